@@ -25,13 +25,19 @@ import (
 	"time"
 
 	"intel/isecl/lib/common/v5/middleware"
+	"intel/isecl/shvs/v5/audit"
 	"intel/isecl/shvs/v5/config"
 	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/logging"
+	"intel/isecl/shvs/v5/ratelimit"
 	"intel/isecl/shvs/v5/repository"
 	"intel/isecl/shvs/v5/repository/postgres"
+	"intel/isecl/shvs/v5/requestid"
 	"intel/isecl/shvs/v5/resource"
 	"intel/isecl/shvs/v5/resource/scheduler"
 	"intel/isecl/shvs/v5/tasks"
+	"intel/isecl/shvs/v5/tracing"
+	"intel/isecl/shvs/v5/types"
 	"intel/isecl/shvs/v5/version"
 
 	"intel/isecl/lib/common/v5/crypt"
@@ -46,6 +52,7 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	// Import driver for GORM
 	_ "github.com/jinzhu/gorm/dialects/postgres"
@@ -72,6 +79,11 @@ func (a *App) printUsage() {
 	fmt.Fprintln(w, "    shvs <command> [arguments]")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Available Commands:")
+	fmt.Fprintln(w, "    audit verify [file]   Verify the hash chain of the audit log against its head in the database,")
+	fmt.Fprintln(w, "                          "+constants.AuditLogFile+" by default")
+	fmt.Fprintln(w, "    config show           Show the effective configuration and the source of every setting")
+	fmt.Fprintln(w, "    config validate       Validate the effective configuration")
+	fmt.Fprintln(w, "    config set key=value  Change settings of config.yml, such as SHVSRefreshTimer=60")
 	fmt.Fprintln(w, "    help|-h|--help        Show this help message")
 	fmt.Fprintln(w, "    setup [task]          Run setup task")
 	fmt.Fprintln(w, "    start                 Start SGX Host Verification Service")
@@ -80,6 +92,14 @@ func (a *App) printUsage() {
 	fmt.Fprintln(w, "    uninstall             Uninstall SGX Host Verification Service")
 	fmt.Fprintln(w, "    version|--version|-v  Show the version of SGX Host Verification Service")
 	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Configuration overrides:")
+	fmt.Fprintln(w, "    Every setting of config.yml can be overridden at run time by the environment variable listed")
+	fmt.Fprintln(w, "    by 'shvs config show'. Settings provisioned by setup use the variable setup reads, such as")
+	fmt.Fprintln(w, "    SHVS_DB_HOSTNAME, prefixed with SHVS_ when setup reads it unprefixed, such as SHVS_CMS_BASE_URL")
+	fmt.Fprintln(w, "    for CMS_BASE_URL. The others use SHVS_<SETTING>, such as SHVS_HOST_EXPIRY_OVERRIDES. Empty")
+	fmt.Fprintln(w, "    variables are ignored. Postgres.Password and SHVS.Password can also be read from the file named")
+	fmt.Fprintln(w, "    by SHVS_DB_PASSWORD_FILE and SHVS_ADMIN_PASSWORD_FILE.")
+	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Available Tasks for setup:")
 	fmt.Fprintln(w, "    all                       Runs all setup tasks")
 	fmt.Fprintln(w, "                              Required env variables:")
@@ -111,15 +131,27 @@ func (a *App) printUsage() {
 	fmt.Fprintln(w, "                                 - SHVS_SERVER_READ_HEADER_TIMEOUT                   : SGX Host Verification Service Read Header Timeout Duration")
 	fmt.Fprintln(w, "                                 - SHVS_SERVER_WRITE_TIMEOUT                         : SGX Host Verification Service Request Write Timeout Duration")
 	fmt.Fprintln(w, "                                 - SHVS_SERVER_IDLE_TIMEOUT                          : SGX Host Verification Service Request Idle Timeout")
+	fmt.Fprintln(w, "                                 - SHVS_SERVER_SHUTDOWN_TIMEOUT                      : SGX Host Verification Service Graceful Shutdown Timeout Duration")
 	fmt.Fprintln(w, "                                 - SHVS_SERVER_MAX_HEADER_BYTES                      : SGX Host Verification Service Max Length Of Request Header Bytes")
 	fmt.Fprintln(w, "                                 - SHVS_LOG_LEVEL                                    : SGX Host Verification Service Log Level")
+	fmt.Fprintln(w, "                                 - SHVS_LOG_FORMAT                                   : SGX Host Verification Service Log format, text or json")
 	fmt.Fprintln(w, "                                 - SHVS_LOG_MAX_LENGTH                               : SGX Host Verification Service Log maximum length")
 	fmt.Fprintln(w, "                                 - SHVS_ENABLE_CONSOLE_LOG                           : SGX Host Verification Service Enable standard output")
 	fmt.Fprintln(w, "                                 - SHVS_ADMIN_USERNAME                               : SHVS Service Username")
 	fmt.Fprintln(w, "                                 - SHVS_ADMIN_PASSWORD                               : SHVS Service Password")
-	fmt.Fprintln(w, "                                 - SHVS_SCHEDULER_TIMER                              : SHVS Scheduler Timeout Seconds")
 	fmt.Fprintln(w, "                                 - SHVS_AUTO_REFRESH_TIMER                           : SHVS autoRefresh Timeout Seconds")
+	fmt.Fprintln(w, "                                 - SHVS_JOB_WORKERS                                  : SHVS Job Runner Worker Count")
+	fmt.Fprintln(w, "                                 - SHVS_JOB_QUEUE_SIZE                               : SHVS Job Runner Queue Size")
+	fmt.Fprintln(w, "                                 - SHVS_JOB_TIMEOUT                                  : SHVS Job Timeout Duration")
+	fmt.Fprintln(w, "                                 - SHVS_JOB_POLL_INTERVAL                            : SHVS Job Queue Poll Interval Duration")
+	fmt.Fprintln(w, "                                 - SHVS_JOB_RETENTION_DAYS                           : SHVS Finished Job Retention in days")
+	fmt.Fprintln(w, "                                 - SHVS_LEADER_ELECTION_INTERVAL                     : SHVS Scheduler Leader Election Interval Duration")
 	fmt.Fprintln(w, "                                 - SHVS_HOST_PLATFORM_EXPIRY_TIME                    : SHVS Host Platform Expiry Time in seconds")
+	fmt.Fprintln(w, "                                 - SHVS_HOST_STALE_GRACE_TIME                        : SHVS Host Stale Grace Time in minutes")
+	fmt.Fprintln(w, "                                 - SHVS_REPORT_TIMER                                 : SHVS Compliance Report Timer Seconds")
+	fmt.Fprintln(w, "                                 - SHVS_REPORT_INACTIVE_HOURS                        : SHVS Compliance Report Inactive Host Threshold in hours")
+	fmt.Fprintln(w, "                                 - SHVS_REPORT_RETENTION_DAYS                        : SHVS Compliance Report Retention in days")
+	fmt.Fprintln(w, "                                 - SHVS_VERDICT_VALIDITY_MINS                        : SHVS Host Verdict Token Validity in minutes")
 	fmt.Fprintln(w, "                                 - SCS_BASE_URL                                      : SGX Caching Service URL")
 	fmt.Fprintln(w, "                                 - AAS_API_URL                                       : AAS API URL")
 	fmt.Fprintln(w, "")
@@ -141,6 +173,16 @@ func (a *App) printUsage() {
 	fmt.Fprintln(w, "                                 - KEY_PATH=<key_path>              : Path of file where TLS key needs to be stored")
 	fmt.Fprintln(w, "                                 - CERT_PATH=<cert_path>            : Path of file/directory where TLS certificate needs to be stored")
 	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "    download_cert_signing    Generates Key pair and CSR for signing host verdicts, gets it signed from CMS")
+	fmt.Fprintln(w, "                             - Option [--force] overwrites any existing files, and always downloads newly signed signing cert")
+	fmt.Fprintln(w, "                             Required env variables specific to setup task are:")
+	fmt.Fprintln(w, "                                 - CMS_BASE_URL=<url>               : for CMS API url")
+	fmt.Fprintln(w, "                                 - BEARER_TOKEN=<token>             : for authenticating with CMS")
+	fmt.Fprintln(w, "                             Optional env variables specific to setup task are:")
+	fmt.Fprintln(w, "                                 - SHVS_SIGNING_CERT_CN=<cn>        : Common name of the signing certificate")
+	fmt.Fprintln(w, "                                 - SIGNING_KEY_PATH=<key_path>      : Path of file where signing key needs to be stored")
+	fmt.Fprintln(w, "                                 - SIGNING_CERT_PATH=<cert_path>    : Path of file where signing certificate needs to be stored")
+	fmt.Fprintln(w, "")
 }
 
 func (a *App) consoleWriter() io.Writer {
@@ -178,6 +220,23 @@ func (a *App) configuration() *config.Configuration {
 	return config.Global()
 }
 
+// validateBeforeStart refuses to start the service with an invalid configuration, listing every problem
+// so that all of them can be fixed at once
+func (a *App) validateBeforeStart() error {
+	err := a.configuration().Validate()
+	if err == nil {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, "Error: daemon did not start - invalid configuration:")
+	if validationErr, ok := err.(*config.ValidationError); ok {
+		for _, problem := range validationErr.Problems {
+			fmt.Fprintln(os.Stderr, "    "+problem)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Run 'shvs config validate' after fixing the configuration")
+	return err
+}
+
 func (a *App) executablePath() string {
 	if a.ExecutablePath != "" {
 		return a.ExecutablePath
@@ -242,14 +301,44 @@ func (a *App) configureLogs(stdOut, logFile bool) {
 	}
 
 	ioWriterSecurity := io.MultiWriter(ioWriterDefault, a.secLogWriter())
-	f := commLog.LogFormatter{MaxLength: a.configuration().LogMaxLength}
-	commLogInt.SetLogger(commLog.DefaultLoggerName, a.configuration().LogLevel, &f, ioWriterDefault, false)
-	commLogInt.SetLogger(commLog.SecurityLoggerName, a.configuration().LogLevel, &f, ioWriterSecurity, false)
+	var f logrus.Formatter = &commLog.LogFormatter{MaxLength: a.configuration().LogMaxLength}
+	if a.configuration().LogFormat == constants.LogFormatJSON {
+		f = &logging.JSONFormatter{MaxLength: a.configuration().LogMaxLength}
+	}
+	commLogInt.SetLogger(commLog.DefaultLoggerName, a.configuration().LogLevel, f, ioWriterDefault, false)
+	commLogInt.SetLogger(commLog.SecurityLoggerName, a.configuration().LogLevel, f, ioWriterSecurity, false)
 
 	slog.Info(commLogMsg.LogInit)
 	log.Info(commLogMsg.LogInit)
 }
 
+// httpLogFormatter formats the HTTP log in the Apache Combined Log Format followed by the request ID, or as JSON
+func httpLogFormatter(logFormat string) handlers.LogFormatter {
+	if logFormat == constants.LogFormatJSON {
+		return logging.WriteJSONHTTPLog
+	}
+	return requestid.WriteCombinedLog
+}
+
+// reloadConfiguration reads config.yml again and applies its log level. The other reloadable settings are
+// read from the global configuration where they are used.
+func (a *App) reloadConfiguration() ([]string, error) {
+	log.Trace("app:reloadConfiguration() Entering")
+	defer log.Trace("app:reloadConfiguration() Leaving")
+
+	conf, restartRequired, err := config.Reload()
+	if err != nil {
+		return nil, err
+	}
+	if a.Config != nil {
+		a.Config = conf
+	}
+	log.Logger.SetLevel(conf.LogLevel)
+	slog.Logger.SetLevel(conf.LogLevel)
+	slog.Infof("Configuration reloaded, log level is %s", conf.LogLevel)
+	return restartRequired, nil
+}
+
 func (a *App) Run(args []string) error {
 
 	if len(args) < 2 {
@@ -264,6 +353,13 @@ func (a *App) Run(args []string) error {
 		fmt.Fprintf(os.Stderr, "Unrecognized command: %s\n", args[1])
 		os.Exit(1)
 	case "run":
+		if err := a.configuration().ApplyEnvOverrides(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error: daemon did not start - ", err.Error())
+			return errors.Wrap(err, "app:Run() Error applying configuration overrides")
+		}
+		if err := a.validateBeforeStart(); err != nil {
+			return errors.Wrap(err, "app:Run() Invalid configuration")
+		}
 		a.configureLogs(a.configuration().LogEnableStdout, true)
 		if err := a.startServer(); err != nil {
 			fmt.Fprintln(os.Stderr, "Error: daemon did not start - ", err.Error())
@@ -296,6 +392,10 @@ func (a *App) Run(args []string) error {
 	case "version", "--version", "-v":
 		fmt.Println(version.GetVersion())
 		return nil
+	case "config":
+		return a.configCommand(args[2:])
+	case "audit":
+		return a.auditCommand(args[2:])
 	case "setup":
 		a.configureLogs(a.configuration().LogEnableStdout, true)
 		var setupContext setup.Context
@@ -306,6 +406,7 @@ func (a *App) Run(args []string) error {
 
 		if args[2] != "download_ca_cert" &&
 			args[2] != "download_cert_tls" &&
+			args[2] != "download_cert_signing" &&
 			args[2] != "database" &&
 			args[2] != "update_service_config" &&
 			args[2] != "all" {
@@ -420,6 +521,17 @@ func (a *App) Run(args []string) error {
 				return errors.Wrap(err, "Error while changing ownership of TLS Cert file")
 			}
 		}
+		if task == "download_cert_signing" {
+			err = os.Chown(a.Config.SigningKeyFile, uid, gid)
+			if err != nil {
+				return errors.Wrap(err, "Error while changing ownership of Signing Key file")
+			}
+
+			err = os.Chown(a.Config.SigningCertFile, uid, gid)
+			if err != nil {
+				return errors.Wrap(err, "Error while changing ownership of Signing Cert file")
+			}
+		}
 	}
 	return nil
 }
@@ -443,28 +555,80 @@ func (a *App) startServer() error {
 	err = shvsDB.Migrate()
 	if err != nil {
 		log.WithError(err).Error("Failed to migrate database")
+		return err
+	}
+
+	stopTracing := func(context.Context) error { return nil }
+	if c.Tracing.Enabled {
+		stopTracing, err = tracing.Start(context.Background(), c.Tracing.Endpoint, c.Tracing.Insecure, tracingSampleRatio(c))
+		if err != nil {
+			log.WithError(err).Error("Failed to start tracing")
+			return err
+		}
 	}
 
 	// Create Router, set routes
 	r := mux.NewRouter()
 	r.SkipClean(true)
+	// set on the root router only, a subrouter would answer the requests meant for the next subrouter
+	r.NotFoundHandler = resource.NotFoundHandler()
+	r.MethodNotAllowedHandler = resource.MethodNotAllowedHandler()
+	r.Use(tracing.Middleware)
+
+	// Requests are limited per remote IP before the token is checked, and per token subject after
+	ipLimiter := newLimiter(c.RateLimit.IPRequestsPerSecond, constants.DefaultIPRequestsPerSec,
+		burst(c.RateLimit.IPBurst, constants.DefaultIPRequestBurst))
+	subjectLimiter := newLimiter(c.RateLimit.SubjectRequestsPerSecond, constants.DefaultSubjectRequestsPerSec,
+		burst(c.RateLimit.SubjectBurst, constants.DefaultSubjectRequestBurst))
+	trustedProxies, err := config.ParseTrustedProxies(c.RateLimit.TrustedProxies)
+	if err != nil {
+		log.WithError(err).Error("Invalid trusted proxies of the rate limit")
+		return err
+	}
+	maxRequestBodyBytes := c.MaxRequestBodyBytes
+	if maxRequestBodyBytes == 0 {
+		maxRequestBodyBytes = constants.DefaultMaxRequestBodyBytes
+	}
 
 	// Create Router, set routes
 	sr := r.PathPrefix("/sgx-hvs/v2/").Subrouter()
+	sr.Use(resource.RateLimitByIP(ipLimiter, trustedProxies), resource.LimitRequestBody(maxRequestBodyBytes))
 	func(setters ...func(*mux.Router)) {
 		for _, setter := range setters {
 			setter(sr)
 		}
-	}(resource.SetVersionRoutes)
+	}(resource.SetVersionRoutes, resource.SetHealthRoutes)
 
 	sr = r.PathPrefix("/sgx-hvs/v2/").Subrouter()
 	var cacheTime, _ = time.ParseDuration(constants.JWTCertsCacheTime)
-	sr.Use(middleware.NewTokenAuth(constants.TrustedJWTSigningCertsDir, constants.TrustedCAsStoreDir, fnGetJwtCerts, cacheTime))
+	sr.Use(resource.RateLimitByIP(ipLimiter, trustedProxies), resource.LimitRequestBody(maxRequestBodyBytes))
+	tokenAuth := middleware.NewTokenAuth(constants.TrustedJWTSigningCertsDir, constants.TrustedCAsStoreDir, fnGetJwtCerts, cacheTime)
+	if c.ClientCertAuth.Enabled {
+		sr.Use(resource.ClientCertAuth(tokenAuth))
+	} else {
+		sr.Use(tokenAuth)
+	}
+	sr.Use(resource.RateLimitBySubject(subjectLimiter))
 	func(setters ...func(*mux.Router, repository.SHVSDatabase)) {
 		for _, setter := range setters {
 			setter(sr, shvsDB)
 		}
-	}(resource.SGXHostRegisterOps)
+	}(resource.SGXHostRegisterOps, resource.ComplianceReportOps, resource.PolicyOps, resource.HostVerdictOps, resource.JobOps,
+		resource.ConfigReloadOps, resource.AuditEventOps)
+
+	auditLog, err := audit.Open(constants.AuditLogFile, shvsDB)
+	if err != nil {
+		log.WithError(err).Error("Failed to open audit log")
+		return err
+	}
+	defer auditLog.Close()
+	resource.SetAuditLog(auditLog)
+
+	err = resource.InitVerdictSigner(c.SigningKeyFile, c.SigningCertFile, c.Token.IncludeKid,
+		time.Duration(c.VerdictValidityMins)*time.Minute)
+	if err != nil {
+		log.WithError(err).Warn("Host verdict signing key is not available, signed verdicts are disabled")
+	}
 
 	tlsconfig := &tls.Config{
 		MinVersion: tls.VersionTLS13,
@@ -473,20 +637,64 @@ func (a *App) startServer() error {
 			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
 			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
 	}
-	err = scheduler.StartWorkqueueScheduler(c.SchedulerTimer + 60)
-	if err != nil {
-		log.WithError(err).Error("failed to start workqueue scheduler")
-		return err
+	// Agents may authenticate with a client certificate issued by CMS, the other clients keep using bearer tokens
+	if c.ClientCertAuth.Enabled {
+		tlsconfig.ClientCAs, err = trustedCAPool()
+		if err != nil {
+			log.WithError(err).Error("Failed to load the CAs of the client certificates")
+			return err
+		}
+		tlsconfig.ClientAuth = tls.VerifyClientCertIfGiven
 	}
-	scheduler.StartAutoRefreshSchedular(shvsDB, c.SHVSRefreshTimer)
+	// The root context is done on termination, or when the web server fails. It stops the schedulers
+	// and the job dispatcher from starting new work, while the shutdown below drains the work under way.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	jobRunner := scheduler.NewJobRunner(c.JobRunner.Workers, c.JobRunner.QueueSize, c.JobRunner.JobTimeout)
+	// The leader keeps its lock until its jobs are drained, so that no other instance starts a sweep meanwhile
+	electorCtx, stopElector := context.WithCancel(context.Background())
+	defer stopElector()
+	elector := scheduler.NewLeaderElector(shvsDB.LeaderLock(), c.LeaderElectionInterval)
+	elector.Start(electorCtx)
+	resource.SetLeaderStatus(elector.IsLeader)
+	scheduler.StartAutoRefreshSchedular(ctx, jobRunner, elector, shvsDB)
+	scheduler.StartComplianceReportSchedular(ctx, elector, shvsDB)
+	jobDispatcher := scheduler.StartJobDispatcher(ctx, jobRunner, shvsDB, c)
+	resource.SetJobCanceller(jobDispatcher.Cancel)
+
+	// SIGHUP reloads the configuration, as does the admin reload endpoint
+	resource.SetConfigReloader(a.reloadConfiguration)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload:
+				event := &types.AuditEvent{Action: constants.AuditActionConfigReload, Subject: "SIGHUP",
+					Result: constants.AuditResultSuccess}
+				restartRequired, err := a.reloadConfiguration()
+				if err != nil {
+					log.WithError(err).Error("Failed to reload configuration, keeping the current configuration")
+					event.Result = constants.AuditResultFailure
+					event.Detail = err.Error()
+				} else if len(restartRequired) > 0 {
+					event.Detail = "restart required: " + strings.Join(restartRequired, ", ")
+				}
+				if err := audit.Record(shvsDB, auditLog, event); err != nil {
+					slog.WithError(err).Error("Failed to record the configuration reload")
+				}
+			}
+		}
+	}()
 
-	// Setup signal handlers to gracefully handle termination
-	stop := make(chan os.Signal)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	httpLog := stdlog.New(a.httpLogWriter(), "", 0)
 	h := &http.Server{
 		Addr:              fmt.Sprintf(":%d", c.Port),
-		Handler:           handlers.RecoveryHandler(handlers.RecoveryLogger(httpLog), handlers.PrintRecoveryStack(true))(handlers.CombinedLoggingHandler(a.httpLogWriter(), r)),
+		Handler:           handlers.RecoveryHandler(handlers.RecoveryLogger(httpLog), handlers.PrintRecoveryStack(true))(requestid.Handler(logging.Handler(handlers.CustomLoggingHandler(a.httpLogWriter(), r, httpLogFormatter(c.LogFormat))))),
 		ErrorLog:          httpLog,
 		TLSConfig:         tlsconfig,
 		ReadTimeout:       c.ReadTimeout,
@@ -500,20 +708,48 @@ func (a *App) startServer() error {
 	go func() {
 		tlsCert := config.Global().TLSCertFile
 		tlsKey := config.Global().TLSKeyFile
-		if err := h.ListenAndServeTLS(tlsCert, tlsKey); err != nil {
+		if err := h.ListenAndServeTLS(tlsCert, tlsKey); err != nil && err != http.ErrServerClosed {
 			log.WithError(err).Info("Failed to start HTTPS server")
-			stop <- syscall.SIGTERM
+			stop()
 		}
 	}()
 
 	slog.Info(commLogMsg.ServiceStart)
 	// TODO dispatch Service status checker goroutine
-	<-stop
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	<-ctx.Done()
+	// restore the default signal handling, so that a second signal terminates right away
+	stop()
+	log.Info("Shutting down SHVS server")
+
+	shutdownTimeout := config.Global().ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = constants.DefaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
-	if err := h.Shutdown(ctx); err != nil {
+
+	// Every step runs even when an earlier one fails, so that the leader lock is released before the
+	// database is closed
+	var shutdownErr error
+	if err := h.Shutdown(shutdownCtx); err != nil {
 		log.WithError(err).Info("Failed to gracefully shutdown webserver")
-		return err
+		shutdownErr = err
+	}
+	if err := jobRunner.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Info("Failed to gracefully shutdown job runner")
+		if shutdownErr == nil {
+			shutdownErr = err
+		}
+	}
+	jobDispatcher.Wait()
+	stopElector()
+	elector.Wait()
+	// the spans of the drained requests and jobs are flushed last
+	if err := stopTracing(shutdownCtx); err != nil {
+		log.WithError(err).Info("Failed to flush the traces")
+	}
+	if shutdownErr != nil {
+		return shutdownErr
 	}
 	slog.Info(commLogMsg.ServiceStop)
 	return nil
@@ -662,6 +898,9 @@ func validateSetupArgs(cmd string, args []string) error {
 	case "download_cert_tls":
 		return nil
 
+	case "download_cert_signing":
+		return nil
+
 	case "database":
 		envNamesCmdOpts := map[string]string{
 			"SHVS_DB_HOSTNAME":   "db-host",
@@ -732,6 +971,54 @@ func (a *App) DatabaseFactory() (repository.SHVSDatabase, error) {
 	return p, nil
 }
 
+// tracingSampleRatio samples all traces unless a ratio is configured, 0 turning sampling off
+func tracingSampleRatio(c *config.Configuration) float64 {
+	if c.Tracing.SampleRatio == nil || *c.Tracing.SampleRatio < 0 {
+		return 1
+	}
+	return *c.Tracing.SampleRatio
+}
+
+// trustedCAPool returns the pool of the CMS CAs trusted by SHVS. An empty pool would reject every client
+// certificate, so it is an error when no CA is found.
+func trustedCAPool() (*x509.CertPool, error) {
+	caCertPems, err := cos.GetDirFileContents(constants.TrustedCAsStoreDir, "*.pem")
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not read CA certificates")
+	}
+	if len(caCertPems) == 0 {
+		return nil, errors.Errorf("No CA certificate found in %s", constants.TrustedCAsStoreDir)
+	}
+	pool := x509.NewCertPool()
+	for _, caCertPem := range caCertPems {
+		if !pool.AppendCertsFromPEM(caCertPem) {
+			return nil, errors.New("Could not parse CA certificates")
+		}
+	}
+	return pool, nil
+}
+
+// newLimiter returns the limiter of the configured requests per second, or of the default when not set. It
+// returns nil, which lets all requests through, when the rate is set to 0.
+func newLimiter(configured *float64, defaultRate float64, burst int) *ratelimit.Limiter {
+	rate := defaultRate
+	if configured != nil {
+		rate = *configured
+	}
+	if rate == 0 {
+		return nil
+	}
+	return ratelimit.NewLimiter(rate, burst)
+}
+
+// burst returns the configured burst of requests, or the default when not set
+func burst(configured, defaultBurst int) int {
+	if configured == 0 {
+		return defaultBurst
+	}
+	return configured
+}
+
 func fnGetJwtCerts() error {
 	log.Trace("resource/service:fnGetJwtCerts() Entering")
 	defer log.Trace("resource/service:fnGetJwtCerts() Leaving")
@@ -763,10 +1050,12 @@ func fnGetJwtCerts() error {
 		}
 	}
 	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: false,
-				RootCAs:            rootCAs,
+		Transport: &tracing.Transport{
+			Base: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: false,
+					RootCAs:            rootCAs,
+				},
 			},
 		},
 	}
@@ -0,0 +1,235 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+// Package audit records the administrative actions taken on SHVS. Every event is stored in the audit_events
+// table and appended to a hash-chained log file, in which each entry carries the hash of the previous one so
+// that any change to the file breaks the chain from the changed entry on. The number of entries of the file
+// and the hash of the last one, its head, are stored in the database, so that removing the last entries or
+// rewriting the whole file is detected too.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	commLog "intel/isecl/lib/common/v5/log"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+var log = commLog.GetDefaultLogger()
+var slog = commLog.GetSecurityLogger()
+
+// GenesisHash is the previous hash of the first entry of a log
+var GenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// Entry is a line of the audit log file
+type Entry struct {
+	PrevHash string          `json:"prev_hash"`
+	Event    json.RawMessage `json:"event"`
+	Hash     string          `json:"hash"`
+}
+
+// entryHash chains the event to the previous entry
+func entryHash(prevHash string, event []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write(event)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ChainError reports the first entry of a log file which does not chain to the entries before it
+type ChainError struct {
+	// Line is the line number of the entry, starting from 1
+	Line   int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log chain broken at line %d: %s", e.Line, e.Reason)
+}
+
+// LogID names the audit log file at path in the database, by the host name of the instance and the
+// absolute path of the file
+func LogID(path string) string {
+	hostname, _ := os.Hostname()
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return hostname + ":" + path
+}
+
+// Verify checks the chain of the entries read from r. It returns the number of entries and the hash of the
+// last one, or a *ChainError for the first entry not chained to the previous one.
+func Verify(r io.Reader) (int, string, error) {
+	return VerifyHead(r, nil)
+}
+
+// VerifyHead checks the chain of the entries read from r as Verify does, and that it holds the head stored
+// in the database, when there is one. Entries appended after the head are accepted, as the head is stored
+// once the entry is written.
+func VerifyHead(r io.Reader, head *types.AuditLogHead) (int, string, error) {
+	lastHash := GenesisHash
+	count := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		count++
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return count - 1, lastHash, &ChainError{Line: count, Reason: "malformed entry"}
+		}
+		if entry.PrevHash != lastHash {
+			return count - 1, lastHash, &ChainError{Line: count, Reason: "previous hash does not match"}
+		}
+		if entry.Hash != entryHash(entry.PrevHash, entry.Event) {
+			return count - 1, lastHash, &ChainError{Line: count, Reason: "hash does not match the event"}
+		}
+		if head != nil && count == head.Sequence && entry.Hash != head.Hash {
+			return count - 1, lastHash, &ChainError{Line: count, Reason: "hash does not match the head stored in the database"}
+		}
+		lastHash = entry.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return count, lastHash, errors.Wrap(err, "failed to read audit log")
+	}
+	if head != nil && count < head.Sequence {
+		return count, lastHash, &ChainError{Line: count + 1,
+			Reason: fmt.Sprintf("file ends before entry %d, the head stored in the database", head.Sequence)}
+	}
+	return count, lastHash, nil
+}
+
+// Log appends the audit events to a hash-chained log file
+type Log struct {
+	mutex    sync.Mutex
+	w        io.WriteCloser
+	id       string
+	count    int
+	lastHash string
+}
+
+// Open opens the audit log file for appending, creating it when needed, and checks it against its head
+// stored in db, unless db is nil. A broken chain is reported to the security log and new entries are chained
+// to the last entry of the file, so that the break stays visible.
+func Open(path string, db repository.SHVSDatabase) (*Log, error) {
+	log.Trace("audit/audit:Open() Entering")
+	defer log.Trace("audit/audit:Open() Leaving")
+
+	id := LogID(path)
+	var head *types.AuditLogHead
+	if db != nil {
+		var err error
+		head, err = db.AuditEventRepository().RetrieveHead(id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the head of the audit log")
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log")
+	}
+	count, lastHash, err := VerifyHead(f, head)
+	var chainErr *ChainError
+	if errors.As(err, &chainErr) {
+		slog.WithError(err).Errorf("audit/audit:Open() %s was tampered with", path)
+		count, lastHash, err = lastEntry(f)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	log.Debugf("audit/audit:Open() %s holds %d entries", path, count)
+	return &Log{w: f, id: id, count: count, lastHash: lastHash}, nil
+}
+
+// lastEntry reads the number of entries and the hash of the last one, whether or not it is chained to the
+// entries before it
+func lastEntry(f *os.File) (int, string, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, "", errors.Wrap(err, "failed to read audit log")
+	}
+	count := 0
+	lastHash := GenesisHash
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		count++
+		var entry Entry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil && entry.Hash != "" {
+			lastHash = entry.Hash
+		}
+	}
+	return count, lastHash, errors.Wrap(scanner.Err(), "failed to read audit log")
+}
+
+// Append writes the event as the next entry of the chain
+func (l *Log) Append(event *types.AuditEvent) error {
+	_, err := l.append(event)
+	return err
+}
+
+// append writes the event as the next entry of the chain and returns the new head of the log
+func (l *Log) append(event *types.AuditEvent) (types.AuditLogHead, error) {
+	js, err := json.Marshal(event)
+	if err != nil {
+		return types.AuditLogHead{}, errors.Wrap(err, "failed to encode audit event")
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entry := Entry{PrevHash: l.lastHash, Event: js, Hash: entryHash(l.lastHash, js)}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return types.AuditLogHead{}, errors.Wrap(err, "failed to encode audit log entry")
+	}
+	_, err = l.w.Write(append(line, '\n'))
+	if err != nil {
+		return types.AuditLogHead{}, errors.Wrap(err, "failed to write audit log entry")
+	}
+	l.count++
+	l.lastHash = entry.Hash
+	return types.AuditLogHead{LogID: l.id, Sequence: l.count, Hash: entry.Hash, UpdatedTime: time.Now().UTC()}, nil
+}
+
+func (l *Log) Close() error {
+	return l.w.Close()
+}
+
+// Record stores the event in the database and appends it to the log file, when there is one, advancing the
+// head of the file in the database. The ID and the time of the event are set when missing.
+func Record(db repository.SHVSDatabase, l *Log, event *types.AuditEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	_, err := db.AuditEventRepository().Create(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to store audit event")
+	}
+	if l == nil {
+		return nil
+	}
+	head, err := l.append(event)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(db.AuditEventRepository().AdvanceHead(&head), "failed to store the head of the audit log")
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package audit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+)
+
+func tempLogFile(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "shvs-audit.log")
+}
+
+func TestRecordAndVerify(t *testing.T) {
+	file := tempLogFile(t)
+	db := &mock.MockDatabase{}
+
+	l, err := Open(file, db)
+	assert.NoError(t, err)
+	for _, action := range []string{constants.AuditActionHostRegister, constants.AuditActionHostDelete} {
+		err = Record(db, l, &types.AuditEvent{Action: action, Subject: "admin", Target: "host-1",
+			Result: constants.AuditResultSuccess})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, l.Close())
+
+	assert.Len(t, db.MockAuditEventRepository.AuditEvents, 2)
+	assert.NotEmpty(t, db.MockAuditEventRepository.AuditEvents[0].ID)
+	assert.False(t, db.MockAuditEventRepository.AuditEvents[0].Time.IsZero())
+
+	content, err := ioutil.ReadFile(file)
+	assert.NoError(t, err)
+	count, lastHash, err := Verify(bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.NotEqual(t, GenesisHash, lastHash)
+
+	// entries appended after reopening the file stay chained
+	l, err = Open(file, db)
+	assert.NoError(t, err)
+	assert.Equal(t, lastHash, l.lastHash)
+	assert.NoError(t, Record(db, l, &types.AuditEvent{Action: constants.AuditActionConfigReload}))
+	assert.NoError(t, l.Close())
+	content, _ = ioutil.ReadFile(file)
+	count, _, err = Verify(bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 3, db.MockAuditEventRepository.Heads[LogID(file)].Sequence)
+}
+
+func TestVerifyHeadDetectsTruncationAndRewrite(t *testing.T) {
+	file := tempLogFile(t)
+	db := &mock.MockDatabase{}
+	l, err := Open(file, db)
+	assert.NoError(t, err)
+	for _, subject := range []string{"admin", "operator", "admin"} {
+		assert.NoError(t, Record(db, l, &types.AuditEvent{Action: constants.AuditActionHostDelete, Subject: subject}))
+	}
+	assert.NoError(t, l.Close())
+	head, _ := db.AuditEventRepository().RetrieveHead(LogID(file))
+	content, _ := ioutil.ReadFile(file)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	count, _, err := VerifyHead(bytes.NewReader(content), head)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// the last entry removed, the chain itself still verifies
+	truncated := strings.Join(lines[:2], "\n")
+	_, _, err = Verify(strings.NewReader(truncated))
+	assert.NoError(t, err)
+	_, _, err = VerifyHead(strings.NewReader(truncated), head)
+	assert.Equal(t, &ChainError{Line: 3, Reason: "file ends before entry 3, the head stored in the database"}, err)
+
+	// the whole file rewritten with a valid chain
+	rewritten := tempLogFile(t)
+	l, _ = Open(rewritten, nil)
+	for _, subject := range []string{"admin", "admin", "admin"} {
+		assert.NoError(t, l.Append(&types.AuditEvent{Action: constants.AuditActionHostDelete, Subject: subject}))
+	}
+	assert.NoError(t, l.Close())
+	content, _ = ioutil.ReadFile(rewritten)
+	_, _, err = VerifyHead(bytes.NewReader(content), head)
+	assert.Equal(t, &ChainError{Line: 3, Reason: "hash does not match the head stored in the database"}, err)
+
+	// entries appended after the head was stored
+	assert.NoError(t, ioutil.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0640))
+	l, _ = Open(file, nil)
+	assert.NoError(t, l.Append(&types.AuditEvent{Action: constants.AuditActionHostDelete, Subject: "admin"}))
+	assert.NoError(t, l.Close())
+	content, _ = ioutil.ReadFile(file)
+	count, _, err = VerifyHead(bytes.NewReader(content), head)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	file := tempLogFile(t)
+	l, err := Open(file, nil)
+	assert.NoError(t, err)
+	for _, subject := range []string{"admin", "operator", "admin"} {
+		assert.NoError(t, l.Append(&types.AuditEvent{Action: constants.AuditActionHostDelete, Subject: subject}))
+	}
+	assert.NoError(t, l.Close())
+	content, _ := ioutil.ReadFile(file)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	// changed event
+	changed := append([]string{}, lines...)
+	changed[1] = strings.Replace(changed[1], "operator", "intruder", 1)
+	count, _, err := Verify(strings.NewReader(strings.Join(changed, "\n")))
+	assert.Equal(t, &ChainError{Line: 2, Reason: "hash does not match the event"}, err)
+	assert.Equal(t, 1, count)
+
+	// removed entry
+	removed := []string{lines[0], lines[2]}
+	_, _, err = Verify(strings.NewReader(strings.Join(removed, "\n")))
+	assert.Equal(t, &ChainError{Line: 2, Reason: "previous hash does not match"}, err)
+
+	// malformed entry
+	_, _, err = Verify(strings.NewReader(lines[0] + "\n{\n"))
+	assert.Equal(t, &ChainError{Line: 2, Reason: "malformed entry"}, err)
+}
+
+func TestOpenContinuesBrokenChain(t *testing.T) {
+	file := tempLogFile(t)
+	l, _ := Open(file, nil)
+	assert.NoError(t, l.Append(&types.AuditEvent{Action: constants.AuditActionHostDelete, Subject: "admin"}))
+	assert.NoError(t, l.Close())
+	content, _ := ioutil.ReadFile(file)
+	assert.NoError(t, ioutil.WriteFile(file, bytes.Replace(content, []byte("admin"), []byte("other"), 1), 0640))
+
+	l, err := Open(file, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, l.Append(&types.AuditEvent{Action: constants.AuditActionHostDelete, Subject: "admin"}))
+	assert.NoError(t, l.Close())
+
+	// the break stays at the changed entry
+	content, _ = ioutil.ReadFile(file)
+	_, _, err = Verify(bytes.NewReader(content))
+	assert.Equal(t, &ChainError{Line: 1, Reason: "hash does not match the event"}, err)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"intel/isecl/shvs/v5/audit"
+	"intel/isecl/shvs/v5/constants"
+)
+
+// auditCommand runs the audit subcommands
+func (a *App) auditCommand(args []string) error {
+	log.Trace("audit_cmd:auditCommand() Entering")
+	defer log.Trace("audit_cmd:auditCommand() Leaving")
+
+	if len(args) == 0 || args[0] != "verify" || len(args) > 2 {
+		a.printUsage()
+		return errors.New("Usage: shvs audit verify [file]")
+	}
+	file := constants.AuditLogFile
+	if len(args) == 2 {
+		file = args[1]
+	}
+	return a.verifyAuditLog(file)
+}
+
+// verifyAuditLog checks the hash chain of the audit log file against its head stored in the database,
+// reporting the first entry which was tampered with. The head is found by the host name and the path of the
+// file, a copy of the file is verified on its own.
+func (a *App) verifyAuditLog(file string) error {
+	if err := a.configuration().ApplyEnvOverrides(); err != nil {
+		return errors.Wrap(err, "audit verify")
+	}
+	db, err := a.DatabaseFactory()
+	if err != nil {
+		return errors.Wrap(err, "audit verify: failed to open the database")
+	}
+	defer db.Close()
+	head, err := db.AuditEventRepository().RetrieveHead(audit.LogID(file))
+	if err != nil {
+		return errors.Wrap(err, "audit verify")
+	}
+	if head == nil {
+		fmt.Fprintf(a.consoleWriter(), "%s: no head stored in the database, verifying the chain only\n", file)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.Wrap(err, "audit verify")
+	}
+	defer f.Close()
+
+	count, lastHash, err := audit.VerifyHead(f, head)
+	if err != nil {
+		return errors.Wrapf(err, "audit verify: %s, %d entries verified", file, count)
+	}
+	fmt.Fprintf(a.consoleWriter(), "%s: %d entries verified, last hash %s\n", file, count, lastHash)
+	return nil
+}
@@ -12,10 +12,10 @@ import (
 	commLog "intel/isecl/lib/common/v5/log"
 	"intel/isecl/lib/common/v5/setup"
 	"intel/isecl/shvs/v5/constants"
-	"net/url"
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,7 +23,11 @@ var log = commLog.GetDefaultLogger()
 
 // Configuration is the global configuration struct that is marshalled/unmarshaled to a persisted yaml file
 type Configuration struct {
-	configFile       string
+	configFile string
+	// sources holds the settings overridden from the environment along with their source
+	sources map[string]string
+	// loadErr is the error met reading config.yml, reported by Validate
+	loadErr          error
 	Port             int
 	CmsTLSCertDigest string
 	Postgres         struct {
@@ -38,6 +42,17 @@ type Configuration struct {
 	LogMaxLength    int
 	LogEnableStdout bool
 	LogLevel        logrus.Level
+	// LogFormat is text or json, for the application, security and HTTP logs
+	LogFormat string
+	// Tracing exports OpenTelemetry spans of the requests over OTLP/HTTP to Endpoint, given as host:port
+	Tracing struct {
+		Enabled  bool
+		Endpoint string
+		Insecure bool
+		// SampleRatio is the ratio of the traces sampled, none of them when 0 and all of them when unset or
+		// negative
+		SampleRatio *float64
+	}
 
 	SHVS struct {
 		User     string
@@ -47,38 +62,99 @@ type Configuration struct {
 		IncludeKid        bool
 		TokenDurationMins int
 	}
+	// ClientCertAuth lets the agents authenticate with a client certificate issued by CMS instead of a bearer
+	// token, the certificate naming the hardware UUID of the host in its subject or a SAN
+	ClientCertAuth struct {
+		Enabled bool
+	}
 	CMSBaseURL             string
 	AuthServiceURL         string
 	ScsBaseURL             string
-	SchedulerTimer         int
 	SHVSRefreshTimer       int
 	SHVSHostInfoExpiryTime int
-	Subject                struct {
-		TLSCertCommonName string
+	SHVSHostStaleGraceTime int
+	// HostExpiryOverrides maps hardware UUIDs to the expiry time in minutes of their hosts
+	HostExpiryOverrides    map[string]int
+	LeaderElectionInterval time.Duration
+	JobRunner              struct {
+		Workers      int
+		QueueSize    int
+		JobTimeout   time.Duration
+		PollInterval time.Duration
+		// RetentionDays is how long the finished jobs are kept
+		RetentionDays int
+	}
+	ComplianceReport struct {
+		Timer         int
+		InactiveHours int
+		RetentionDays int
+	}
+	// VerdictValidityMins bounds the lifetime of the signed host verdicts
+	VerdictValidityMins int
+	Subject             struct {
+		TLSCertCommonName     string
+		SigningCertCommonName string
 	}
 	TLSKeyFile        string
 	TLSCertFile       string
+	SigningKeyFile    string
+	SigningCertFile   string
 	CertSANList       string
 	ReadTimeout       time.Duration
 	ReadHeaderTimeout time.Duration
 	WriteTimeout      time.Duration
 	IdleTimeout       time.Duration
-	MaxHeaderBytes    int
+	// ShutdownTimeout bounds how long in-flight requests and jobs are drained on shutdown
+	ShutdownTimeout time.Duration
+	MaxHeaderBytes  int
+	// MaxRequestBodyBytes bounds the size of the request bodies
+	MaxRequestBodyBytes int64
+	// RateLimit bounds the requests of each token subject and of each remote IP, refilled at the rate per
+	// second up to the burst. The remote IP is the peer of the connection, unless the peer is one of the
+	// TrustedProxies, IPs or CIDRs of the ingresses and proxies in front of SHVS, whose X-Forwarded-For header
+	// then gives the client IP. Without TrustedProxies, all clients behind a proxy share the limit of its IP.
+	// The defaults apply to the settings left unset, and requests per second of 0 turn the limit off.
+	RateLimit struct {
+		SubjectRequestsPerSecond *float64
+		SubjectBurst             int
+		IPRequestsPerSecond      *float64
+		IPBurst                  int
+		TrustedProxies           []string
+	}
 }
 
 var global *Configuration
+var globalLock sync.RWMutex
 
 func Global() *Configuration {
 	log.Trace("config/config:Global() Entering")
 	defer log.Trace("config/config:Global() Leaving")
 
+	globalLock.RLock()
+	conf := global
+	globalLock.RUnlock()
+	if conf != nil {
+		return conf
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
 	if global == nil {
 		global = Load(path.Join(constants.ConfigDir, constants.ConfigFile))
 	}
 	return global
 }
 
+// SetGlobal swaps the global configuration. Callers holding the previous configuration keep a consistent
+// view of it, so a configuration is never changed in place once it is global.
+func SetGlobal(conf *Configuration) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	global = conf
+}
+
 var ErrNoConfigFile = errors.New("no config file")
+var ErrEnvOverrides = errors.New("configuration carries environment overrides")
 
 func (conf *Configuration) Save() error {
 	log.Trace("config/config:Save() Entering")
@@ -87,6 +163,9 @@ func (conf *Configuration) Save() error {
 	if conf.configFile == "" {
 		return ErrNoConfigFile
 	}
+	if conf.EnvOverridesApplied() {
+		return ErrEnvOverrides
+	}
 	file, err := os.OpenFile(conf.configFile, os.O_RDWR, 0)
 	if err != nil {
 		// we have an error
@@ -116,7 +195,8 @@ func (conf *Configuration) SaveConfiguration(taskName string, c setup.Context) e
 	defer log.Trace("config/config:SaveConfiguration() Leaving")
 
 	// target config changes only in scope for the setup task
-	if taskName == "all" || taskName == "download_ca_cert" || taskName == "download_cert_tls" {
+	if taskName == "all" || taskName == "download_ca_cert" || taskName == "download_cert_tls" ||
+		taskName == "download_cert_signing" {
 		tlsCertDigest, err := c.GetenvString("CMS_TLS_CERT_SHA384", "TLS certificate digest")
 		if err == nil && strings.TrimSpace(tlsCertDigest) != "" {
 			conf.CmsTLSCertDigest = tlsCertDigest
@@ -127,7 +207,7 @@ func (conf *Configuration) SaveConfiguration(taskName string, c setup.Context) e
 
 		cmsBaseURL, err := c.GetenvString("CMS_BASE_URL", "CMS Base URL")
 		if err == nil && strings.TrimSpace(cmsBaseURL) != "" {
-			if _, err = url.ParseRequestURI(cmsBaseURL); err != nil {
+			if err = ValidateURL(cmsBaseURL); err != nil {
 				log.Error("CMS_BASE_URL provided is invalid")
 				return errorLog.Wrap(err, "SaveConfiguration() CMS_BASE_URL provided is invalid")
 			}
@@ -169,6 +249,33 @@ func (conf *Configuration) SaveConfiguration(taskName string, c setup.Context) e
 		}
 	}
 
+	if taskName == "download_cert_signing" {
+		signingCertCN, err := c.GetenvString("SHVS_SIGNING_CERT_CN", "SHVS Signing Certificate Common Name")
+		if err == nil && strings.TrimSpace(signingCertCN) != "" {
+			conf.Subject.SigningCertCommonName = signingCertCN
+		} else if conf.Subject.SigningCertCommonName == "" {
+			conf.Subject.SigningCertCommonName = constants.DefaultSHVSSigningCn
+		}
+
+		signingKeyPath, err := c.GetenvString("SIGNING_KEY_PATH", "Filepath where signing key needs to be stored")
+		if err == nil && strings.TrimSpace(signingKeyPath) != "" {
+			conf.SigningKeyFile = signingKeyPath
+		} else if conf.SigningKeyFile == "" {
+			conf.SigningKeyFile = constants.DefaultSigningKeyFile
+		}
+
+		signingCertPath, err := c.GetenvString("SIGNING_CERT_PATH", "Filepath where signing certificate needs to be stored")
+		if err == nil && strings.TrimSpace(signingCertPath) != "" {
+			conf.SigningCertFile = signingCertPath
+		} else if conf.SigningCertFile == "" {
+			conf.SigningCertFile = constants.DefaultSigningCertFile
+		}
+
+		if conf.CertSANList == "" {
+			conf.CertSANList = constants.DefaultSHVSTlsSan
+		}
+	}
+
 	return conf.Save()
 }
 
@@ -176,22 +283,33 @@ func Load(filePath string) *Configuration {
 	log.Trace("config/config:Load() Entering")
 	defer log.Trace("config/config:Load() Leaving")
 
+	c, err := load(filePath)
+	if err != nil && !os.IsNotExist(errorLog.Cause(err)) {
+		log.WithError(err).Error("Failed to load config.yml")
+	}
+	c.loadErr = err
+	return c
+}
+
+// load reads the configuration file, returning the configuration decoded so far along with the error
+// when the file cannot be read or decoded
+func load(filePath string) (*Configuration, error) {
 	var c Configuration
-	file, _ := os.Open(filePath)
-	if file != nil {
-		defer func() {
-			derr := file.Close()
-			if derr != nil {
-				log.WithError(derr).Error("Failed to close config.yml")
-			}
-		}()
-		err := yaml.NewDecoder(file).Decode(&c)
-		if err != nil {
-			log.WithError(err).Error("Failed to decode config.yml contents")
-		}
-	} else {
+	c.configFile = filePath
+	file, err := os.Open(filePath)
+	if err != nil {
 		c.LogLevel = logrus.InfoLevel
+		return &c, err
 	}
-	c.configFile = filePath
-	return &c
+	defer func() {
+		derr := file.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Failed to close config.yml")
+		}
+	}()
+	err = yaml.NewDecoder(file).Decode(&c)
+	if err != nil {
+		return &c, errorLog.Wrap(err, "failed to decode config.yml contents")
+	}
+	return &c, nil
 }
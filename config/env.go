@@ -0,0 +1,261 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	errorLog "github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"intel/isecl/shvs/v5/constants"
+)
+
+// EnvPrefix prefixes the environment variables overriding the configuration
+const EnvPrefix = "SHVS_"
+
+// FileEnvSuffix is appended to the environment variable of a secret setting to read its value from a file
+const FileEnvSuffix = "_FILE"
+
+// Sources of a setting, in increasing order of precedence
+const (
+	SourceConfigFile = "config.yml"
+	SourceEnv        = "env"
+	SourceFile       = "file"
+)
+
+// secretSettings can be read from the file named by their *_FILE environment variable and are never shown
+var secretSettings = map[string]bool{
+	"Postgres.Password": true,
+	"SHVS.Password":     true,
+}
+
+// setupEnvs are the environment variables read by setup for the settings it provisions. They override these
+// settings at runtime too, in place of the name derived by envName. The names setup reads without the SHVS_
+// prefix, such as CMS_BASE_URL, are prefixed at runtime, so that the variables left over from setup or shared
+// with the other services do not change the configuration of a running SHVS.
+var setupEnvs = map[string]string{
+	"CmsTLSCertDigest":               "CMS_TLS_CERT_SHA384",
+	"CMSBaseURL":                     "CMS_BASE_URL",
+	"AuthServiceURL":                 "AAS_API_URL",
+	"ScsBaseURL":                     "SCS_BASE_URL",
+	"Postgres.DBName":                "SHVS_DB_NAME",
+	"Postgres.Username":              "SHVS_DB_USERNAME",
+	"Postgres.Password":              "SHVS_DB_PASSWORD",
+	"Postgres.Hostname":              "SHVS_DB_HOSTNAME",
+	"Postgres.Port":                  "SHVS_DB_PORT",
+	"Postgres.SSLMode":               "SHVS_DB_SSLMODE",
+	"Postgres.SSLCert":               "SHVS_DB_SSLCERT",
+	"LogLevel":                       constants.SHVSLogLevel,
+	"LogEnableStdout":                "SHVS_ENABLE_CONSOLE_LOG",
+	"SHVS.User":                      "SHVS_ADMIN_USERNAME",
+	"SHVS.Password":                  "SHVS_ADMIN_PASSWORD",
+	"SHVSRefreshTimer":               "SHVS_AUTO_REFRESH_TIMER",
+	"SHVSHostInfoExpiryTime":         "SHVS_HOST_PLATFORM_EXPIRY_TIME",
+	"JobRunner.Workers":              "SHVS_JOB_WORKERS",
+	"JobRunner.QueueSize":            "SHVS_JOB_QUEUE_SIZE",
+	"JobRunner.JobTimeout":           "SHVS_JOB_TIMEOUT",
+	"JobRunner.PollInterval":         "SHVS_JOB_POLL_INTERVAL",
+	"JobRunner.RetentionDays":        "SHVS_JOB_RETENTION_DAYS",
+	"ComplianceReport.Timer":         "SHVS_REPORT_TIMER",
+	"ComplianceReport.InactiveHours": "SHVS_REPORT_INACTIVE_HOURS",
+	"ComplianceReport.RetentionDays": "SHVS_REPORT_RETENTION_DAYS",
+	"Subject.TLSCertCommonName":      "SHVS_TLS_CERT_CN",
+	"Subject.SigningCertCommonName":  "SHVS_SIGNING_CERT_CN",
+	"TLSKeyFile":                     "KEY_PATH",
+	"TLSCertFile":                    "CERT_PATH",
+	"SigningKeyFile":                 "SIGNING_KEY_PATH",
+	"SigningCertFile":                "SIGNING_CERT_PATH",
+	"CertSANList":                    "SAN_LIST",
+	"ReadTimeout":                    "SHVS_SERVER_READ_TIMEOUT",
+	"ReadHeaderTimeout":              "SHVS_SERVER_READ_HEADER_TIMEOUT",
+	"WriteTimeout":                   "SHVS_SERVER_WRITE_TIMEOUT",
+	"IdleTimeout":                    "SHVS_SERVER_IDLE_TIMEOUT",
+	"ShutdownTimeout":                "SHVS_SERVER_SHUTDOWN_TIMEOUT",
+	"MaxHeaderBytes":                 "SHVS_SERVER_MAX_HEADER_BYTES",
+}
+
+// flagSettings are enabled by any non-empty value of their environment variable, as setup reads them
+var flagSettings = map[string]bool{
+	"LogEnableStdout": true,
+}
+
+// Setting is a leaf of the configuration, addressed by its dotted path such as Postgres.Password
+type Setting struct {
+	Path string
+	// Env is the environment variable overriding the setting
+	Env    string
+	Secret bool
+	// Source tells whether the value comes from config.yml, the environment or a secret file
+	Source string
+	value  reflect.Value
+}
+
+// Value returns the current value of the setting
+func (s Setting) Value() interface{} {
+	return s.value.Interface()
+}
+
+// Settings lists the leaves of the configuration in the order of the Configuration struct
+func (conf *Configuration) Settings() []Setting {
+	var settings []Setting
+	collectSettings(reflect.ValueOf(conf).Elem(), "", &settings)
+	for i := range settings {
+		settings[i].Source = SourceConfigFile
+		if source, ok := conf.sources[settings[i].Path]; ok {
+			settings[i].Source = source
+		}
+	}
+	return settings
+}
+
+func collectSettings(v reflect.Value, prefix string, settings *[]Setting) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		path := prefix + field.Name
+		if field.Type.Kind() == reflect.Struct {
+			collectSettings(v.Field(i), path+".", settings)
+			continue
+		}
+		env, ok := setupEnvs[path]
+		switch {
+		case !ok:
+			env = envName(path)
+		case !strings.HasPrefix(env, EnvPrefix):
+			env = EnvPrefix + env
+		}
+		*settings = append(*settings, Setting{
+			Path:   path,
+			Env:    env,
+			Secret: secretSettings[path],
+			value:  v.Field(i),
+		})
+	}
+}
+
+// envName derives the environment variable of a setting not provisioned by setup from its path,
+// JobRunner.Workers becoming SHVS_JOB_RUNNER_WORKERS. The SHVS of settings such as SHVSRefreshTimer is not
+// repeated.
+func envName(path string) string {
+	var words []string
+	for _, name := range strings.Split(path, ".") {
+		words = append(words, splitCamelCase(name)...)
+	}
+	if len(words) > 1 && words[0] == "SHVS" {
+		words = words[1:]
+	}
+	return EnvPrefix + strings.Join(words, "_")
+}
+
+// splitCamelCase splits a field name into upper case words, keeping acronyms such as TLS together
+func splitCamelCase(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		lowerToUpper := unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1])
+		acronymEnd := unicode.IsUpper(runes[i]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+		if lowerToUpper || acronymEnd {
+			words = append(words, strings.ToUpper(string(runes[start:i])))
+			start = i
+		}
+	}
+	return append(words, strings.ToUpper(string(runes[start:])))
+}
+
+// ApplyEnvOverrides overrides the settings from their environment variable, the one setup reads, prefixed
+// with SHVS_ if need be, or else SHVS_*, and, for secrets, from the file named by that variable suffixed with _FILE, which takes precedence.
+// Empty variables are ignored. The overrides are kept in memory only, Save refuses to write a configuration
+// carrying them. All invalid overrides are reported together.
+func (conf *Configuration) ApplyEnvOverrides() error {
+	log.Trace("config/env:ApplyEnvOverrides() Entering")
+	defer log.Trace("config/env:ApplyEnvOverrides() Leaving")
+
+	conf.sources = make(map[string]string)
+	var problems []string
+	for _, setting := range conf.Settings() {
+		value, source, err := lookupOverride(setting)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if source == "" {
+			continue
+		}
+		if flagSettings[setting.Path] {
+			value = "true"
+		}
+		err = setValue(setting.value, value)
+		if err != nil {
+			problems = append(problems, errorLog.Wrapf(err, "%s has an invalid value", setting.Env).Error())
+			continue
+		}
+		conf.sources[setting.Path] = source
+		log.Debugf("config/env:ApplyEnvOverrides() %s overridden from %s", setting.Path, source)
+	}
+	if len(problems) > 0 {
+		return errorLog.Errorf("invalid configuration overrides: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// EnvOverridesApplied reports whether any setting was overridden from the environment
+func (conf *Configuration) EnvOverridesApplied() bool {
+	return len(conf.sources) > 0
+}
+
+// Set changes the setting at the path, matched case-insensitively, decoding the value as an override of
+// the setting would be
+func (conf *Configuration) Set(path, value string) error {
+	for _, setting := range conf.Settings() {
+		if !strings.EqualFold(setting.Path, path) {
+			continue
+		}
+		err := setValue(setting.value, value)
+		if err != nil {
+			return errorLog.Wrapf(err, "%s has an invalid value", setting.Path)
+		}
+		return nil
+	}
+	return errorLog.Errorf("no such setting: %s", path)
+}
+
+func lookupOverride(setting Setting) (string, string, error) {
+	if setting.Secret {
+		if file, ok := os.LookupEnv(setting.Env + FileEnvSuffix); ok && file != "" {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return "", "", errorLog.Wrapf(err, "%s could not be read", setting.Env+FileEnvSuffix)
+			}
+			return strings.TrimRight(string(content), "\r\n"), SourceFile, nil
+		}
+	}
+	if value, ok := os.LookupEnv(setting.Env); ok && value != "" {
+		return value, SourceEnv, nil
+	}
+	return "", "", nil
+}
+
+// setValue sets a string setting as is and decodes any other setting as YAML, so that durations such as
+// 30s, log levels and maps such as {<hardware uuid>: 60} are written as in config.yml
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.String {
+		v.SetString(value)
+		return nil
+	}
+	decoded := reflect.New(v.Type())
+	err := yaml.Unmarshal([]byte(value), decoded.Interface())
+	if err != nil {
+		return err
+	}
+	v.Set(decoded.Elem())
+	return nil
+}
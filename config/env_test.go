@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "SHVS_PORT", envName("Port"))
+	assert.Equal(t, "SHVS_POSTGRES_DB_NAME", envName("Postgres.DBName"))
+	assert.Equal(t, "SHVS_POSTGRES_SSL_CERT", envName("Postgres.SSLCert"))
+	assert.Equal(t, "SHVS_CMS_TLS_CERT_DIGEST", envName("CmsTLSCertDigest"))
+	assert.Equal(t, "SHVS_CMS_BASE_URL", envName("CMSBaseURL"))
+	assert.Equal(t, "SHVS_REFRESH_TIMER", envName("SHVSRefreshTimer"))
+	assert.Equal(t, "SHVS_PASSWORD", envName("SHVS.Password"))
+	assert.Equal(t, "SHVS_JOB_RUNNER_POLL_INTERVAL", envName("JobRunner.PollInterval"))
+}
+
+func TestSettingsUseSetupEnvs(t *testing.T) {
+	envs := make(map[string]string)
+	for _, setting := range (&Configuration{}).Settings() {
+		envs[setting.Path] = setting.Env
+	}
+	assert.Equal(t, "SHVS_PORT", envs["Port"])
+	assert.Equal(t, "SHVS_DB_HOSTNAME", envs["Postgres.Hostname"])
+	assert.Equal(t, "SHVS_ADMIN_PASSWORD", envs["SHVS.Password"])
+	assert.Equal(t, "SHVS_CMS_TLS_CERT_SHA384", envs["CmsTLSCertDigest"])
+	assert.Equal(t, "SHVS_KEY_PATH", envs["TLSKeyFile"])
+	assert.Equal(t, "SHVS_SAN_LIST", envs["CertSANList"])
+	assert.Equal(t, "SHVS_JOB_POLL_INTERVAL", envs["JobRunner.PollInterval"])
+	assert.Equal(t, "SHVS_HOST_EXPIRY_OVERRIDES", envs["HostExpiryOverrides"])
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	temp, _ := ioutil.TempFile("", "config.yml")
+	defer os.Remove(temp.Name())
+	temp.WriteString("port: 13000\npostgres:\n  hostname: localhost\n  port: 5432\n  password: file-password\nloglevel: info\n" +
+		"jobrunner:\n  jobtimeout: 10m\n")
+	temp.Close()
+
+	secret, _ := ioutil.TempFile("", "db-password")
+	defer os.Remove(secret.Name())
+	secret.WriteString("secret-password\n")
+	secret.Close()
+
+	overrides := map[string]string{
+		"SHVS_PORT":                              "13001",
+		"SHVS_DB_HOSTNAME":                       "db.shvs.svc",
+		"SHVS_DB_PASSWORD":                       "env-password",
+		"SHVS_DB_PASSWORD_FILE":                  secret.Name(),
+		"SHVS_LOGLEVEL":                          "debug",
+		"SHVS_SERVER_READ_TIMEOUT":               "45s",
+		"SHVS_HOST_EXPIRY_OVERRIDES":             "{b0fcfba4-c587-4417-ba3b-f92dbcc366f8: 60}",
+		"SHVS_ENABLE_CONSOLE_LOG":                "y",
+		"SHVS_TRACING_SAMPLE_RATIO":              "0",
+		"SHVS_RATE_LIMIT_IP_REQUESTS_PER_SECOND": "0",
+		"SHVS_CMS_BASE_URL":                      "https://cms.shvs.svc:8445/cms/v1",
+		"AAS_API_URL":                            "https://setup-only:8444/aas/v1",
+		"SHVS_DB_PORT":                           "",
+		"SHVS_JOB_TIMEOUT":                       "",
+	}
+	for env, value := range overrides {
+		os.Setenv(env, value)
+		defer os.Unsetenv(env)
+	}
+
+	c := Load(temp.Name())
+	err := c.ApplyEnvOverrides()
+	assert.NoError(t, err)
+	assert.Equal(t, 13001, c.Port)
+	assert.Equal(t, "db.shvs.svc", c.Postgres.Hostname)
+	// the secret file takes precedence over the environment variable
+	assert.Equal(t, "secret-password", c.Postgres.Password)
+	assert.Equal(t, logrus.DebugLevel, c.LogLevel)
+	assert.Equal(t, 45*time.Second, c.ReadTimeout)
+	assert.Equal(t, map[string]int{"b0fcfba4-c587-4417-ba3b-f92dbcc366f8": 60}, c.HostExpiryOverrides)
+	// setup enables the console log for any value, such as the y of the k8s config map
+	assert.True(t, c.LogEnableStdout)
+	// a sample ratio of 0 is set, turning sampling off
+	assert.NotNil(t, c.Tracing.SampleRatio)
+	assert.Equal(t, 0.0, *c.Tracing.SampleRatio)
+	// so is a rate limit of 0, turning the limit off, while the unset one keeps its default
+	assert.NotNil(t, c.RateLimit.IPRequestsPerSecond)
+	assert.Equal(t, 0.0, *c.RateLimit.IPRequestsPerSecond)
+	assert.Nil(t, c.RateLimit.SubjectRequestsPerSecond)
+	// the variables setup reads unprefixed are prefixed at runtime, setup's own are left to setup
+	assert.Equal(t, "https://cms.shvs.svc:8445/cms/v1", c.CMSBaseURL)
+	assert.Empty(t, c.AuthServiceURL)
+	// empty variables leave the configured values unchanged
+	assert.Equal(t, 5432, c.Postgres.Port)
+	assert.Equal(t, 10*time.Minute, c.JobRunner.JobTimeout)
+
+	sources := make(map[string]string)
+	for _, setting := range c.Settings() {
+		sources[setting.Path] = setting.Source
+	}
+	assert.Equal(t, SourceEnv, sources["Port"])
+	assert.Equal(t, SourceFile, sources["Postgres.Password"])
+	assert.Equal(t, SourceConfigFile, sources["Postgres.DBName"])
+	assert.Equal(t, SourceConfigFile, sources["Postgres.Port"])
+
+	// overrides are never written to config.yml
+	assert.Equal(t, ErrEnvOverrides, c.Save())
+}
+
+func TestApplyEnvOverridesReportsAllProblems(t *testing.T) {
+	os.Setenv("SHVS_PORT", "not-a-port")
+	defer os.Unsetenv("SHVS_PORT")
+	os.Setenv("SHVS_ADMIN_PASSWORD_FILE", "/invalid/path/password")
+	defer os.Unsetenv("SHVS_ADMIN_PASSWORD_FILE")
+
+	c := &Configuration{}
+	err := c.ApplyEnvOverrides()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "SHVS_PORT")
+	assert.Contains(t, err.Error(), "SHVS_ADMIN_PASSWORD_FILE")
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package config
+
+import (
+	"fmt"
+
+	errorLog "github.com/pkg/errors"
+)
+
+// Reload reads the file of the global configuration again and, once the new configuration is valid, swaps
+// it in for the global configuration. Settings only read on startup, such as the port and the database,
+// take effect on the next restart; they are returned along with the new configuration.
+func Reload() (*Configuration, []string, error) {
+	log.Trace("config/reload:Reload() Entering")
+	defer log.Trace("config/reload:Reload() Leaving")
+
+	current := Global()
+	updated, err := load(current.configFile)
+	if err != nil {
+		return nil, nil, errorLog.Wrap(err, "Reload() failed to read configuration")
+	}
+	if current.sources != nil {
+		err = updated.ApplyEnvOverrides()
+		if err != nil {
+			return nil, nil, errorLog.Wrap(err, "Reload() failed to apply configuration overrides")
+		}
+	}
+	err = updated.validate()
+	if err != nil {
+		return nil, nil, errorLog.Wrap(err, "Reload() configuration is invalid")
+	}
+
+	restartRequired := current.restartRequired(updated)
+	for _, setting := range restartRequired {
+		log.Warnf("config/reload:Reload() %s was changed and takes effect on the next restart", setting)
+	}
+	SetGlobal(updated)
+	log.Info("config/reload:Reload() configuration reloaded")
+	return updated, restartRequired, nil
+}
+
+// validate checks the settings which can be reloaded, the others being checked on the next restart
+func (conf *Configuration) validate() error {
+	problems := &ValidationError{}
+	conf.validateTimers(problems)
+	if len(problems.Problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// restartRequired lists the settings changed in updated which are only read on startup
+func (conf *Configuration) restartRequired(updated *Configuration) []string {
+	var settings []string
+	changed := func(setting string, current, updated interface{}) {
+		if fmt.Sprint(current) != fmt.Sprint(updated) {
+			settings = append(settings, setting)
+		}
+	}
+	changed("Port", conf.Port, updated.Port)
+	changed("Postgres", conf.Postgres, updated.Postgres)
+	changed("LogEnableStdout", conf.LogEnableStdout, updated.LogEnableStdout)
+	changed("LogMaxLength", conf.LogMaxLength, updated.LogMaxLength)
+	changed("LogFormat", conf.LogFormat, updated.LogFormat)
+	changed("Tracing", conf.Tracing, updated.Tracing)
+	changed("Token", conf.Token, updated.Token)
+	changed("ClientCertAuth", conf.ClientCertAuth, updated.ClientCertAuth)
+	changed("JobRunner", conf.JobRunner, updated.JobRunner)
+	changed("LeaderElectionInterval", conf.LeaderElectionInterval, updated.LeaderElectionInterval)
+	changed("TLSCertFile", conf.TLSCertFile, updated.TLSCertFile)
+	changed("TLSKeyFile", conf.TLSKeyFile, updated.TLSKeyFile)
+	changed("SigningKeyFile", conf.SigningKeyFile, updated.SigningKeyFile)
+	changed("SigningCertFile", conf.SigningCertFile, updated.SigningCertFile)
+	changed("VerdictValidityMins", conf.VerdictValidityMins, updated.VerdictValidityMins)
+	changed("ReadTimeout", conf.ReadTimeout, updated.ReadTimeout)
+	changed("ReadHeaderTimeout", conf.ReadHeaderTimeout, updated.ReadHeaderTimeout)
+	changed("WriteTimeout", conf.WriteTimeout, updated.WriteTimeout)
+	changed("IdleTimeout", conf.IdleTimeout, updated.IdleTimeout)
+	changed("MaxHeaderBytes", conf.MaxHeaderBytes, updated.MaxHeaderBytes)
+	changed("MaxRequestBodyBytes", conf.MaxRequestBodyBytes, updated.MaxRequestBodyBytes)
+	changed("RateLimit", conf.RateLimit, updated.RateLimit)
+	return settings
+}
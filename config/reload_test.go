@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReload(t *testing.T) {
+	temp, _ := ioutil.TempFile("", "config.yml")
+	defer os.Remove(temp.Name())
+	temp.WriteString("port: 13000\nloglevel: info\nshvsrefreshtimer: 120\nshvshostinfoexpirytime: 240\n")
+	temp.Close()
+
+	current := Load(temp.Name())
+	SetGlobal(current)
+	defer SetGlobal(nil)
+
+	err := ioutil.WriteFile(temp.Name(), []byte("port: 13001\nloglevel: debug\nshvsrefreshtimer: 60\nshvshostinfoexpirytime: 240\n"), 0600)
+	assert.NoError(t, err)
+	updated, restartRequired, err := Reload()
+	assert.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, updated.LogLevel)
+	assert.Equal(t, 60, updated.SHVSRefreshTimer)
+	assert.Equal(t, []string{"Port"}, restartRequired)
+	assert.Equal(t, updated, Global())
+	// the previous configuration is not changed in place
+	assert.Equal(t, 120, current.SHVSRefreshTimer)
+}
+
+func TestReloadKeepsConfigurationOnError(t *testing.T) {
+	temp, _ := ioutil.TempFile("", "config.yml")
+	defer os.Remove(temp.Name())
+	temp.WriteString("port: 13000\nshvsrefreshtimer: 120\n")
+	temp.Close()
+
+	current := Load(temp.Name())
+	SetGlobal(current)
+	defer SetGlobal(nil)
+
+	// invalid setting
+	err := ioutil.WriteFile(temp.Name(), []byte("port: 13000\nshvsrefreshtimer: -1\n"), 0600)
+	assert.NoError(t, err)
+	_, _, err = Reload()
+	assert.Error(t, err)
+	assert.Equal(t, current, Global())
+
+	// invalid host expiry override
+	err = ioutil.WriteFile(temp.Name(), []byte("port: 13000\nshvsrefreshtimer: 120\nshvshostinfoexpirytime: 240\nhostexpiryoverrides:\n  not-a-uuid: 60\n"), 0600)
+	assert.NoError(t, err)
+	_, _, err = Reload()
+	assert.Error(t, err)
+	assert.Equal(t, current, Global())
+
+	// malformed file
+	err = ioutil.WriteFile(temp.Name(), []byte("port: [13000\n"), 0600)
+	assert.NoError(t, err)
+	_, _, err = Reload()
+	assert.Error(t, err)
+	assert.Equal(t, current, Global())
+}
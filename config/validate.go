@@ -0,0 +1,243 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"intel/isecl/shvs/v5/constants"
+)
+
+// ValidationError lists all problems found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration, %d problem(s) found: %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+func (e *ValidationError) add(format string, args ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// ValidateURL checks that the URL is absolute, as the base URLs of the services SHVS connects to must be
+func ValidateURL(rawURL string) error {
+	_, err := url.ParseRequestURI(rawURL)
+	return err
+}
+
+// ValidatePort checks that the port SHVS listens on is neither reserved nor out of range
+func ValidatePort(port int) error {
+	if port <= 1024 || port > 65535 {
+		return fmt.Errorf("port %d is reserved or out of range", port)
+	}
+	return nil
+}
+
+// ParseTrustedProxies parses the IPs and CIDRs of the trusted proxies, an IP standing for a single address
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		if ip := net.ParseIP(proxy); ip != nil {
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			} else {
+				ip = ip.To4()
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q is neither an IP nor a CIDR", proxy)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Validate checks the configuration SHVS runs with and reports all problems found in a *ValidationError
+func (conf *Configuration) Validate() error {
+	log.Trace("config/validate:Validate() Entering")
+	defer log.Trace("config/validate:Validate() Leaving")
+
+	problems := &ValidationError{}
+	if conf.loadErr != nil {
+		problems.add("%s could not be loaded: %s", conf.configFile, conf.loadErr.Error())
+	}
+	if err := ValidatePort(conf.Port); err != nil {
+		problems.add("Port %d is reserved or out of range", conf.Port)
+	}
+
+	urls := []struct {
+		name  string
+		value string
+	}{
+		{"CMSBaseURL", conf.CMSBaseURL},
+		{"AuthServiceURL", conf.AuthServiceURL},
+		{"ScsBaseURL", conf.ScsBaseURL},
+	}
+	for _, u := range urls {
+		if u.value == "" {
+			problems.add("%s is not set", u.name)
+		} else if err := ValidateURL(u.value); err != nil {
+			problems.add("%s %q is not a valid URL", u.name, u.value)
+		}
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"ReadTimeout", conf.ReadTimeout},
+		{"ReadHeaderTimeout", conf.ReadHeaderTimeout},
+		{"WriteTimeout", conf.WriteTimeout},
+		{"IdleTimeout", conf.IdleTimeout},
+		{"ShutdownTimeout", conf.ShutdownTimeout},
+		{"JobRunner.JobTimeout", conf.JobRunner.JobTimeout},
+		{"JobRunner.PollInterval", conf.JobRunner.PollInterval},
+		{"LeaderElectionInterval", conf.LeaderElectionInterval},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			problems.add("%s %s must not be negative", d.name, d.value)
+		}
+	}
+
+	switch conf.LogFormat {
+	case "", constants.LogFormatText, constants.LogFormatJSON:
+	default:
+		problems.add("LogFormat %q must be %s or %s", conf.LogFormat, constants.LogFormatText, constants.LogFormatJSON)
+	}
+
+	if conf.Tracing.SampleRatio != nil && *conf.Tracing.SampleRatio > 1 {
+		problems.add("Tracing.SampleRatio %v must not be greater than 1", *conf.Tracing.SampleRatio)
+	}
+
+	if conf.MaxRequestBodyBytes < 0 {
+		problems.add("MaxRequestBodyBytes %d must not be negative", conf.MaxRequestBodyBytes)
+	}
+	if conf.RateLimit.SubjectRequestsPerSecond != nil && *conf.RateLimit.SubjectRequestsPerSecond < 0 {
+		problems.add("RateLimit.SubjectRequestsPerSecond %v must not be negative", *conf.RateLimit.SubjectRequestsPerSecond)
+	}
+	if conf.RateLimit.IPRequestsPerSecond != nil && *conf.RateLimit.IPRequestsPerSecond < 0 {
+		problems.add("RateLimit.IPRequestsPerSecond %v must not be negative", *conf.RateLimit.IPRequestsPerSecond)
+	}
+	if conf.RateLimit.SubjectBurst < 0 {
+		problems.add("RateLimit.SubjectBurst %d must not be negative", conf.RateLimit.SubjectBurst)
+	}
+	if conf.RateLimit.IPBurst < 0 {
+		problems.add("RateLimit.IPBurst %d must not be negative", conf.RateLimit.IPBurst)
+	}
+	if _, err := ParseTrustedProxies(conf.RateLimit.TrustedProxies); err != nil {
+		problems.add("RateLimit.TrustedProxies: %s", err)
+	}
+
+	conf.validateTimers(problems)
+	conf.validatePostgres(problems)
+	validateReadable(problems, "TLSCertFile", conf.TLSCertFile)
+	validateReadable(problems, "TLSKeyFile", conf.TLSKeyFile)
+
+	if len(problems.Problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// validateTimers checks the scheduler timers, the host expiry times and how they relate to each other.
+// Timers left at zero take their default, except for the auto refresh and the host expiry which are
+// always set by setup.
+func (conf *Configuration) validateTimers(problems *ValidationError) {
+	if conf.SHVSRefreshTimer == 0 {
+		problems.add("SHVSRefreshTimer is not set")
+	}
+	if conf.SHVSHostInfoExpiryTime == 0 {
+		problems.add("SHVSHostInfoExpiryTime is not set")
+	}
+	timers := []struct {
+		name  string
+		value int
+	}{
+		{"SHVSRefreshTimer", conf.SHVSRefreshTimer},
+		{"SHVSHostInfoExpiryTime", conf.SHVSHostInfoExpiryTime},
+		{"SHVSHostStaleGraceTime", conf.SHVSHostStaleGraceTime},
+		{"ComplianceReport.Timer", conf.ComplianceReport.Timer},
+		{"ComplianceReport.InactiveHours", conf.ComplianceReport.InactiveHours},
+		{"ComplianceReport.RetentionDays", conf.ComplianceReport.RetentionDays},
+		{"JobRunner.RetentionDays", conf.JobRunner.RetentionDays},
+	}
+	for _, t := range timers {
+		if t.value < 0 {
+			problems.add("%s %d must not be negative", t.name, t.value)
+		}
+	}
+
+	// hosts are expired by the auto refresh, a host would outlive its expiry time by up to a refresh interval
+	if conf.SHVSRefreshTimer > 0 && conf.SHVSHostInfoExpiryTime > 0 &&
+		time.Duration(conf.SHVSRefreshTimer)*time.Second >= time.Duration(conf.SHVSHostInfoExpiryTime)*time.Minute {
+		problems.add("SHVSRefreshTimer of %d seconds must be shorter than SHVSHostInfoExpiryTime of %d minutes",
+			conf.SHVSRefreshTimer, conf.SHVSHostInfoExpiryTime)
+	}
+	for hardwareUUID, expiryMins := range conf.HostExpiryOverrides {
+		if _, err := uuid.Parse(hardwareUUID); err != nil {
+			problems.add("HostExpiryOverrides key %s is not a hardware UUID", hardwareUUID)
+		} else if expiryMins <= 0 {
+			problems.add("HostExpiryOverrides of %s must be positive", hardwareUUID)
+		}
+	}
+}
+
+// validatePostgres checks the settings the database is opened with
+func (conf *Configuration) validatePostgres(problems *ValidationError) {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"Postgres.Hostname", conf.Postgres.Hostname},
+		{"Postgres.DBName", conf.Postgres.DBName},
+		{"Postgres.Username", conf.Postgres.Username},
+		{"Postgres.Password", conf.Postgres.Password},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			problems.add("%s is not set", r.name)
+		}
+	}
+	if conf.Postgres.Port <= 0 || conf.Postgres.Port > 65535 {
+		problems.add("Postgres.Port %d is out of range", conf.Postgres.Port)
+	}
+	// the database is opened with verify-full unless a weaker mode is set, which needs the server certificate
+	switch strings.TrimSpace(strings.ToLower(conf.Postgres.SSLMode)) {
+	case "allow", "prefer", "require":
+	default:
+		validateReadable(problems, "Postgres.SSLCert", conf.Postgres.SSLCert)
+	}
+}
+
+// validateReadable checks that the file of a setting is set and can be read by the service
+func validateReadable(problems *ValidationError, name, file string) {
+	if file == "" {
+		problems.add("%s is not set", name)
+		return
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			problems.add("%s %s does not exist", name, file)
+		} else {
+			problems.add("%s %s is not readable", name, file)
+		}
+		return
+	}
+	f.Close()
+}
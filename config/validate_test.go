@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfiguration(t *testing.T) *Configuration {
+	cert, _ := ioutil.TempFile("", "tls-cert.pem")
+	cert.Close()
+	t.Cleanup(func() { os.Remove(cert.Name()) })
+
+	conf := &Configuration{
+		Port:                   13000,
+		CMSBaseURL:             "https://cms.com:8445/cms/v1/",
+		AuthServiceURL:         "https://aas.com:8444/aas/v1/",
+		ScsBaseURL:             "https://scs.com:9000/scs/sgx/certification/v1/",
+		SHVSRefreshTimer:       120,
+		SHVSHostInfoExpiryTime: 240,
+		ShutdownTimeout:        30 * time.Second,
+		TLSCertFile:            cert.Name(),
+		TLSKeyFile:             cert.Name(),
+	}
+	conf.Postgres.Hostname = "localhost"
+	conf.Postgres.Port = 5432
+	conf.Postgres.DBName = "shvsdb"
+	conf.Postgres.Username = "shvs"
+	conf.Postgres.Password = "password"
+	conf.Postgres.SSLMode = "verify-full"
+	conf.Postgres.SSLCert = cert.Name()
+	return conf
+}
+
+func TestValidate(t *testing.T) {
+	conf := validConfiguration(t)
+	assert.NoError(t, conf.Validate())
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	conf := validConfiguration(t)
+	conf.Port = 137
+	conf.AuthServiceURL = "aas.com"
+	conf.ScsBaseURL = ""
+	conf.LogFormat = "xml"
+	sampleRatio := 1.5
+	conf.Tracing.SampleRatio = &sampleRatio
+	conf.RateLimit.IPBurst = -1
+	conf.RateLimit.TrustedProxies = []string{"10.0.0.0/8", "ingress"}
+	conf.ShutdownTimeout = -time.Second
+	conf.SHVSRefreshTimer = 240 * 60
+	conf.TLSKeyFile = "/nonexistent/tls.key"
+	conf.HostExpiryOverrides = map[string]int{"not-a-uuid": 60}
+
+	err := conf.Validate()
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, []string{
+		"Port 137 is reserved or out of range",
+		"AuthServiceURL \"aas.com\" is not a valid URL",
+		"ScsBaseURL is not set",
+		"ShutdownTimeout -1s must not be negative",
+		"LogFormat \"xml\" must be text or json",
+		"Tracing.SampleRatio 1.5 must not be greater than 1",
+		"RateLimit.IPBurst -1 must not be negative",
+		"RateLimit.TrustedProxies: trusted proxy \"ingress\" is neither an IP nor a CIDR",
+		"SHVSRefreshTimer of 14400 seconds must be shorter than SHVSHostInfoExpiryTime of 240 minutes",
+		"HostExpiryOverrides key not-a-uuid is not a hardware UUID",
+		"TLSKeyFile /nonexistent/tls.key does not exist",
+	}, validationErr.Problems)
+}
+
+func TestValidateRequiresTimersAndDatabase(t *testing.T) {
+	conf := validConfiguration(t)
+	conf.SHVSRefreshTimer = 0
+	conf.SHVSHostInfoExpiryTime = 0
+	conf.Postgres.DBName = ""
+	conf.Postgres.Port = 70000
+	conf.Postgres.SSLCert = "/nonexistent/db.crt"
+
+	err := conf.Validate()
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, []string{
+		"SHVSRefreshTimer is not set",
+		"SHVSHostInfoExpiryTime is not set",
+		"Postgres.DBName is not set",
+		"Postgres.Port 70000 is out of range",
+		"Postgres.SSLCert /nonexistent/db.crt does not exist",
+	}, validationErr.Problems)
+
+	// no server certificate is needed unless the server is verified
+	conf = validConfiguration(t)
+	conf.Postgres.SSLMode = "require"
+	conf.Postgres.SSLCert = ""
+	assert.NoError(t, conf.Validate())
+}
+
+func TestValidateReportsLoadError(t *testing.T) {
+	temp, _ := ioutil.TempFile("", "config.yml")
+	defer os.Remove(temp.Name())
+	temp.WriteString("port: [13000\n")
+	temp.Close()
+
+	conf := Load(temp.Name())
+	err := conf.Validate()
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Contains(t, validationErr.Problems[0], temp.Name()+" could not be loaded")
+}
+
+func TestSet(t *testing.T) {
+	conf := validConfiguration(t)
+	assert.NoError(t, conf.Set("shvsrefreshtimer", "60"))
+	assert.Equal(t, 60, conf.SHVSRefreshTimer)
+	assert.NoError(t, conf.Set("Postgres.DBName", "shvsdb"))
+	assert.Equal(t, "shvsdb", conf.Postgres.DBName)
+	assert.NoError(t, conf.Set("ShutdownTimeout", "1m"))
+	assert.Equal(t, time.Minute, conf.ShutdownTimeout)
+
+	assert.Error(t, conf.Set("SHVSRefreshTimer", "soon"))
+	assert.Error(t, conf.Set("NoSuchSetting", "1"))
+}
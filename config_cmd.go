@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"intel/isecl/shvs/v5/config"
+	"intel/isecl/shvs/v5/constants"
+)
+
+const redactedValue = "<redacted>"
+
+// configCommand runs the config subcommands
+func (a *App) configCommand(args []string) error {
+	log.Trace("config_cmd:configCommand() Entering")
+	defer log.Trace("config_cmd:configCommand() Leaving")
+
+	if len(args) == 0 {
+		a.printUsage()
+		return errors.New("No config subcommand given")
+	}
+	switch args[0] {
+	case "show":
+		return a.showConfiguration()
+	case "validate":
+		return a.validateConfiguration()
+	case "set":
+		return a.setConfiguration(args[1:])
+	default:
+		a.printUsage()
+		return errors.Errorf("No such config subcommand: %s", args[0])
+	}
+}
+
+// showConfiguration prints the effective configuration the service runs with, along with the source and
+// the environment variable of every setting. Secrets are redacted.
+func (a *App) showConfiguration() error {
+	conf := a.configuration()
+	err := conf.ApplyEnvOverrides()
+	if err != nil {
+		return errors.Wrap(err, "config show")
+	}
+
+	w := a.consoleWriter()
+	fmt.Fprintln(w, "# Effective configuration of SGX Host Verification Service")
+	fmt.Fprintln(w, "# Precedence, highest first:")
+	fmt.Fprintln(w, "#   1. file named by the ENVIRONMENT variable suffixed with "+config.FileEnvSuffix+" (secrets only)")
+	fmt.Fprintln(w, "#   2. ENVIRONMENT variable, the one setup reads for the settings it provisions, prefixed with "+config.EnvPrefix)
+	fmt.Fprintln(w, "#      when setup reads it unprefixed; empty values are ignored")
+	fmt.Fprintln(w, "#   3. "+path.Join(constants.ConfigDir, constants.ConfigFile))
+	fmt.Fprintln(w, "")
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE\tENVIRONMENT")
+	for _, setting := range conf.Settings() {
+		value := fmt.Sprint(setting.Value())
+		env := setting.Env
+		if setting.Secret {
+			if value != "" {
+				value = redactedValue
+			}
+			env = strings.Join([]string{setting.Env + config.FileEnvSuffix, setting.Env}, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", setting.Path, value, setting.Source, env)
+	}
+	return tw.Flush()
+}
+
+// validateConfiguration checks the effective configuration, printing every problem found
+func (a *App) validateConfiguration() error {
+	conf := a.configuration()
+	err := conf.ApplyEnvOverrides()
+	if err != nil {
+		return errors.Wrap(err, "config validate")
+	}
+
+	w := a.consoleWriter()
+	err = conf.Validate()
+	if validationErr, ok := err.(*config.ValidationError); ok {
+		for _, problem := range validationErr.Problems {
+			fmt.Fprintln(w, problem)
+		}
+		return errors.Errorf("config validate: %d problem(s) found", len(validationErr.Problems))
+	}
+	if err != nil {
+		return errors.Wrap(err, "config validate")
+	}
+	fmt.Fprintln(w, "Configuration is valid")
+	return nil
+}
+
+// setConfiguration changes settings of config.yml given as key=value. Overrides from the environment are
+// not applied, so that they are never written to config.yml. Problems the configuration already had are
+// reported by 'config validate' and do not prevent the change.
+func (a *App) setConfiguration(args []string) error {
+	if len(args) == 0 {
+		a.printUsage()
+		return errors.New("config set: no key=value given")
+	}
+	conf := a.configuration()
+	existing := problemSet(conf.Validate())
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return errors.Errorf("config set: %s is not in key=value format", arg)
+		}
+		err := conf.Set(strings.TrimSpace(kv[0]), kv[1])
+		if err != nil {
+			return errors.Wrap(err, "config set")
+		}
+	}
+
+	var problems []string
+	for problem := range problemSet(conf.Validate()) {
+		if !existing[problem] {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.Errorf("config set: %s", strings.Join(problems, "; "))
+	}
+	err := conf.Save()
+	if err != nil {
+		return errors.Wrap(err, "config set")
+	}
+	fmt.Fprintln(a.consoleWriter(), "Configuration updated, run 'shvs config validate' to check it")
+	return nil
+}
+
+func problemSet(err error) map[string]bool {
+	problems := make(map[string]bool)
+	if validationErr, ok := err.(*config.ValidationError); ok {
+		for _, problem := range validationErr.Problems {
+			problems[problem] = true
+		}
+	}
+	return problems
+}
@@ -17,9 +17,12 @@ const (
 	LogFile                       = LogDir + "shvs.log"
 	SecurityLogFile               = LogDir + "shvs-security.log"
 	HTTPLogFile                   = LogDir + "http.log"
+	AuditLogFile                  = LogDir + "shvs-audit.log"
 	ConfigFile                    = "config.yml"
 	DefaultTLSCertFile            = ConfigDir + "tls-cert.pem"
 	DefaultTLSKeyFile             = ConfigDir + "tls.key"
+	DefaultSigningCertFile        = ConfigDir + "signing-cert.pem"
+	DefaultSigningKeyFile         = ConfigDir + "signing.key"
 	TrustedJWTSigningCertsDir     = ConfigDir + "certs/trustedjwt/"
 	TrustedCAsStoreDir            = ConfigDir + "certs/trustedca/"
 	ServiceRemoveCmd              = "systemctl disable shvs"
@@ -31,6 +34,9 @@ const (
 	HostListReaderGroupName       = "HostsListReader"
 	HostDataReaderGroupName       = "HostDataReader"
 	HostListManagerGroupName      = "HostListManager"
+	JobAdminGroupName             = "JobAdministrator"
+	ConfigAdminGroupName          = "ConfigAdministrator"
+	AuditReaderGroupName          = "AuditReader"
 	SHVSUserName                  = "shvs"
 	ExpiryTimeKeyName             = "validTo"
 	DefaultHTTPSPort              = 13000
@@ -38,15 +44,66 @@ const (
 	DefaultKeyAlgorithmLength     = 3072
 	DefaultSHVSTlsSan             = "127.0.0.1,localhost"
 	DefaultSHVSTlsCn              = "SHVS TLS Certificate"
-	DefaultSHVSSchedulerTimer     = 60
+	DefaultSHVSSigningCn          = "SHVS Signing Certificate"
+	SigningCertType               = "JWT-Signing"
+	DefaultVerdictValidityMins    = 5
+	VerdictTokenIssuer            = "SHVS"
+	DefaultJobWorkers             = 10
+	DefaultJobQueueSize           = 100
+	DefaultJobTimeout             = 5 * time.Minute
+	DefaultJobPollInterval        = 5 * time.Second
+	DefaultJobMaxAttempts         = 3
+	DefaultJobRetryDelay          = 30 * time.Second
+	DefaultJobRetentionDays       = 7
+	DefaultJobsLimit              = 100
+	MaxJobsLimit                  = 1000
+	JobStatusQueued               = "QUEUED"
+	JobStatusProcessing           = "PROCESSING"
+	JobStatusCompleted            = "COMPLETED"
+	JobStatusError                = "ERROR"
+	JobStatusCancelled            = "CANCELLED"
+	JobTypePolicyReevaluation     = "policy-reevaluation"
+	JobTypeComplianceReport       = "compliance-report"
+	JobTypeAutoRefresh            = "auto-refresh"
+	AuditActionHostRegister       = "host-register"
+	AuditActionHostUpdate         = "host-update"
+	AuditActionHostRestore        = "host-restore"
+	AuditActionHostDelete         = "host-delete"
+	AuditActionPolicyCreate       = "policy-create"
+	AuditActionPolicyUpdate       = "policy-update"
+	AuditActionPolicyDelete       = "policy-delete"
+	AuditActionJobRetry           = "job-retry"
+	AuditActionJobCancel          = "job-cancel"
+	AuditActionConfigReload       = "config-reload"
+	AuditResultSuccess            = "SUCCESS"
+	AuditResultFailure            = "FAILURE"
+	AuditResultDenied             = "DENIED"
+	DefaultAuditEventsLimit       = 100
+	MaxAuditEventsLimit           = 1000
+	SchedulerLeaderLockKey        = 0x53485653
+	DefaultLeaderElectionInterval = 10 * time.Second
 	DefaultSHVSAutoRefreshTimer   = 120
 	DefaultSHVSHostInfoExpiryTime = 4 * 60 * 60
+	DefaultSHVSHostStaleGraceTime = 30
+	DefaultReportTimer            = 24 * 60 * 60
+	DefaultReportInactiveHours    = 24
+	DefaultReportRetentionDays    = 30
+	ReportCheckInterval           = time.Minute
 	SHVSLogLevel                  = "SHVS_LOGLEVEL"
+	SHVSLogFormat                 = "SHVS_LOG_FORMAT"
+	LogFormatText                 = "text"
+	LogFormatJSON                 = "json"
 	DefaultReadTimeout            = 30 * time.Second
 	DefaultReadHeaderTimeout      = 10 * time.Second
 	DefaultWriteTimeout           = 10 * time.Second
 	DefaultIdleTimeout            = 10 * time.Second
+	DefaultShutdownTimeout        = 30 * time.Second
 	DefaultMaxHeaderBytes         = 1 << 20
+	DefaultMaxRequestBodyBytes    = 1 << 20
+	DefaultSubjectRequestsPerSec  = 10
+	DefaultSubjectRequestBurst    = 20
+	DefaultIPRequestsPerSec       = 20
+	DefaultIPRequestBurst         = 40
 	DefaultLogEntryMaxLength      = 300
 	UUID                          = "uuid"
 	Description                   = "description"
@@ -54,12 +111,29 @@ const (
 	ID                            = "id"
 	HostID                        = "host-id"
 	HostStatus                    = "host-status"
+	PolicyName                    = "policy-name"
+	PolicyDesc                    = "policy-description"
+	JobType                       = "job-type"
+	LabelKey                      = "label-key"
+	LabelValue                    = "label-value"
+	MaxHostLabels                 = 16
+	RoleContextLabels             = "labels"
+	RoleContextHostname           = "hostname"
 	HostStatusInactive            = "IN-ACTIVE"
+	HostStatusStale               = "STALE"
 	HostStatusConnected           = "CONNECTED"
 	HostStatusRemoved             = "REMOVED"
 	MaxQueryParamsLength          = 50
 	HstsHeaderKey                 = "Strict-Transport-Security"
 	HstsHeaderValue               = "max-age=63072000; includeSubDomains"
+	HTTPMediaTypeNDJSON           = "application/x-ndjson"
+	HTTPMediaTypeCSV              = "text/csv"
+	HostsCSVFileName              = "hosts.csv"
+	HTTPMediaTypeJWT              = "application/jwt"
+	HTTPMediaTypePemFile          = "application/x-pem-file"
+	HTTPMediaTypeProblemJSON      = "application/problem+json"
+	RequestIDHeaderKey            = "X-Request-ID"
+	StreamFlushRowCount           = 100
 	DBMaxConnPercentage           = 70 // Percentage of DB's max connection. Ideally this should be around 25 to 75 % as we don't want to exhaust DB's connections.
 	DBConnMaxLifetimeMinutes      = 20 // DB connection lifetime.
 )
@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+// Package logging formats the application, security and HTTP logs as JSON, one object per line, with the
+// same fields in all three logs so that they can be parsed without regular expressions
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/handlers"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/requestid"
+)
+
+// Fields of the JSON log entries
+const (
+	TimestampField = "timestamp"
+	LevelField     = "level"
+	ComponentField = "component"
+	MessageField   = "message"
+	UserField      = "user"
+	HostIDField    = "host_id"
+	TraceIDField   = "trace_id"
+)
+
+// ComponentHTTP is the component of the HTTP log entries
+const ComponentHTTP = "http"
+
+// JSONFormatter formats the entries of the application and security logs. The component is the name of
+// the logger, default or security.
+type JSONFormatter struct {
+	// MaxLength truncates longer messages, there is no limit when it is zero
+	MaxLength int
+}
+
+func (f *JSONFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(e.Data)+4)
+	for k, v := range e.Data {
+		switch k {
+		case "name", "package":
+			data[ComponentField] = v
+		default:
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			data[k] = v
+		}
+	}
+	message := e.Message
+	if f.MaxLength > 0 && len(message) > f.MaxLength {
+		message = message[:f.MaxLength]
+	}
+	data[TimestampField] = e.Time.Format(time.RFC3339Nano)
+	data[LevelField] = e.Level.String()
+	data[MessageField] = message
+	return marshalLine(data)
+}
+
+type requestFieldsKey struct{}
+
+// requestFields holds the fields of a request known once it was authenticated and routed, such as the user
+// and the host, for its HTTP log entry which is written by a handler outside of the router
+type requestFields struct {
+	mu     sync.Mutex
+	fields logrus.Fields
+}
+
+// Handler stores in the request context the holder of the fields added by AddRequestFields. It wraps the
+// HTTP logging handler, so that WriteJSONHTTPLog reads the fields the resource handlers added.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestFieldsKey{}, &requestFields{})))
+	})
+}
+
+// AddRequestFields adds the fields, such as the user and host_id of the application log, to the HTTP log
+// entry of the request. It does nothing outside of Handler.
+func AddRequestFields(ctx context.Context, fields logrus.Fields) {
+	holder, ok := ctx.Value(requestFieldsKey{}).(*requestFields)
+	if !ok {
+		return
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	if holder.fields == nil {
+		holder.fields = logrus.Fields{}
+	}
+	for k, v := range fields {
+		holder.fields[k] = v
+	}
+}
+
+// WriteJSONHTTPLog writes the entries of the HTTP log, it is the log formatter of the HTTP logging handler.
+// The user, host_id and trace_id of the request are written as in the application log when it was served
+// under Handler.
+func WriteJSONHTTPLog(w io.Writer, params handlers.LogFormatterParams) {
+	req := params.Request
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	data := logrus.Fields{
+		TimestampField:     params.TimeStamp.Format(time.RFC3339Nano),
+		LevelField:         logrus.InfoLevel.String(),
+		ComponentField:     ComponentHTTP,
+		MessageField:       fmt.Sprintf("%s %s %d", req.Method, params.URL.RequestURI(), params.StatusCode),
+		requestid.LogField: requestid.FromContext(req.Context()),
+		"remote_addr":      host,
+		"method":           req.Method,
+		"uri":              params.URL.RequestURI(),
+		"protocol":         req.Proto,
+		"status":           params.StatusCode,
+		"size":             params.Size,
+		"referer":          req.Referer(),
+		"user_agent":       req.UserAgent(),
+	}
+	if holder, ok := req.Context().Value(requestFieldsKey{}).(*requestFields); ok {
+		holder.mu.Lock()
+		for k, v := range holder.fields {
+			if _, set := data[k]; !set {
+				data[k] = v
+			}
+		}
+		holder.mu.Unlock()
+	}
+	line, err := marshalLine(data)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(line)
+}
+
+func marshalLine(data logrus.Fields) ([]byte, error) {
+	line, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal log entry: %v", err)
+	}
+	return append(line, '\n'), nil
+}
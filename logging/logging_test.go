@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/handlers"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"intel/isecl/shvs/v5/requestid"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"name":             "security",
+		requestid.LogField: "7d4b2a6c",
+		UserField:          "b0fcfba4-c587-4417-ba3b-f92dbcc366f8",
+	}).WithError(errors.New("token expired"))
+	entry.Time = time.Date(2022, 5, 4, 10, 30, 0, 0, time.UTC)
+	entry.Level = logrus.WarnLevel
+	entry.Message = "Failed to match host identity from token"
+
+	f := &JSONFormatter{}
+	line, err := f.Format(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, byte('\n'), line[len(line)-1])
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(line, &fields))
+	assert.Equal(t, map[string]interface{}{
+		TimestampField:     "2022-05-04T10:30:00Z",
+		LevelField:         "warning",
+		ComponentField:     "security",
+		MessageField:       "Failed to match host identity from token",
+		requestid.LogField: "7d4b2a6c",
+		UserField:          "b0fcfba4-c587-4417-ba3b-f92dbcc366f8",
+		logrus.ErrorKey:    "token expired",
+	}, fields)
+}
+
+func TestJSONFormatterTruncatesMessage(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "0123456789"
+
+	f := &JSONFormatter{MaxLength: 4}
+	line, err := f.Format(entry)
+	assert.NoError(t, err)
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(line, &fields))
+	assert.Equal(t, "0123", fields[MessageField])
+}
+
+func TestWriteJSONHTTPLog(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/sgx-hvs/v2/hosts", nil)
+	req.RemoteAddr = "10.0.0.1:41000"
+	req = req.WithContext(requestid.NewContext(req.Context(), "7d4b2a6c"))
+
+	var buf bytes.Buffer
+	WriteJSONHTTPLog(&buf, handlers.LogFormatterParams{
+		Request:    req,
+		URL:        *req.URL,
+		TimeStamp:  time.Date(2022, 5, 4, 10, 30, 0, 0, time.UTC),
+		StatusCode: http.StatusCreated,
+		Size:       96,
+	})
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	assert.Equal(t, ComponentHTTP, fields[ComponentField])
+	assert.Equal(t, "info", fields[LevelField])
+	assert.Equal(t, "7d4b2a6c", fields[requestid.LogField])
+	assert.Equal(t, "10.0.0.1", fields["remote_addr"])
+	assert.Equal(t, "/sgx-hvs/v2/hosts", fields["uri"])
+	assert.Equal(t, float64(http.StatusCreated), fields["status"])
+	assert.Equal(t, "POST /sgx-hvs/v2/hosts 201", fields[MessageField])
+}
+
+func TestWriteJSONHTTPLogRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	h := Handler(handlers.CustomLoggingHandler(&buf, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddRequestFields(r.Context(), logrus.Fields{
+			UserField:   "b0fcfba4-c587-4417-ba3b-f92dbcc366f8",
+			HostIDField: "5b4f6b4c-8c1e-4e44-9d4c-1f5c2a3d8e90",
+			"status":    "ignored",
+		})
+		w.WriteHeader(http.StatusOK)
+	}), WriteJSONHTTPLog))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sgx-hvs/v2/hosts/5b4f6b4c-8c1e-4e44-9d4c-1f5c2a3d8e90", nil))
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	assert.Equal(t, "b0fcfba4-c587-4417-ba3b-f92dbcc366f8", fields[UserField])
+	assert.Equal(t, "5b4f6b4c-8c1e-4e44-9d4c-1f5c2a3d8e90", fields[HostIDField])
+	assert.Equal(t, float64(http.StatusOK), fields["status"])
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLimiter(rate float64, burst int) (*Limiter, *time.Time) {
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	l := NewLimiter(rate, burst)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestAllowBurstThenRate(t *testing.T) {
+	l, now := newTestLimiter(2, 3)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := l.Allow("admin")
+		assert.True(t, allowed)
+	}
+	allowed, wait := l.Allow("admin")
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// other keys have their own bucket
+	allowed, _ = l.Allow("operator")
+	assert.True(t, allowed)
+
+	*now = now.Add(500 * time.Millisecond)
+	allowed, _ = l.Allow("admin")
+	assert.True(t, allowed)
+	allowed, _ = l.Allow("admin")
+	assert.False(t, allowed)
+
+	// the bucket does not refill beyond the burst
+	*now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		allowed, _ = l.Allow("admin")
+		assert.True(t, allowed)
+	}
+	allowed, _ = l.Allow("admin")
+	assert.False(t, allowed)
+}
+
+func TestSweepDropsIdleBuckets(t *testing.T) {
+	l, now := newTestLimiter(1, 2)
+	l.Allow("admin")
+	l.Allow("operator")
+	l.Allow("operator")
+
+	*now = now.Add(sweepInterval)
+	l.Allow("operator")
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, "operator")
+}
@@ -0,0 +1,21 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package repository
+
+import (
+	"intel/isecl/shvs/v5/types"
+)
+
+// AuditEventRepository stores the audit events and the heads of the audit log files. Events are never
+// updated nor deleted.
+type AuditEventRepository interface {
+	Create(*types.AuditEvent) (*types.AuditEvent, error)
+	// RetrieveAll returns the events matching the filter, newest first
+	RetrieveAll(*types.AuditEventFilter) (types.AuditEvents, error)
+	// RetrieveHead returns the head of the audit log file, nil when none was stored
+	RetrieveHead(logID string) (*types.AuditLogHead, error)
+	// AdvanceHead stores the head of the audit log file, unless a later head of the file is stored already
+	AdvanceHead(*types.AuditLogHead) error
+}
@@ -0,0 +1,19 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package repository
+
+import (
+	"intel/isecl/shvs/v5/types"
+	"time"
+)
+
+type ComplianceReportRepository interface {
+	Create(*types.ComplianceReport) (*types.ComplianceReport, error)
+	Retrieve(*types.ComplianceReport) (*types.ComplianceReport, error)
+	RetrieveAll() (types.ComplianceReports, error)
+	// RetrieveLatest returns the summary of the newest report, nil when none was stored
+	RetrieveLatest() (*types.ComplianceReport, error)
+	DeleteOlderThan(time.Time) (int64, error)
+}
@@ -4,10 +4,20 @@
  */
 package repository
 
+import "context"
+
 type SHVSDatabase interface {
 	Migrate() error
+	// WithContext returns the database logging the request ID carried by ctx
+	WithContext(ctx context.Context) SHVSDatabase
 	HostRepository() HostRepository
 	HostStatusRepository() HostStatusRepository
 	HostSgxDataRepository() HostSgxDataRepository
+	ComplianceReportRepository() ComplianceReportRepository
+	PolicyRepository() PolicyRepository
+	HostPolicyVerdictRepository() HostPolicyVerdictRepository
+	JobRepository() JobRepository
+	AuditEventRepository() AuditEventRepository
+	LeaderLock() LeaderLock
 	Close()
 }
@@ -0,0 +1,15 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package repository
+
+import (
+	"intel/isecl/shvs/v5/types"
+)
+
+type HostPolicyVerdictRepository interface {
+	Create(*types.HostPolicyVerdict) (*types.HostPolicyVerdict, error)
+	Retrieve(*types.HostPolicyVerdict) (*types.HostPolicyVerdict, error)
+	Update(*types.HostPolicyVerdict) error
+}
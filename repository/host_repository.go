@@ -11,6 +11,8 @@ type HostRepository interface {
 	Retrieve(*types.Host, *types.HostInfoFetchCriteria) (*types.HostInfo, error)
 	RetrieveAnyIfExists(*types.Host) (*types.Host, error)
 	GetHostQuery(*types.Host, *types.HostInfoFetchCriteria) ([]*types.HostInfo, error)
+	StreamHostQuery(*types.Host, *types.HostInfoFetchCriteria, func(*types.HostInfo) error) error
+	StreamHostInventory(*types.Host, *types.HostInfoFetchCriteria, func(*types.HostInventory) error) error
 	Update(*types.Host) error
 	Delete(*types.Host) error
 }
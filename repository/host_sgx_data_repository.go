@@ -12,8 +12,10 @@ import (
 type HostSgxDataRepository interface {
 	Create(*types.HostSgxData) (*types.HostSgxData, error)
 	Retrieve(*types.HostSgxData) (*types.HostSgxData, error)
-	RetrieveAll(*types.HostSgxData) (*types.HostsSgxData, error)
+	// RetrieveAll, GetPlatformData and StreamPlatformData return the data of hosts in one of statuses
+	RetrieveAll(h *types.HostSgxData, statuses []string) (*types.HostsSgxData, error)
 	Update(*types.HostSgxData) error
 	Delete(*types.HostSgxData) error
-	GetPlatformData(updatedTime time.Time) (*types.HostsSgxData, error)
+	GetPlatformData(updatedTime time.Time, statuses []string) (*types.HostsSgxData, error)
+	StreamPlatformData(updatedTime time.Time, statuses []string, fn func(*types.PlatformData) error) error
 }
@@ -5,6 +5,9 @@
 package repository
 
 import (
+	"time"
+
+	"github.com/google/uuid"
 	"intel/isecl/shvs/v5/types"
 )
 
@@ -12,6 +15,13 @@ type HostStatusRepository interface {
 	Create(*types.HostStatus) (*types.HostStatus, error)
 	Retrieve(*types.HostStatus) (*types.HostStatus, error)
 	Update(*types.HostStatus) error
-	RetrieveExpiredHosts() (types.HostStatuses, error)
+	// ExpireHosts moves the hosts in fromStatus whose expiry time passed more than grace ago to
+	// toStatus and returns their IDs
+	ExpireHosts(fromStatus, toStatus string, grace time.Duration) ([]uuid.UUID, error)
 	RetrieveNonExpiredHost(*types.HostStatus) (*types.HostStatus, error)
+	// ExtendExpiry marks the host connected until expiryTime when it is in one of statuses. It returns
+	// false when the host is in none of them.
+	ExtendExpiry(hostID uuid.UUID, expiryTime time.Time, statuses []string) (bool, error)
+	// RetrieveInStatus returns the status of the host when it is in one of the given statuses
+	RetrieveInStatus(h *types.HostStatus, statuses []string) (*types.HostStatus, error)
 }
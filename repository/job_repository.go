@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package repository
+
+import (
+	"time"
+
+	"intel/isecl/shvs/v5/types"
+)
+
+type JobRepository interface {
+	Create(*types.Job) (*types.Job, error)
+	// CreateUnlessQueued stores the job in a single statement unless a job of its type is already
+	// queued. It returns false when the job was not stored.
+	CreateUnlessQueued(*types.Job) (bool, error)
+	Retrieve(*types.Job) (*types.Job, error)
+	// RetrieveAll returns at most limit jobs matching the type and status of the filter, newest first
+	RetrieveAll(filter *types.Job, limit int) (types.Jobs, error)
+	Update(*types.Job) error
+	// CompareAndSwap stores updated unless the job was changed since old was read, as compared by status,
+	// owner and attempts. It returns false when the job was changed.
+	CompareAndSwap(old, updated *types.Job) (bool, error)
+	// Claim marks the next runnable job as processing by owner until the lease expires. It returns
+	// nil when there is no job to run.
+	Claim(owner string, lease time.Duration) (*types.Job, error)
+	// DeleteFinishedBefore deletes the completed, failed and cancelled jobs last updated before the
+	// given time and returns the number of jobs deleted
+	DeleteFinishedBefore(time.Time) (int64, error)
+}
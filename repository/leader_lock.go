@@ -0,0 +1,16 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package repository
+
+import "context"
+
+// LeaderLock is held by at most one SHVS instance at a time and is released when the holding instance
+// goes away, so that another instance can take over
+type LeaderLock interface {
+	// TryAcquire takes the lock unless another instance holds it. Once acquired, every call checks
+	// again that the lock is still held.
+	TryAcquire(ctx context.Context) (bool, error)
+	Release() error
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"intel/isecl/shvs/v5/types"
+	"sync"
+)
+
+type MockAuditEventRepository struct {
+	AuditEvents []types.AuditEvent
+	Heads       map[string]types.AuditLogHead
+	mutex       sync.Mutex
+}
+
+func (m *MockAuditEventRepository) Create(e *types.AuditEvent) (*types.AuditEvent, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.AuditEvents = append(m.AuditEvents, *e)
+	return e, nil
+}
+
+func (m *MockAuditEventRepository) RetrieveAll(filter *types.AuditEventFilter) (types.AuditEvents, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var events types.AuditEvents
+	for i := len(m.AuditEvents) - 1; i >= 0; i-- {
+		e := m.AuditEvents[i]
+		if (filter.Action == "" || e.Action == filter.Action) && (filter.Subject == "" || e.Subject == filter.Subject) &&
+			(filter.Target == "" || e.Target == filter.Target) && (filter.Result == "" || e.Result == filter.Result) &&
+			(filter.FromTime.IsZero() || !e.Time.Before(filter.FromTime)) && (filter.ToTime.IsZero() || !e.Time.After(filter.ToTime)) {
+			events = append(events, e)
+		}
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+func (m *MockAuditEventRepository) RetrieveHead(logID string) (*types.AuditLogHead, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	head, ok := m.Heads[logID]
+	if !ok {
+		return nil, nil
+	}
+	return &head, nil
+}
+
+func (m *MockAuditEventRepository) AdvanceHead(head *types.AuditLogHead) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.Heads == nil {
+		m.Heads = make(map[string]types.AuditLogHead)
+	}
+	if stored, ok := m.Heads[head.LogID]; !ok || stored.Sequence < head.Sequence {
+		m.Heads[head.LogID] = *head
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"errors"
+	"intel/isecl/shvs/v5/types"
+	"time"
+)
+
+type MockComplianceReportRepository struct {
+	ComplianceReports []types.ComplianceReport
+}
+
+func (m *MockComplianceReportRepository) Create(cr *types.ComplianceReport) (*types.ComplianceReport, error) {
+	m.ComplianceReports = append(m.ComplianceReports, *cr)
+	return cr, nil
+}
+
+func (m *MockComplianceReportRepository) Retrieve(cr *types.ComplianceReport) (*types.ComplianceReport, error) {
+	for _, thisReport := range m.ComplianceReports {
+		if thisReport.ID == cr.ID {
+			return &thisReport, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *MockComplianceReportRepository) RetrieveAll() (types.ComplianceReports, error) {
+	var reports types.ComplianceReports
+	for _, thisReport := range m.ComplianceReports {
+		thisReport.NonCompliantHosts = nil
+		reports = append(reports, thisReport)
+	}
+	return reports, nil
+}
+
+func (m *MockComplianceReportRepository) RetrieveLatest() (*types.ComplianceReport, error) {
+	var latest *types.ComplianceReport
+	for i := range m.ComplianceReports {
+		if latest == nil || m.ComplianceReports[i].CreatedTime.After(latest.CreatedTime) {
+			report := m.ComplianceReports[i]
+			report.NonCompliantHosts = nil
+			latest = &report
+		}
+	}
+	return latest, nil
+}
+
+func (m *MockComplianceReportRepository) DeleteOlderThan(createdBefore time.Time) (int64, error) {
+	var kept []types.ComplianceReport
+	for _, thisReport := range m.ComplianceReports {
+		if !thisReport.CreatedTime.Before(createdBefore) {
+			kept = append(kept, thisReport)
+		}
+	}
+	deleted := int64(len(m.ComplianceReports) - len(kept))
+	m.ComplianceReports = kept
+	return deleted, nil
+}
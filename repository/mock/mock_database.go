@@ -5,6 +5,8 @@
 package mock
 
 import (
+	"context"
+
 	"intel/isecl/shvs/v5/repository"
 )
 
@@ -12,20 +14,33 @@ type MockDatabase struct {
 	MockHostRepository        MockHostRepository
 	MockHostStatusRepository  MockHostStatusRepository
 	MockHostSgxDataRepository MockHostSgxDataRepository
+
+	MockComplianceReportRepository  MockComplianceReportRepository
+	MockPolicyRepository            MockPolicyRepository
+	MockHostPolicyVerdictRepository MockHostPolicyVerdictRepository
+	MockJobRepository               MockJobRepository
+	MockAuditEventRepository        MockAuditEventRepository
+	MockLeaderLock                  MockLeaderLock
 }
 
 func NewMockDatabase(hostRepo MockHostRepository, hostStatusRepo MockHostStatusRepository, hostSgxRepo MockHostSgxDataRepository) repository.SHVSDatabase {
-	return &MockDatabase{
+	db := &MockDatabase{
 		MockHostRepository:        hostRepo,
 		MockHostStatusRepository:  hostStatusRepo,
 		MockHostSgxDataRepository: hostSgxRepo,
 	}
+	db.MockHostRepository.statuses = &db.MockHostStatusRepository
+	return db
 }
 
 func (m *MockDatabase) Migrate() error {
 	return nil
 }
 
+func (m *MockDatabase) WithContext(ctx context.Context) repository.SHVSDatabase {
+	return m
+}
+
 func (m *MockDatabase) HostRepository() repository.HostRepository {
 	return &m.MockHostRepository
 }
@@ -38,6 +53,30 @@ func (m *MockDatabase) HostSgxDataRepository() repository.HostSgxDataRepository
 	return &m.MockHostSgxDataRepository
 }
 
+func (m *MockDatabase) ComplianceReportRepository() repository.ComplianceReportRepository {
+	return &m.MockComplianceReportRepository
+}
+
+func (m *MockDatabase) PolicyRepository() repository.PolicyRepository {
+	return &m.MockPolicyRepository
+}
+
+func (m *MockDatabase) HostPolicyVerdictRepository() repository.HostPolicyVerdictRepository {
+	return &m.MockHostPolicyVerdictRepository
+}
+
+func (m *MockDatabase) JobRepository() repository.JobRepository {
+	return &m.MockJobRepository
+}
+
+func (m *MockDatabase) AuditEventRepository() repository.AuditEventRepository {
+	return &m.MockAuditEventRepository
+}
+
+func (m *MockDatabase) LeaderLock() repository.LeaderLock {
+	return &m.MockLeaderLock
+}
+
 func (m *MockDatabase) Close() {
 
 }
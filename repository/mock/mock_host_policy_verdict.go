@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"errors"
+	"intel/isecl/shvs/v5/types"
+)
+
+type MockHostPolicyVerdictRepository struct {
+	Verdicts []types.HostPolicyVerdict
+}
+
+func (m *MockHostPolicyVerdictRepository) Create(v *types.HostPolicyVerdict) (*types.HostPolicyVerdict, error) {
+	m.Verdicts = append(m.Verdicts, *v)
+	return v, nil
+}
+
+func (m *MockHostPolicyVerdictRepository) Retrieve(v *types.HostPolicyVerdict) (*types.HostPolicyVerdict, error) {
+	for _, thisVerdict := range m.Verdicts {
+		if thisVerdict.HostID == v.HostID {
+			return &thisVerdict, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *MockHostPolicyVerdictRepository) Update(v *types.HostPolicyVerdict) error {
+	for i, thisVerdict := range m.Verdicts {
+		if thisVerdict.ID == v.ID {
+			m.Verdicts[i] = *v
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
@@ -14,6 +14,8 @@ import (
 
 type MockHostRepository struct {
 	Host []types.Host
+	// statuses gives the status of the hosts of the inventory, when set by NewMockDatabase
+	statuses *MockHostStatusRepository
 }
 
 func (m *MockHostRepository) Create(h *types.Host) (*types.Host, error) {
@@ -22,6 +24,7 @@ func (m *MockHostRepository) Create(h *types.Host) (*types.Host, error) {
 		Name:         h.Name,
 		Description:  h.Description,
 		HardwareUUID: h.HardwareUUID,
+		Labels:       h.Labels,
 		CreatedTime:  h.CreatedTime,
 		UpdatedTime:  h.UpdatedTime,
 		Deleted:      h.Deleted,
@@ -109,6 +112,48 @@ func (m *MockHostRepository) GetHostQuery(queryData *types.Host, criteria *types
 	return hosts, nil
 }
 
+func (m *MockHostRepository) StreamHostQuery(queryData *types.Host, criteria *types.HostInfoFetchCriteria, fn func(*types.HostInfo) error) error {
+	hosts, err := m.GetHostQuery(queryData, criteria)
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if err = fn(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockHostRepository) StreamHostInventory(queryData *types.Host, criteria *types.HostInfoFetchCriteria, fn func(*types.HostInventory) error) error {
+	for _, thisHost := range m.Host {
+		if thisHost.Deleted || (queryData.Name != "" && thisHost.Name != queryData.Name) ||
+			(queryData.HardwareUUID != uuid.Nil && thisHost.HardwareUUID != queryData.HardwareUUID) {
+			continue
+		}
+		inventory := types.HostInventory{
+			HostID:       thisHost.ID,
+			HostName:     thisHost.Name,
+			HardwareUUID: thisHost.HardwareUUID,
+			Labels:       thisHost.Labels,
+			LastSeenTime: thisHost.UpdatedTime,
+		}
+		if m.statuses != nil {
+			for _, hostStatus := range m.statuses.HostStatusRepo {
+				if hostStatus.HostID == thisHost.ID {
+					status, updatedTime, expiryTime := hostStatus.Status, hostStatus.UpdatedTime, hostStatus.ExpiryTime
+					inventory.Status, inventory.UpdatedTime, inventory.ExpiryTime = &status, &updatedTime, &expiryTime
+				}
+			}
+		}
+		err := fn(&inventory)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *MockHostRepository) Update(h *types.Host) error {
 
 	return nil
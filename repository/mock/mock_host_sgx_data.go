@@ -39,15 +39,24 @@ func (m *MockHostSgxDataRepository) Retrieve(h *types.HostSgxData) (*types.HostS
 	return nil, errors.New("no records found")
 }
 
-func (m *MockHostSgxDataRepository) RetrieveAll(h *types.HostSgxData) (*types.HostsSgxData, error) {
+func (m *MockHostSgxDataRepository) RetrieveAll(h *types.HostSgxData, statuses []string) (*types.HostsSgxData, error) {
 
 	return &m.HostSGXData, nil
 }
 
-func (m *MockHostSgxDataRepository) GetPlatformData(timeIntervalFilter time.Time) (*types.HostsSgxData, error) {
+func (m *MockHostSgxDataRepository) GetPlatformData(timeIntervalFilter time.Time, statuses []string) (*types.HostsSgxData, error) {
 	return &m.HostSGXData, nil
 }
 
+func (m *MockHostSgxDataRepository) StreamPlatformData(timeIntervalFilter time.Time, statuses []string, fn func(*types.PlatformData) error) error {
+	for _, platformData := range m.HostSGXData {
+		if err := fn(&types.PlatformData{HostSgxData: platformData}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *MockHostSgxDataRepository) Update(h *types.HostSgxData) error {
 	return nil
 }
@@ -7,7 +7,9 @@ package mock
 import (
 	"errors"
 	"intel/isecl/lib/common/v5/validation"
+	"intel/isecl/shvs/v5/constants"
 	"intel/isecl/shvs/v5/types"
+	"time"
 
 	"github.com/google/uuid"
 	commErr "github.com/intel-secl/intel-secl/v5/pkg/lib/common/err"
@@ -67,8 +69,48 @@ func (m *MockHostStatusRepository) RetrieveNonExpiredHost(h *types.HostStatus) (
 	return nil, nil
 }
 
-func (m *MockHostStatusRepository) RetrieveExpiredHosts() (types.HostStatuses, error) {
-	return nil, nil
+func (m *MockHostStatusRepository) RetrieveInStatus(h *types.HostStatus, statuses []string) (*types.HostStatus, error) {
+	for _, thisHost := range m.HostStatusRepo {
+		if (h.ID == uuid.Nil || thisHost.ID != h.ID) && (h.HostID == uuid.Nil || thisHost.HostID != h.HostID) {
+			continue
+		}
+		for _, status := range statuses {
+			if thisHost.Status == status {
+				return &thisHost, nil
+			}
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *MockHostStatusRepository) ExtendExpiry(hostID uuid.UUID, expiryTime time.Time, statuses []string) (bool, error) {
+	for i, thisHostStatus := range m.HostStatusRepo {
+		if thisHostStatus.HostID != hostID {
+			continue
+		}
+		for _, status := range statuses {
+			if thisHostStatus.Status == status {
+				m.HostStatusRepo[i].Status = constants.HostStatusConnected
+				m.HostStatusRepo[i].ExpiryTime = expiryTime
+				m.HostStatusRepo[i].UpdatedTime = time.Now()
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (m *MockHostStatusRepository) ExpireHosts(fromStatus, toStatus string, grace time.Duration) ([]uuid.UUID, error) {
+	var hostIDs []uuid.UUID
+	now := time.Now()
+	for i, thisHostStatus := range m.HostStatusRepo {
+		if thisHostStatus.Status == fromStatus && thisHostStatus.ExpiryTime.Before(now.Add(-grace)) {
+			m.HostStatusRepo[i].Status = toStatus
+			m.HostStatusRepo[i].UpdatedTime = now
+			hostIDs = append(hostIDs, thisHostStatus.HostID)
+		}
+	}
+	return hostIDs, nil
 }
 
 func (m *MockHostStatusRepository) Update(h *types.HostStatus) error {
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"errors"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/types"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MockJobRepository struct {
+	Jobs  []types.Job
+	mutex sync.Mutex
+}
+
+func (m *MockJobRepository) Create(j *types.Job) (*types.Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.Jobs = append(m.Jobs, *j)
+	return j, nil
+}
+
+func (m *MockJobRepository) CreateUnlessQueued(j *types.Job) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, thisJob := range m.Jobs {
+		if thisJob.Type == j.Type && thisJob.Status == constants.JobStatusQueued {
+			return false, nil
+		}
+	}
+	m.Jobs = append(m.Jobs, *j)
+	return true, nil
+}
+
+func (m *MockJobRepository) Retrieve(j *types.Job) (*types.Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, thisJob := range m.Jobs {
+		if (j.ID == uuid.Nil || thisJob.ID == j.ID) && (j.Type == "" || thisJob.Type == j.Type) &&
+			(j.Status == "" || thisJob.Status == j.Status) {
+			return &thisJob, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *MockJobRepository) RetrieveAll(j *types.Job, limit int) (types.Jobs, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var jobs types.Jobs
+	for i := len(m.Jobs) - 1; i >= 0 && len(jobs) < limit; i-- {
+		thisJob := m.Jobs[i]
+		if (j.Type == "" || thisJob.Type == j.Type) && (j.Status == "" || thisJob.Status == j.Status) {
+			jobs = append(jobs, thisJob)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *MockJobRepository) Update(j *types.Job) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, thisJob := range m.Jobs {
+		if thisJob.ID == j.ID {
+			m.Jobs[i] = *j
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
+
+func (m *MockJobRepository) CompareAndSwap(old, updated *types.Job) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, thisJob := range m.Jobs {
+		if thisJob.ID == old.ID {
+			if thisJob.Status != old.Status || thisJob.Owner != old.Owner || thisJob.Attempts != old.Attempts {
+				return false, nil
+			}
+			m.Jobs[i] = *updated
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockJobRepository) Claim(owner string, lease time.Duration) (*types.Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	for i, thisJob := range m.Jobs {
+		if (thisJob.Status == constants.JobStatusQueued || thisJob.Status == constants.JobStatusProcessing) &&
+			!thisJob.NextRunTime.After(now) {
+			thisJob.Status = constants.JobStatusProcessing
+			thisJob.Attempts++
+			thisJob.Owner = owner
+			thisJob.NextRunTime = now.Add(lease)
+			thisJob.StartTime = &now
+			thisJob.EndTime = nil
+			thisJob.UpdatedTime = now
+			m.Jobs[i] = thisJob
+			return &thisJob, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockJobRepository) DeleteFinishedBefore(updatedBefore time.Time) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var deleted int64
+	jobs := m.Jobs[:0]
+	for _, thisJob := range m.Jobs {
+		finished := thisJob.Status == constants.JobStatusCompleted || thisJob.Status == constants.JobStatusError ||
+			thisJob.Status == constants.JobStatusCancelled
+		if finished && thisJob.UpdatedTime.Before(updatedBefore) {
+			deleted++
+			continue
+		}
+		jobs = append(jobs, thisJob)
+	}
+	m.Jobs = jobs
+	return deleted, nil
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"context"
+	"sync"
+)
+
+// MockAdvisoryLock stands in for the database lock shared by the SHVS instances of a test
+type MockAdvisoryLock struct {
+	mutex  sync.Mutex
+	holder *MockLeaderLock
+}
+
+// MockLeaderLock is the handle of one SHVS instance to Lock. Without a Lock the instance is always
+// the leader.
+type MockLeaderLock struct {
+	Lock *MockAdvisoryLock
+}
+
+func (m *MockLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	if m.Lock == nil {
+		return true, nil
+	}
+	m.Lock.mutex.Lock()
+	defer m.Lock.mutex.Unlock()
+
+	if m.Lock.holder == nil {
+		m.Lock.holder = m
+	}
+	return m.Lock.holder == m, nil
+}
+
+func (m *MockLeaderLock) Release() error {
+	if m.Lock == nil {
+		return nil
+	}
+	m.Lock.mutex.Lock()
+	defer m.Lock.mutex.Unlock()
+
+	if m.Lock.holder == m {
+		m.Lock.holder = nil
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"errors"
+	"intel/isecl/shvs/v5/types"
+
+	"github.com/google/uuid"
+)
+
+type MockPolicyRepository struct {
+	Policies []types.Policy
+}
+
+func (m *MockPolicyRepository) Create(p *types.Policy) (*types.Policy, error) {
+	for _, thisPolicy := range m.Policies {
+		if thisPolicy.Name == p.Name {
+			return nil, errors.New("duplicate policy name")
+		}
+	}
+	m.Policies = append(m.Policies, *p)
+	return p, nil
+}
+
+func (m *MockPolicyRepository) Retrieve(p *types.Policy) (*types.Policy, error) {
+	for _, thisPolicy := range m.Policies {
+		if (p.ID == uuid.Nil || thisPolicy.ID == p.ID) && (p.Name == "" || thisPolicy.Name == p.Name) {
+			return &thisPolicy, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *MockPolicyRepository) RetrieveAll() (types.Policies, error) {
+	return m.Policies, nil
+}
+
+func (m *MockPolicyRepository) Update(p *types.Policy) error {
+	for i, thisPolicy := range m.Policies {
+		if thisPolicy.ID == p.ID {
+			m.Policies[i] = *p
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
+
+func (m *MockPolicyRepository) Delete(p *types.Policy) error {
+	for i, thisPolicy := range m.Policies {
+		if thisPolicy.ID == p.ID {
+			m.Policies = append(m.Policies[:i], m.Policies[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
@@ -0,0 +1,17 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package repository
+
+import (
+	"intel/isecl/shvs/v5/types"
+)
+
+type PolicyRepository interface {
+	Create(*types.Policy) (*types.Policy, error)
+	Retrieve(*types.Policy) (*types.Policy, error)
+	RetrieveAll() (types.Policies, error)
+	Update(*types.Policy) error
+	Delete(*types.Policy) error
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/types"
+)
+
+// The audit_events table is append-only, updates and deletes are discarded by these rules
+const (
+	auditEventsNoUpdateRule = "CREATE OR REPLACE RULE audit_events_no_update AS ON UPDATE TO audit_events DO INSTEAD NOTHING"
+	auditEventsNoDeleteRule = "CREATE OR REPLACE RULE audit_events_no_delete AS ON DELETE TO audit_events DO INSTEAD NOTHING"
+)
+
+// advanceAuditLogHead keeps the latest head of a log file when the heads of concurrent appends are stored
+// out of order
+const advanceAuditLogHead = `INSERT INTO audit_log_heads (log_id, sequence, hash, updated_time) VALUES (?, ?, ?, ?)
+	ON CONFLICT (log_id) DO UPDATE SET sequence = EXCLUDED.sequence, hash = EXCLUDED.hash, updated_time = EXCLUDED.updated_time
+	WHERE audit_log_heads.sequence < EXCLUDED.sequence`
+
+type PostgresAuditEventRepository struct {
+	db  *gorm.DB
+	log *logrus.Entry
+}
+
+func (r *PostgresAuditEventRepository) Create(e *types.AuditEvent) (*types.AuditEvent, error) {
+	r.log.Trace("repository/postgres/pg_audit_event: Create() Entering")
+	defer r.log.Trace("repository/postgres/pg_audit_event: Create() Leaving")
+
+	err := r.db.Create(e).Error
+	return e, errors.Wrap(err, "Create(): failed to create AuditEvent")
+}
+
+func (r *PostgresAuditEventRepository) RetrieveAll(filter *types.AuditEventFilter) (types.AuditEvents, error) {
+	r.log.Trace("repository/postgres/pg_audit_event: RetrieveAll() Entering")
+	defer r.log.Trace("repository/postgres/pg_audit_event: RetrieveAll() Leaving")
+
+	var events types.AuditEvents
+	tx := r.db.Order("time desc")
+	if filter.Action != "" {
+		tx = tx.Where("action = ?", filter.Action)
+	}
+	if filter.Subject != "" {
+		tx = tx.Where("subject = ?", filter.Subject)
+	}
+	if filter.Target != "" {
+		tx = tx.Where("target = ?", filter.Target)
+	}
+	if filter.Result != "" {
+		tx = tx.Where("result = ?", filter.Result)
+	}
+	if !filter.FromTime.IsZero() {
+		tx = tx.Where("time >= ?", filter.FromTime)
+	}
+	if !filter.ToTime.IsZero() {
+		tx = tx.Where("time <= ?", filter.ToTime)
+	}
+	if filter.Limit > 0 {
+		tx = tx.Limit(filter.Limit)
+	}
+	err := tx.Find(&events).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "RetrieveAll(): failed to RetrieveAll AuditEvents")
+	}
+	return events, nil
+}
+
+func (r *PostgresAuditEventRepository) RetrieveHead(logID string) (*types.AuditLogHead, error) {
+	r.log.Trace("repository/postgres/pg_audit_event: RetrieveHead() Entering")
+	defer r.log.Trace("repository/postgres/pg_audit_event: RetrieveHead() Leaving")
+
+	var head types.AuditLogHead
+	err := r.db.Where("log_id = ?", logID).First(&head).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "RetrieveHead(): failed to retrieve AuditLogHead")
+	}
+	return &head, nil
+}
+
+func (r *PostgresAuditEventRepository) AdvanceHead(head *types.AuditLogHead) error {
+	r.log.Trace("repository/postgres/pg_audit_event: AdvanceHead() Entering")
+	defer r.log.Trace("repository/postgres/pg_audit_event: AdvanceHead() Leaving")
+
+	err := r.db.Exec(advanceAuditLogHead, head.LogID, head.Sequence, head.Hash, head.UpdatedTime).Error
+	return errors.Wrap(err, "AdvanceHead(): failed to store AuditLogHead")
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/types"
+	"time"
+)
+
+type PostgresComplianceReportRepository struct {
+	db  *gorm.DB
+	log *logrus.Entry
+}
+
+func (r *PostgresComplianceReportRepository) Create(cr *types.ComplianceReport) (*types.ComplianceReport, error) {
+	r.log.Trace("repository/postgres/pg_compliance_report: Create() Entering")
+	defer r.log.Trace("repository/postgres/pg_compliance_report: Create() Leaving")
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(cr).Error; err != nil {
+			return err
+		}
+		for i := range cr.NonCompliantHosts {
+			cr.NonCompliantHosts[i].ID = uuid.New()
+			cr.NonCompliantHosts[i].ReportID = cr.ID
+			if err := tx.Create(&cr.NonCompliantHosts[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return cr, errors.Wrap(err, "Create(): failed to create ComplianceReport")
+}
+
+func (r *PostgresComplianceReportRepository) Retrieve(cr *types.ComplianceReport) (*types.ComplianceReport, error) {
+	r.log.Trace("repository/postgres/pg_compliance_report: Retrieve() Entering")
+	defer r.log.Trace("repository/postgres/pg_compliance_report: Retrieve() Leaving")
+
+	var report types.ComplianceReport
+	err := r.db.Where("id = (?)", cr.ID).First(&report).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "Retrieve(): failed to Retrieve ComplianceReport")
+	}
+	err = r.db.Where("report_id = (?)", report.ID).Order("host_name").Find(&report.NonCompliantHosts).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "Retrieve(): failed to Retrieve NonCompliantHosts")
+	}
+	return &report, nil
+}
+
+func (r *PostgresComplianceReportRepository) RetrieveAll() (types.ComplianceReports, error) {
+	r.log.Trace("repository/postgres/pg_compliance_report: RetrieveAll() Entering")
+	defer r.log.Trace("repository/postgres/pg_compliance_report: RetrieveAll() Leaving")
+
+	var reports types.ComplianceReports
+	err := r.db.Order("created_time desc").Find(&reports).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "RetrieveAll(): failed to RetrieveAll ComplianceReport")
+	}
+	return reports, nil
+}
+
+func (r *PostgresComplianceReportRepository) RetrieveLatest() (*types.ComplianceReport, error) {
+	r.log.Trace("repository/postgres/pg_compliance_report: RetrieveLatest() Entering")
+	defer r.log.Trace("repository/postgres/pg_compliance_report: RetrieveLatest() Leaving")
+
+	var report types.ComplianceReport
+	err := r.db.Order("created_time desc").First(&report).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "RetrieveLatest(): failed to Retrieve latest ComplianceReport")
+	}
+	return &report, nil
+}
+
+func (r *PostgresComplianceReportRepository) DeleteOlderThan(createdBefore time.Time) (int64, error) {
+	r.log.Trace("repository/postgres/pg_compliance_report: DeleteOlderThan() Entering")
+	defer r.log.Trace("repository/postgres/pg_compliance_report: DeleteOlderThan() Leaving")
+
+	tx := r.db.Where("created_time < (?)", createdBefore).Delete(&types.ComplianceReport{})
+	if tx.Error != nil {
+		return 0, errors.Wrap(tx.Error, "DeleteOlderThan(): failed to delete ComplianceReport")
+	}
+	return tx.RowsAffected, nil
+}
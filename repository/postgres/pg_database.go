@@ -5,16 +5,21 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 	commLog "intel/isecl/lib/common/v5/log"
 	commLogMsg "intel/isecl/lib/common/v5/log/message"
 	"intel/isecl/shvs/v5/constants"
 	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/requestid"
+	"intel/isecl/shvs/v5/tracing"
 	"intel/isecl/shvs/v5/types"
 	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 var log = commLog.GetDefaultLogger()
@@ -22,6 +27,10 @@ var slog = commLog.GetSecurityLogger()
 
 type PostgresDatabase struct {
 	DB *gorm.DB
+	// log is the logger of the repositories, carrying the request ID of a database returned by WithContext
+	log *logrus.Entry
+	// slog is the security logger of the repositories
+	slog *logrus.Entry
 }
 
 func (pd *PostgresDatabase) Migrate() error {
@@ -31,19 +40,76 @@ func (pd *PostgresDatabase) Migrate() error {
 	pd.DB.AutoMigrate(types.Host{})
 	pd.DB.AutoMigrate(types.HostStatus{}).AddForeignKey("host_id", "hosts(id)", "RESTRICT", "RESTRICT")
 	pd.DB.AutoMigrate(types.HostSgxData{}).AddForeignKey("host_id", "hosts(id)", "RESTRICT", "RESTRICT")
+	pd.DB.AutoMigrate(types.ComplianceReport{})
+	pd.DB.AutoMigrate(types.NonCompliantHost{}).AddForeignKey("report_id", "compliance_reports(id)", "CASCADE", "RESTRICT")
+	pd.DB.AutoMigrate(types.Policy{})
+	pd.DB.AutoMigrate(types.HostPolicyVerdict{}).AddForeignKey("host_id", "hosts(id)", "RESTRICT", "RESTRICT")
+	pd.DB.AutoMigrate(types.Job{})
+	pd.DB.AutoMigrate(types.AuditEvent{})
+	pd.DB.AutoMigrate(types.AuditLogHead{})
+	// without these rules the audit events could be changed, the migration fails rather than run unprotected
+	for _, rule := range []string{auditEventsNoUpdateRule, auditEventsNoDeleteRule} {
+		if err := pd.DB.Exec(rule).Error; err != nil {
+			return errors.Wrap(err, "Migrate(): failed to protect the audit events")
+		}
+	}
 	return nil
 }
 
+func (pd *PostgresDatabase) WithContext(ctx context.Context) repository.SHVSDatabase {
+	// the queries are traced as children of the span of the request
+	return &PostgresDatabase{DB: tracing.WithContext(pd.DB, ctx), log: requestid.Entry(ctx, log), slog: requestid.Entry(ctx, slog)}
+}
+
+func (pd *PostgresDatabase) loggers() (*logrus.Entry, *logrus.Entry) {
+	if pd.log == nil || pd.slog == nil {
+		return log, slog
+	}
+	return pd.log, pd.slog
+}
+
 func (pd *PostgresDatabase) HostRepository() repository.HostRepository {
-	return &PostgresHostRepository{db: pd.DB}
+	log, _ := pd.loggers()
+	return &PostgresHostRepository{db: pd.DB, log: log}
 }
 
 func (pd *PostgresDatabase) HostStatusRepository() repository.HostStatusRepository {
-	return &PostgresHostStatusRepository{db: pd.DB}
+	log, slog := pd.loggers()
+	return &PostgresHostStatusRepository{db: pd.DB, log: log, slog: slog}
 }
 
 func (pd *PostgresDatabase) HostSgxDataRepository() repository.HostSgxDataRepository {
-	return &PostgresHostSgxDataRepository{db: pd.DB}
+	log, slog := pd.loggers()
+	return &PostgresHostSgxDataRepository{db: pd.DB, log: log, slog: slog}
+}
+
+func (pd *PostgresDatabase) ComplianceReportRepository() repository.ComplianceReportRepository {
+	log, _ := pd.loggers()
+	return &PostgresComplianceReportRepository{db: pd.DB, log: log}
+}
+
+func (pd *PostgresDatabase) PolicyRepository() repository.PolicyRepository {
+	log, _ := pd.loggers()
+	return &PostgresPolicyRepository{db: pd.DB, log: log}
+}
+
+func (pd *PostgresDatabase) HostPolicyVerdictRepository() repository.HostPolicyVerdictRepository {
+	log, _ := pd.loggers()
+	return &PostgresHostPolicyVerdictRepository{db: pd.DB, log: log}
+}
+
+func (pd *PostgresDatabase) JobRepository() repository.JobRepository {
+	log, _ := pd.loggers()
+	return &PostgresJobRepository{db: pd.DB, log: log}
+}
+
+func (pd *PostgresDatabase) AuditEventRepository() repository.AuditEventRepository {
+	log, _ := pd.loggers()
+	return &PostgresAuditEventRepository{db: pd.DB, log: log}
+}
+
+func (pd *PostgresDatabase) LeaderLock() repository.LeaderLock {
+	return &PostgresLeaderLock{db: pd.DB.DB()}
 }
 
 func (pd *PostgresDatabase) Close() {
@@ -113,6 +179,7 @@ func Open(host string, port int, dbname, user, password, sslMode, sslCert string
 	}
 
 	setConnectionPool(db)
+	tracing.InstrumentGorm(db)
 
 	return &PostgresDatabase{DB: db}, nil
 }
@@ -9,30 +9,34 @@ import (
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"intel/isecl/shvs/v5/types"
 )
 
 type PostgresHostRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	log *logrus.Entry
 }
 
 func (r *PostgresHostRepository) Create(h *types.Host) (*types.Host, error) {
-	log.Trace("repository/postgres/pg_host: Create() Entering")
-	defer log.Trace("repository/postgres/pg_host: Create() Leaving")
+	r.log.Trace("repository/postgres/pg_host: Create() Entering")
+	defer r.log.Trace("repository/postgres/pg_host: Create() Leaving")
 
 	err := r.db.Create(h).Error
 	return h, errors.Wrap(err, "Create: failed to create Host")
 }
 
 const (
-	hostsFields   = "hosts.id, hosts.name, hosts.hardware_uuid"
+	hostsFields   = "hosts.id, hosts.name, hosts.hardware_uuid, hosts.labels"
 	sgxDataFields = "host_sgx_data.sgx_supported, host_sgx_data.sgx_enabled, host_sgx_data.flc_enabled," +
 		"host_sgx_data.epc_size, host_sgx_data.tcb_uptodate"
+	inventoryFields = hostsFields + ", host_statuses.status, " + sgxDataFields +
+		", host_statuses.updated_time, host_statuses.expiry_time, hosts.updated_time"
 )
 
 func (r *PostgresHostRepository) Retrieve(h *types.Host, criteria *types.HostInfoFetchCriteria) (*types.HostInfo, error) {
-	log.Trace("repository/postgres/pg_host: Retrieve() Entering")
-	defer log.Trace("repository/postgres/pg_host: Retrieve() Leaving")
+	r.log.Trace("repository/postgres/pg_host: Retrieve() Entering")
+	defer r.log.Trace("repository/postgres/pg_host: Retrieve() Leaving")
 
 	var host types.HostInfo
 
@@ -45,16 +49,16 @@ func (r *PostgresHostRepository) Retrieve(h *types.Host, criteria *types.HostInf
 	if criteria != nil && (criteria.GetPlatformData || criteria.GetStatus) {
 		row = buildHostInfoFetchQuery(tx, criteria).Where(&h).Where("deleted='f'").Row()
 		if criteria.GetPlatformData && criteria.GetStatus {
-			err = row.Scan(&host.ID, &host.Name, &host.HardwareUUID, &sgx.Supported,
+			err = row.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels, &sgx.Supported,
 				&sgx.Enabled, &meta.FlcEnabled, &meta.EpcSize, &meta.TcbUpToDate, &host.Status)
 		} else if criteria.GetPlatformData {
-			err = row.Scan(&host.ID, &host.Name, &host.HardwareUUID, &sgx.Supported,
+			err = row.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels, &sgx.Supported,
 				&sgx.Enabled, &meta.FlcEnabled, &meta.EpcSize, &meta.TcbUpToDate)
 		} else if criteria.GetStatus {
-			err = row.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Status)
+			err = row.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels, &host.Status)
 		}
 	} else {
-		err = tx.Select(hostsFields).Where(&h).Where("deleted='f'").Row().Scan(&host.ID, &host.Name, &host.HardwareUUID)
+		err = tx.Select(hostsFields).Where(&h).Where("deleted='f'").Row().Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels)
 	}
 	if err != nil {
 		return nil, errors.Wrap(err, "Retrieve: failed to Retrieve Host")
@@ -70,8 +74,8 @@ func (r *PostgresHostRepository) Retrieve(h *types.Host, criteria *types.HostInf
 }
 
 func (r *PostgresHostRepository) RetrieveAnyIfExists(h *types.Host) (*types.Host, error) {
-	log.Trace("repository/postgres/pg_host: RetrieveAnyIfExists() Entering")
-	defer log.Trace("repository/postgres/pg_host: RetrieveAnyIfExists() Leaving")
+	r.log.Trace("repository/postgres/pg_host: RetrieveAnyIfExists() Entering")
+	defer r.log.Trace("repository/postgres/pg_host: RetrieveAnyIfExists() Leaving")
 
 	err := r.db.Where(h).First(h).Error
 	if err != nil {
@@ -81,28 +85,18 @@ func (r *PostgresHostRepository) RetrieveAnyIfExists(h *types.Host) (*types.Host
 }
 
 func (r *PostgresHostRepository) GetHostQuery(queryData *types.Host, criteria *types.HostInfoFetchCriteria) ([]*types.HostInfo, error) {
-	log.Trace("repository/postgres/pg_host: GetHostQuery() Entering")
-	defer log.Trace("repository/postgres/pg_host: GetHostQuery() Leaving")
+	r.log.Trace("repository/postgres/pg_host: GetHostQuery() Entering")
+	defer r.log.Trace("repository/postgres/pg_host: GetHostQuery() Leaving")
 
 	hrs := []*types.HostInfo{}
-	tx := buildHostSearchQuery(r.db, queryData)
-	if tx == nil {
-		return hrs, errors.New("Unexpected Error. Could not build a gorm query object in Hosts GetHostQuery function.")
-	}
-	if criteria != nil && (criteria.GetPlatformData || criteria.GetStatus) {
-		tx = buildHostInfoFetchQuery(tx, criteria)
-	} else {
-		tx = tx.Select(hostsFields)
-	}
-
-	rows, err := tx.Rows()
+	rows, err := r.hostQueryRows(queryData, criteria)
 	if err != nil {
 		return nil, errors.Wrap(err, "GetHostQuery: failed to retrieve records from db")
 	}
 	defer func() {
 		derr := rows.Close()
 		if derr != nil {
-			log.WithError(derr).Error("Error closing rows")
+			r.log.WithError(derr).Error("Error closing rows")
 		}
 	}()
 
@@ -112,7 +106,7 @@ func (r *PostgresHostRepository) GetHostQuery(queryData *types.Host, criteria *t
 		for rows.Next() {
 			host := types.HostInfo{}
 
-			err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID)
+			err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels)
 			if err != nil {
 				return nil, errors.Wrap(err, "GetHostQuery: failed to scan row from db")
 			}
@@ -122,6 +116,88 @@ func (r *PostgresHostRepository) GetHostQuery(queryData *types.Host, criteria *t
 	return hrs, nil
 }
 
+// StreamHostQuery runs the same query as GetHostQuery but hands each row to fn as soon as it
+// is scanned, so that callers can write very large result sets without buffering them
+func (r *PostgresHostRepository) StreamHostQuery(queryData *types.Host, criteria *types.HostInfoFetchCriteria, fn func(*types.HostInfo) error) error {
+	r.log.Trace("repository/postgres/pg_host: StreamHostQuery() Entering")
+	defer r.log.Trace("repository/postgres/pg_host: StreamHostQuery() Leaving")
+
+	rows, err := r.hostQueryRows(queryData, criteria)
+	if err != nil {
+		return errors.Wrap(err, "StreamHostQuery: failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			r.log.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	if criteria == nil {
+		criteria = &types.HostInfoFetchCriteria{}
+	}
+	for rows.Next() {
+		host, err := scanHostInfo(criteria, rows)
+		if err != nil {
+			return errors.Wrap(err, "StreamHostQuery: failed to scan row from db")
+		}
+		if err = fn(host); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(rows.Err(), "StreamHostQuery: failed to iterate rows from db")
+}
+
+func (r *PostgresHostRepository) StreamHostInventory(queryData *types.Host, criteria *types.HostInfoFetchCriteria, fn func(*types.HostInventory) error) error {
+	r.log.Trace("repository/postgres/pg_host: StreamHostInventory() Entering")
+	defer r.log.Trace("repository/postgres/pg_host: StreamHostInventory() Leaving")
+
+	tx := buildHostSearchQuery(r.db, queryData)
+	if tx == nil {
+		return errors.New("Unexpected Error. Could not build a gorm query object in Hosts StreamHostInventory function.")
+	}
+	rows, err := buildHostComplianceQuery(tx, criteria).Select(inventoryFields).
+		Joins("left join host_sgx_data on host_sgx_data.host_id = hosts.id").
+		Joins("left join host_statuses on host_statuses.host_id = hosts.id").
+		Order("hosts.name").Rows()
+	if err != nil {
+		return errors.Wrap(err, "StreamHostInventory: failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			r.log.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	for rows.Next() {
+		var hi types.HostInventory
+		err = rows.Scan(&hi.HostID, &hi.HostName, &hi.HardwareUUID, &hi.Labels, &hi.Status, &hi.SgxSupported, &hi.SgxEnabled,
+			&hi.FlcEnabled, &hi.EpcSize, &hi.TcbUptodate, &hi.UpdatedTime, &hi.ExpiryTime, &hi.LastSeenTime)
+		if err != nil {
+			return errors.Wrap(err, "StreamHostInventory: failed to scan row from db")
+		}
+		if err = fn(&hi); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(rows.Err(), "StreamHostInventory: failed to iterate rows from db")
+}
+
+func (r *PostgresHostRepository) hostQueryRows(queryData *types.Host, criteria *types.HostInfoFetchCriteria) (*sql.Rows, error) {
+	tx := buildHostSearchQuery(r.db, queryData)
+	if tx == nil {
+		return nil, errors.New("Unexpected Error. Could not build a gorm query object in Hosts GetHostQuery function.")
+	}
+	tx = buildHostComplianceQuery(tx, criteria)
+	if criteria != nil && (criteria.GetPlatformData || criteria.GetStatus) {
+		tx = buildHostInfoFetchQuery(tx, criteria)
+	} else {
+		tx = tx.Select(hostsFields)
+	}
+	return tx.Rows()
+}
+
 func buildHostInfoFetchQuery(tx *gorm.DB, criteria *types.HostInfoFetchCriteria) *gorm.DB {
 	log.Trace("repository/postgres/pg_host: buildHostInfoFetchQuery() Entering")
 	defer log.Trace("repository/postgres/pg_host: buildHostInfoFetchQuery() Leaving")
@@ -143,40 +219,56 @@ func buildHostInfoFetchQuery(tx *gorm.DB, criteria *types.HostInfoFetchCriteria)
 	return tx
 }
 
+// buildHostComplianceQuery restricts the hosts to those whose policy verdict matches criteria.Compliant
+func buildHostComplianceQuery(tx *gorm.DB, criteria *types.HostInfoFetchCriteria) *gorm.DB {
+	if criteria == nil || criteria.Compliant == nil {
+		return tx
+	}
+	return tx.Joins("inner join host_policy_verdicts on host_policy_verdicts.host_id = hosts.id").
+		Where("host_policy_verdicts.compliant = (?)", *criteria.Compliant)
+}
+
 func getAdditionalHostInfo(criteria *types.HostInfoFetchCriteria, rows *sql.Rows) ([]*types.HostInfo, error) {
 
-	var err error
 	hrs := []*types.HostInfo{}
 	for rows.Next() {
-		host := types.HostInfo{}
-		sgx := types.SGX{}
-		meta := types.SGXMeta{}
-
-		if criteria.GetPlatformData && criteria.GetStatus {
-			err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID, &sgx.Supported,
-				&sgx.Enabled, &meta.FlcEnabled, &meta.EpcSize, &meta.TcbUpToDate, &host.Status)
-		} else if criteria.GetPlatformData {
-			err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID, &sgx.Supported,
-				&sgx.Enabled, &meta.FlcEnabled, &meta.EpcSize, &meta.TcbUpToDate)
-		} else if criteria.GetStatus {
-			err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Status)
-		} else {
-			err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID)
-		}
+		host, err := scanHostInfo(criteria, rows)
 		if err != nil {
 			return nil, errors.Wrap(err, "getAdditionalHostInfo: failed to scan row from db")
 		}
+		hrs = append(hrs, host)
+	}
+	return hrs, nil
+}
 
-		if sgx.Supported != nil && *sgx.Supported {
-			host.HardwareFeatures = &types.HardwareFeatures{SGX: &types.SGX{
-				Enabled: sgx.Enabled,
-				Meta:    &meta,
-			}}
-		}
+func scanHostInfo(criteria *types.HostInfoFetchCriteria, rows *sql.Rows) (*types.HostInfo, error) {
+	var err error
+	host := types.HostInfo{}
+	sgx := types.SGX{}
+	meta := types.SGXMeta{}
+
+	if criteria.GetPlatformData && criteria.GetStatus {
+		err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels, &sgx.Supported,
+			&sgx.Enabled, &meta.FlcEnabled, &meta.EpcSize, &meta.TcbUpToDate, &host.Status)
+	} else if criteria.GetPlatformData {
+		err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels, &sgx.Supported,
+			&sgx.Enabled, &meta.FlcEnabled, &meta.EpcSize, &meta.TcbUpToDate)
+	} else if criteria.GetStatus {
+		err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels, &host.Status)
+	} else {
+		err = rows.Scan(&host.ID, &host.Name, &host.HardwareUUID, &host.Labels)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		hrs = append(hrs, &host)
+	if sgx.Supported != nil && *sgx.Supported {
+		host.HardwareFeatures = &types.HardwareFeatures{SGX: &types.SGX{
+			Enabled: sgx.Enabled,
+			Meta:    &meta,
+		}}
 	}
-	return hrs, nil
+	return &host, nil
 }
 
 func buildHostSearchQuery(tx *gorm.DB, rs *types.Host) *gorm.DB {
@@ -200,8 +292,8 @@ func buildHostSearchQuery(tx *gorm.DB, rs *types.Host) *gorm.DB {
 }
 
 func (r *PostgresHostRepository) Update(h *types.Host) error {
-	log.Trace("repository/postgres/pg_host: Update() Entering")
-	defer log.Trace("repository/postgres/pg_host: Update() Leaving")
+	r.log.Trace("repository/postgres/pg_host: Update() Entering")
+	defer r.log.Trace("repository/postgres/pg_host: Update() Leaving")
 
 	if err := r.db.Save(h).Error; err != nil {
 		return errors.Wrap(err, "Update: failed to update Host")
@@ -210,8 +302,8 @@ func (r *PostgresHostRepository) Update(h *types.Host) error {
 }
 
 func (r *PostgresHostRepository) Delete(h *types.Host) error {
-	log.Trace("repository/postgres/pg_host: Delete() Entering")
-	defer log.Trace("repository/postgres/pg_host: Delete() Leaving")
+	r.log.Trace("repository/postgres/pg_host: Delete() Entering")
+	defer r.log.Trace("repository/postgres/pg_host: Delete() Leaving")
 
 	if err := r.db.Delete(h).Error; err != nil {
 		return errors.Wrap(err, "Delete: failed to delete Host")
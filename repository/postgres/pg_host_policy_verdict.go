@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/types"
+)
+
+type PostgresHostPolicyVerdictRepository struct {
+	db  *gorm.DB
+	log *logrus.Entry
+}
+
+func (r *PostgresHostPolicyVerdictRepository) Create(v *types.HostPolicyVerdict) (*types.HostPolicyVerdict, error) {
+	r.log.Trace("repository/postgres/pg_host_policy_verdict: Create() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_policy_verdict: Create() Leaving")
+
+	err := r.db.Create(v).Error
+	return v, errors.Wrap(err, "Create(): failed to create HostPolicyVerdict")
+}
+
+func (r *PostgresHostPolicyVerdictRepository) Retrieve(v *types.HostPolicyVerdict) (*types.HostPolicyVerdict, error) {
+	r.log.Trace("repository/postgres/pg_host_policy_verdict: Retrieve() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_policy_verdict: Retrieve() Leaving")
+
+	var verdict types.HostPolicyVerdict
+	err := r.db.Where(v).First(&verdict).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "Retrieve(): failed to Retrieve HostPolicyVerdict")
+	}
+	return &verdict, nil
+}
+
+func (r *PostgresHostPolicyVerdictRepository) Update(v *types.HostPolicyVerdict) error {
+	r.log.Trace("repository/postgres/pg_host_policy_verdict: Update() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_policy_verdict: Update() Leaving")
+
+	if err := r.db.Save(v).Error; err != nil {
+		return errors.Wrap(err, "Update(): failed to update HostPolicyVerdict")
+	}
+	return nil
+}
@@ -7,74 +7,109 @@ package postgres
 import (
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"intel/isecl/shvs/v5/types"
 	"time"
 )
 
 type PostgresHostSgxDataRepository struct {
-	db *gorm.DB
+	db   *gorm.DB
+	log  *logrus.Entry
+	slog *logrus.Entry
 }
 
 func (r *PostgresHostSgxDataRepository) Create(h *types.HostSgxData) (*types.HostSgxData, error) {
-	log.Trace("repository/postgres/pg_host_sgx_data: Create() Entering")
-	defer log.Trace("repository/postgres/pg_host_sgx_data: Create() Leaving")
+	r.log.Trace("repository/postgres/pg_host_sgx_data: Create() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_sgx_data: Create() Leaving")
 
 	err := r.db.Create(h).Error
 	return h, errors.Wrap(err, "Create(): failed to create HostSgxData")
 }
 
 func (r *PostgresHostSgxDataRepository) Retrieve(h *types.HostSgxData) (*types.HostSgxData, error) {
-	log.Trace("repository/postgres/pg_host_sgx_data: Retrieve() Entering")
-	defer log.Trace("repository/postgres/pg_host_sgx_data: Retrieve() Leaving")
+	r.log.Trace("repository/postgres/pg_host_sgx_data: Retrieve() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_sgx_data: Retrieve() Leaving")
 
 	var p types.HostSgxData
-	slog.WithField("HostSgxData", h).Debug("Retrieve Call")
+	r.slog.WithField("HostSgxData", h).Debug("Retrieve Call")
 	err := r.db.Where(h).First(&p).Error
 	if err != nil {
-		log.Trace("Error in fetch records Entering")
+		r.log.Trace("Error in fetch records Entering")
 		return nil, errors.Wrap(err, "Retrieve(): failed to Retrieve HostSgxData")
 	}
 	return &p, nil
 }
 
-func (r *PostgresHostSgxDataRepository) RetrieveAll(h *types.HostSgxData) (*types.HostsSgxData, error) {
-	log.Trace("repository/postgres/pg_host_sgx_data: RetrieveAll() Entering")
-	defer log.Trace("repository/postgres/pg_host_sgx_data: RetrieveAll() Leaving")
+func (r *PostgresHostSgxDataRepository) RetrieveAll(h *types.HostSgxData, statuses []string) (*types.HostsSgxData, error) {
+	r.log.Trace("repository/postgres/pg_host_sgx_data: RetrieveAll() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_sgx_data: RetrieveAll() Leaving")
 
 	var hs types.HostsSgxData
 	cols := "host_sgx_data.host_id, host_sgx_data.sgx_supported, host_sgx_data.sgx_enabled, host_sgx_data.flc_enabled, host_sgx_data.epc_size, host_sgx_data.tcb_uptodate"
 
-	tx := r.db.Joins("INNER JOIN host_statuses on host_statuses.host_id = host_sgx_data.host_id").Where("status = 'CONNECTED' and host_statuses.host_id = (?)", h.HostID)
+	tx := r.db.Joins("INNER JOIN host_statuses on host_statuses.host_id = host_sgx_data.host_id").Where("status in (?) and host_statuses.host_id = (?)", statuses, h.HostID)
 	tx = tx.Select(cols)
 	err := tx.Find(&hs).Error
 	if err != nil {
 		return nil, errors.Wrap(err, "RetrieveAll(): failed to RetrieveAll HostSgxData")
 	}
-	slog.WithField("db hs", hs).Trace("RetrieveAll")
+	r.slog.WithField("db hs", hs).Trace("RetrieveAll")
 	return &hs, errors.Wrap(err, "RetrieveAll(): failed to RetrieveAll HostSgxData")
 }
 
-func (r *PostgresHostSgxDataRepository) GetPlatformData(timeIntervalFilter time.Time) (*types.HostsSgxData, error) {
-	log.Trace("repository/postgres/pg_host_sgx_data: GetPlatformData() Entering")
-	defer log.Trace("repository/postgres/pg_host_sgx_data: GetPlatformData() Leaving")
+func (r *PostgresHostSgxDataRepository) GetPlatformData(timeIntervalFilter time.Time, statuses []string) (*types.HostsSgxData, error) {
+	r.log.Trace("repository/postgres/pg_host_sgx_data: GetPlatformData() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_sgx_data: GetPlatformData() Leaving")
 
 	var hs types.HostsSgxData
 	cols := "host_sgx_data.host_id, host_sgx_data.sgx_supported, host_sgx_data.sgx_enabled, host_sgx_data.flc_enabled, host_sgx_data.epc_size, host_sgx_data.tcb_uptodate"
 
-	tx := r.db.Joins("INNER JOIN host_statuses on host_statuses.host_id = host_sgx_data.host_id").Where("status = 'CONNECTED' AND updated_time >= (?)", timeIntervalFilter)
+	tx := r.db.Joins("INNER JOIN host_statuses on host_statuses.host_id = host_sgx_data.host_id").Where("status in (?) AND updated_time >= (?)", statuses, timeIntervalFilter)
 	tx = tx.Select(cols)
 	err := tx.Find(&hs).Error
 	if err != nil {
 		return nil, errors.Wrap(err, "GetPlatformData(): failed to RetrieveByHostID HostSgxData")
 	}
 
-	slog.WithField("db hs", hs).Info("getPlatformData")
+	r.slog.WithField("db hs", hs).Info("getPlatformData")
 	return &hs, nil
 }
 
+func (r *PostgresHostSgxDataRepository) StreamPlatformData(timeIntervalFilter time.Time, statuses []string, fn func(*types.PlatformData) error) error {
+	r.log.Trace("repository/postgres/pg_host_sgx_data: StreamPlatformData() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_sgx_data: StreamPlatformData() Leaving")
+
+	cols := "host_sgx_data.host_id, host_sgx_data.sgx_supported, host_sgx_data.sgx_enabled, host_sgx_data.flc_enabled, host_sgx_data.epc_size, host_sgx_data.tcb_uptodate, host_statuses.expiry_time"
+
+	rows, err := r.db.Table("host_sgx_data").Select(cols).
+		Joins("INNER JOIN host_statuses on host_statuses.host_id = host_sgx_data.host_id").
+		Where("host_statuses.status in (?) AND host_statuses.updated_time >= (?)", statuses, timeIntervalFilter).Rows()
+	if err != nil {
+		return errors.Wrap(err, "StreamPlatformData(): failed to retrieve HostSgxData")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			r.log.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	for rows.Next() {
+		var pd types.PlatformData
+		err = rows.Scan(&pd.HostID, &pd.SgxSupported, &pd.SgxEnabled, &pd.FlcEnabled, &pd.EpcSize, &pd.TcbUptodate, &pd.ExpiryTime)
+		if err != nil {
+			return errors.Wrap(err, "StreamPlatformData(): failed to scan row from db")
+		}
+		if err = fn(&pd); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(rows.Err(), "StreamPlatformData(): failed to iterate rows from db")
+}
+
 func (r *PostgresHostSgxDataRepository) Update(h *types.HostSgxData) error {
-	log.Trace("repository/postgres/pg_host_sgx_data: Update() Entering")
-	defer log.Trace("repository/postgres/pg_host_sgx_data: Update() Leaving")
+	r.log.Trace("repository/postgres/pg_host_sgx_data: Update() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_sgx_data: Update() Leaving")
 
 	if err := r.db.Save(h).Error; err != nil {
 		return errors.Wrap(err, "Update(): failed to update HostSgxData")
@@ -83,8 +118,8 @@ func (r *PostgresHostSgxDataRepository) Update(h *types.HostSgxData) error {
 }
 
 func (r *PostgresHostSgxDataRepository) Delete(h *types.HostSgxData) error {
-	log.Trace("repository/postgres/pg_host_sgx_data: Delete() Entering")
-	defer log.Trace("repository/postgres/pg_host_sgx_data: Delete() Leaving")
+	r.log.Trace("repository/postgres/pg_host_sgx_data: Delete() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_sgx_data: Delete() Leaving")
 
 	if err := r.db.Delete(h).Error; err != nil {
 		return errors.Wrap(err, "Delete(): failed to delete HostSgxData")
@@ -5,69 +5,118 @@
 package postgres
 
 import (
+	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/constants"
 	"intel/isecl/shvs/v5/types"
 	"time"
 )
 
+// expireHostsQuery transitions all expired hosts in one statement, so that concurrent sweeps never
+// transition a host twice
+const expireHostsQuery = `UPDATE host_statuses SET status = ?, updated_time = now()
+	WHERE expiry_time < now() - ? * interval '1 second' AND status = ? RETURNING host_id`
+
 type PostgresHostStatusRepository struct {
-	db *gorm.DB
+	db   *gorm.DB
+	log  *logrus.Entry
+	slog *logrus.Entry
 }
 
 func (r *PostgresHostStatusRepository) Create(h *types.HostStatus) (*types.HostStatus, error) {
-	log.Trace("repository/postgres/pg_host_status: Create() Entering")
-	defer log.Trace("repository/postgres/pg_host_status: Create() Leaving")
+	r.log.Trace("repository/postgres/pg_host_status: Create() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_status: Create() Leaving")
 
 	err := r.db.Create(h).Error
 	return h, errors.Wrap(err, "Create(): failed to create HostStatus")
 }
 
 func (r *PostgresHostStatusRepository) Retrieve(h *types.HostStatus) (*types.HostStatus, error) {
-	log.Trace("repository/postgres/pg_host_status: Retrieve() Entering")
-	defer log.Trace("repository/postgres/pg_host_status: Retrieve() Leaving")
+	r.log.Trace("repository/postgres/pg_host_status: Retrieve() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_status: Retrieve() Leaving")
 
 	var p types.HostStatus
-	slog.WithField("HostStatus", h).Debug("Retrieve Call")
+	r.slog.WithField("HostStatus", h).Debug("Retrieve Call")
 	err := r.db.Where(h).First(&p).Error
 	if err != nil {
-		log.Trace("Error in fetch records Entering")
+		r.log.Trace("Error in fetch records Entering")
 		return nil, errors.Wrap(err, "Retrieve(): failed to Retrieve HostStatus")
 	}
 	return &p, nil
 }
 
 func (r *PostgresHostStatusRepository) RetrieveNonExpiredHost(h *types.HostStatus) (*types.HostStatus, error) {
-	log.Trace("repository/postgres/pg_host_status: RetrieveNonExpiredHost() Entering")
-	defer log.Trace("repository/postgres/pg_host_status: RetrieveNonExpiredHost() Leaving")
+	r.log.Trace("repository/postgres/pg_host_status: RetrieveNonExpiredHost() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_status: RetrieveNonExpiredHost() Leaving")
 
 	var hs types.HostStatus
 	err := r.db.Where("status = 'CONNECTED' and host_id = (?)", h.HostID).First(&hs).Error
 	if err != nil {
 		return nil, errors.Wrap(err, "RetrieveNonExpiredHost(): failed to RetrieveNonExpiredHost HostStatus")
 	}
-	slog.WithField("db hs", hs).Trace("RetrieveNonExpiredHost")
+	r.slog.WithField("db hs", hs).Trace("RetrieveNonExpiredHost")
 	return &hs, nil
 }
 
-func (r *PostgresHostStatusRepository) RetrieveExpiredHosts() (types.HostStatuses, error) {
-	log.Trace("repository/postgres/pg_host_status: RetrieveExpiredHosts() Entering")
-	defer log.Trace("repository/postgres/pg_host_status: RetrieveExpiredHosts() Leaving")
+func (r *PostgresHostStatusRepository) RetrieveInStatus(h *types.HostStatus, statuses []string) (*types.HostStatus, error) {
+	r.log.Trace("repository/postgres/pg_host_status: RetrieveInStatus() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_status: RetrieveInStatus() Leaving")
 
-	var hs types.HostStatuses
-	var currentTime = time.Now()
-	err := r.db.Where("(expiry_time < (?) and STATUS in ('CONNECTED')) OR (STATUS in ('IN-ACTIVE'))", currentTime).Find(&hs).Error
+	var hs types.HostStatus
+	err := r.db.Where("status in (?) and host_id = (?)", statuses, h.HostID).First(&hs).Error
 	if err != nil {
-		return nil, errors.Wrap(err, "RetrieveExpiredHosts: failed to RetrieveAll HostStatus")
+		return nil, errors.Wrap(err, "RetrieveInStatus(): failed to RetrieveInStatus HostStatus")
 	}
+	return &hs, nil
+}
 
-	slog.WithField("db hs", hs).Debug("RetrieveExpiredHosts")
-	return hs, errors.Wrap(err, "RetrieveExpiredHosts(): failed to Retrieve ExpiredHosts id")
+func (r *PostgresHostStatusRepository) ExtendExpiry(hostID uuid.UUID, expiryTime time.Time, statuses []string) (bool, error) {
+	r.log.Trace("repository/postgres/pg_host_status: ExtendExpiry() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_status: ExtendExpiry() Leaving")
+
+	tx := r.db.Model(&types.HostStatus{}).Where("host_id = ? AND status in (?)", hostID, statuses).
+		Updates(map[string]interface{}{
+			"status":       constants.HostStatusConnected,
+			"expiry_time":  expiryTime,
+			"updated_time": time.Now(),
+		})
+	if tx.Error != nil {
+		return false, errors.Wrap(tx.Error, "ExtendExpiry(): failed to update HostStatus")
+	}
+	return tx.RowsAffected > 0, nil
+}
+
+func (r *PostgresHostStatusRepository) ExpireHosts(fromStatus, toStatus string, grace time.Duration) ([]uuid.UUID, error) {
+	r.log.Trace("repository/postgres/pg_host_status: ExpireHosts() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_status: ExpireHosts() Leaving")
+
+	rows, err := r.db.Raw(expireHostsQuery, toStatus, grace.Seconds(), fromStatus).Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "ExpireHosts(): failed to update expired HostStatus")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			r.log.WithError(derr).Error("failed to close rows")
+		}
+	}()
+
+	var hostIDs []uuid.UUID
+	for rows.Next() {
+		var hostID uuid.UUID
+		if err = rows.Scan(&hostID); err != nil {
+			return nil, errors.Wrap(err, "ExpireHosts(): failed to scan expired host id")
+		}
+		hostIDs = append(hostIDs, hostID)
+	}
+	return hostIDs, errors.Wrap(rows.Err(), "ExpireHosts(): failed to read expired host ids")
 }
 
 func (r *PostgresHostStatusRepository) Update(h *types.HostStatus) error {
-	log.Trace("repository/postgres/pg_host_status: Update() Entering")
-	defer log.Trace("repository/postgres/pg_host_status: Update() Leaving")
+	r.log.Trace("repository/postgres/pg_host_status: Update() Entering")
+	defer r.log.Trace("repository/postgres/pg_host_status: Update() Leaving")
 
 	if err := r.db.Save(h).Error; err != nil {
 		return errors.Wrap(err, "Update(): failed to update HostStatus")
@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/types"
+)
+
+// claimJobQuery locks the next runnable job, skipping the jobs locked by other SHVS instances. Jobs
+// still processing past their lease are runnable again since their worker is gone.
+const claimJobQuery = `SELECT * FROM jobs WHERE status IN (?, ?) AND next_run_time <= ?
+	ORDER BY next_run_time LIMIT 1 FOR UPDATE SKIP LOCKED`
+
+// createUnlessQueuedQuery stores a job unless a job of the same type is already queued, the check and
+// the insert being a single statement
+const createUnlessQueuedQuery = `INSERT INTO jobs (id, type, status, attempts, max_attempts, payload, result,
+	last_error, owner, next_run_time, start_time, end_time, created_time, updated_time)
+	SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+	WHERE NOT EXISTS (SELECT 1 FROM jobs WHERE type = ? AND status = ?)`
+
+type PostgresJobRepository struct {
+	db  *gorm.DB
+	log *logrus.Entry
+}
+
+func (r *PostgresJobRepository) Create(j *types.Job) (*types.Job, error) {
+	r.log.Trace("repository/postgres/pg_job: Create() Entering")
+	defer r.log.Trace("repository/postgres/pg_job: Create() Leaving")
+
+	err := r.db.Create(j).Error
+	return j, errors.Wrap(err, "Create(): failed to create Job")
+}
+
+func (r *PostgresJobRepository) CreateUnlessQueued(j *types.Job) (bool, error) {
+	r.log.Trace("repository/postgres/pg_job: CreateUnlessQueued() Entering")
+	defer r.log.Trace("repository/postgres/pg_job: CreateUnlessQueued() Leaving")
+
+	tx := r.db.Exec(createUnlessQueuedQuery, j.ID, j.Type, j.Status, j.Attempts, j.MaxAttempts, j.Payload, j.Result,
+		j.LastError, j.Owner, j.NextRunTime, j.StartTime, j.EndTime, j.CreatedTime, j.UpdatedTime,
+		j.Type, constants.JobStatusQueued)
+	if tx.Error != nil {
+		return false, errors.Wrap(tx.Error, "CreateUnlessQueued(): failed to create Job")
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+func (r *PostgresJobRepository) Retrieve(j *types.Job) (*types.Job, error) {
+	r.log.Trace("repository/postgres/pg_job: Retrieve() Entering")
+	defer r.log.Trace("repository/postgres/pg_job: Retrieve() Leaving")
+
+	var job types.Job
+	err := r.db.Where(j).First(&job).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "Retrieve(): failed to Retrieve Job")
+	}
+	return &job, nil
+}
+
+func (r *PostgresJobRepository) RetrieveAll(j *types.Job, limit int) (types.Jobs, error) {
+	r.log.Trace("repository/postgres/pg_job: RetrieveAll() Entering")
+	defer r.log.Trace("repository/postgres/pg_job: RetrieveAll() Leaving")
+
+	var jobs types.Jobs
+	tx := r.db.Order("created_time desc").Limit(limit)
+	if j.Type != "" {
+		tx = tx.Where("type = ?", j.Type)
+	}
+	if j.Status != "" {
+		tx = tx.Where("status = ?", j.Status)
+	}
+	err := tx.Find(&jobs).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "RetrieveAll(): failed to RetrieveAll Jobs")
+	}
+	return jobs, nil
+}
+
+func (r *PostgresJobRepository) Update(j *types.Job) error {
+	r.log.Trace("repository/postgres/pg_job: Update() Entering")
+	defer r.log.Trace("repository/postgres/pg_job: Update() Leaving")
+
+	if err := r.db.Save(j).Error; err != nil {
+		return errors.Wrap(err, "Update(): failed to update Job")
+	}
+	return nil
+}
+
+func (r *PostgresJobRepository) CompareAndSwap(old, updated *types.Job) (bool, error) {
+	r.log.Trace("repository/postgres/pg_job: CompareAndSwap() Entering")
+	defer r.log.Trace("repository/postgres/pg_job: CompareAndSwap() Leaving")
+
+	tx := r.db.Model(&types.Job{}).Where("id = ? AND status = ? AND owner = ? AND attempts = ?",
+		old.ID, old.Status, old.Owner, old.Attempts)
+	tx = tx.Updates(map[string]interface{}{
+		"status":        updated.Status,
+		"result":        updated.Result,
+		"attempts":      updated.Attempts,
+		"last_error":    updated.LastError,
+		"owner":         updated.Owner,
+		"next_run_time": updated.NextRunTime,
+		"start_time":    updated.StartTime,
+		"end_time":      updated.EndTime,
+		"updated_time":  updated.UpdatedTime,
+	})
+	if tx.Error != nil {
+		return false, errors.Wrap(tx.Error, "CompareAndSwap(): failed to update Job")
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+func (r *PostgresJobRepository) Claim(owner string, lease time.Duration) (*types.Job, error) {
+	r.log.Trace("repository/postgres/pg_job: Claim() Entering")
+	defer r.log.Trace("repository/postgres/pg_job: Claim() Leaving")
+
+	var job *types.Job
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var j types.Job
+		now := time.Now()
+		err := tx.Raw(claimJobQuery, constants.JobStatusQueued, constants.JobStatusProcessing, now).Scan(&j).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		j.Status = constants.JobStatusProcessing
+		j.Attempts++
+		j.Owner = owner
+		j.NextRunTime = now.Add(lease)
+		j.StartTime = &now
+		j.EndTime = nil
+		j.UpdatedTime = now
+		if err = tx.Save(&j).Error; err != nil {
+			return err
+		}
+		job = &j
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Claim(): failed to claim Job")
+	}
+	return job, nil
+}
+
+func (r *PostgresJobRepository) DeleteFinishedBefore(updatedBefore time.Time) (int64, error) {
+	r.log.Trace("repository/postgres/pg_job: DeleteFinishedBefore() Entering")
+	defer r.log.Trace("repository/postgres/pg_job: DeleteFinishedBefore() Leaving")
+
+	tx := r.db.Where("status IN (?) AND updated_time < ?", []string{constants.JobStatusCompleted,
+		constants.JobStatusError, constants.JobStatusCancelled}, updatedBefore).Delete(&types.Job{})
+	if tx.Error != nil {
+		return 0, errors.Wrap(tx.Error, "DeleteFinishedBefore(): failed to delete Jobs")
+	}
+	return tx.RowsAffected, nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"intel/isecl/shvs/v5/constants"
+)
+
+// PostgresLeaderLock is a session level advisory lock. Postgres releases it when the connection holding
+// it closes, so the lock is held on a connection taken out of the pool for as long as it is held.
+type PostgresLeaderLock struct {
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+func (l *PostgresLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	log.Trace("repository/postgres/pg_leader_lock: TryAcquire() Entering")
+	defer log.Trace("repository/postgres/pg_leader_lock: TryAcquire() Leaving")
+
+	if l.conn != nil {
+		_, err := l.conn.ExecContext(ctx, "SELECT 1")
+		if err == nil {
+			return true, nil
+		}
+		l.closeConn()
+		return false, errors.Wrap(err, "TryAcquire(): lost the connection holding the leader lock")
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "TryAcquire(): failed to get a connection for the leader lock")
+	}
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", constants.SchedulerLeaderLockKey).Scan(&acquired)
+	if err != nil || !acquired {
+		if cerr := conn.Close(); cerr != nil {
+			log.WithError(cerr).Error("failed to close the leader lock connection")
+		}
+		return false, errors.Wrap(err, "TryAcquire(): failed to acquire the leader lock")
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *PostgresLeaderLock) Release() error {
+	log.Trace("repository/postgres/pg_leader_lock: Release() Entering")
+	defer log.Trace("repository/postgres/pg_leader_lock: Release() Leaving")
+
+	if l.conn == nil {
+		return nil
+	}
+	defer l.closeConn()
+	_, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", constants.SchedulerLeaderLockKey)
+	return errors.Wrap(err, "Release(): failed to release the leader lock")
+}
+
+func (l *PostgresLeaderLock) closeConn() {
+	if err := l.conn.Close(); err != nil {
+		log.WithError(err).Error("failed to close the leader lock connection")
+	}
+	l.conn = nil
+}
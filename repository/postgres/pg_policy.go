@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/types"
+)
+
+type PostgresPolicyRepository struct {
+	db  *gorm.DB
+	log *logrus.Entry
+}
+
+func (r *PostgresPolicyRepository) Create(p *types.Policy) (*types.Policy, error) {
+	r.log.Trace("repository/postgres/pg_policy: Create() Entering")
+	defer r.log.Trace("repository/postgres/pg_policy: Create() Leaving")
+
+	err := r.db.Create(p).Error
+	return p, errors.Wrap(err, "Create(): failed to create Policy")
+}
+
+func (r *PostgresPolicyRepository) Retrieve(p *types.Policy) (*types.Policy, error) {
+	r.log.Trace("repository/postgres/pg_policy: Retrieve() Entering")
+	defer r.log.Trace("repository/postgres/pg_policy: Retrieve() Leaving")
+
+	var policy types.Policy
+	err := r.db.Where(p).First(&policy).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "Retrieve(): failed to Retrieve Policy")
+	}
+	return &policy, nil
+}
+
+func (r *PostgresPolicyRepository) RetrieveAll() (types.Policies, error) {
+	r.log.Trace("repository/postgres/pg_policy: RetrieveAll() Entering")
+	defer r.log.Trace("repository/postgres/pg_policy: RetrieveAll() Leaving")
+
+	var policies types.Policies
+	err := r.db.Order("name").Find(&policies).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "RetrieveAll(): failed to RetrieveAll Policy")
+	}
+	return policies, nil
+}
+
+func (r *PostgresPolicyRepository) Update(p *types.Policy) error {
+	r.log.Trace("repository/postgres/pg_policy: Update() Entering")
+	defer r.log.Trace("repository/postgres/pg_policy: Update() Leaving")
+
+	if err := r.db.Save(p).Error; err != nil {
+		return errors.Wrap(err, "Update(): failed to update Policy")
+	}
+	return nil
+}
+
+func (r *PostgresPolicyRepository) Delete(p *types.Policy) error {
+	r.log.Trace("repository/postgres/pg_policy: Delete() Entering")
+	defer r.log.Trace("repository/postgres/pg_policy: Delete() Leaving")
+
+	if err := r.db.Delete(p).Error; err != nil {
+		return errors.Wrap(err, "Delete(): failed to delete Policy")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+// Package requestid correlates the logs of one request, from the HTTP log to the resource handlers and
+// the repositories, through the X-Request-ID header
+package requestid
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/handlers"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/constants"
+)
+
+// LogField is the field of the log entries holding the request ID
+const LogField = "request_id"
+
+// validID matches the request IDs accepted from clients, any other ID is replaced by a generated one
+var validID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Entry adds the request ID carried by ctx to the log entry
+func Entry(ctx context.Context, entry *logrus.Entry) *logrus.Entry {
+	id := FromContext(ctx)
+	if id == "" {
+		return entry
+	}
+	return entry.WithField(LogField, id)
+}
+
+// Handler accepts the X-Request-ID of the request or generates one, stores it in the request context and
+// echoes it in the response
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(constants.RequestIDHeaderKey)
+		if !validID.MatchString(id) {
+			id = uuid.New().String()
+		}
+		r = r.WithContext(NewContext(r.Context(), id))
+		r.Header.Set(constants.RequestIDHeaderKey, id)
+		w.Header().Set(constants.RequestIDHeaderKey, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WriteCombinedLog writes the request in the Apache Combined Log Format followed by its quoted request ID,
+// it is the log formatter of the HTTP log
+func WriteCombinedLog(w io.Writer, params handlers.LogFormatterParams) {
+	req := params.Request
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	user := "-"
+	if params.URL.User != nil && params.URL.User.Username() != "" {
+		user = params.URL.User.Username()
+	}
+	uri := req.RequestURI
+	if req.ProtoMajor == 2 && req.Method == http.MethodConnect {
+		uri = req.Host
+	}
+	if uri == "" {
+		uri = params.URL.RequestURI()
+	}
+	id := FromContext(req.Context())
+	if id == "" {
+		id = "-"
+	}
+	fmt.Fprintf(w, "%s - %s [%s] %s %d %d %s %s %s\n", host, user, params.TimeStamp.Format("02/Jan/2006:15:04:05 -0700"),
+		strconv.Quote(req.Method+" "+uri+" "+req.Proto), params.StatusCode, params.Size,
+		strconv.Quote(req.Referer()), strconv.Quote(req.UserAgent()), strconv.Quote(id))
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/handlers"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"intel/isecl/shvs/v5/constants"
+)
+
+func serve(req *http.Request) (*httptest.ResponseRecorder, string) {
+	var seen string
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w, seen
+}
+
+func TestHandlerKeepsClientRequestID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/hosts", nil)
+	req.Header.Set(constants.RequestIDHeaderKey, "agent-7d4b2a6c")
+	w, seen := serve(req)
+	assert.Equal(t, "agent-7d4b2a6c", seen)
+	assert.Equal(t, "agent-7d4b2a6c", w.Header().Get(constants.RequestIDHeaderKey))
+}
+
+func TestHandlerGeneratesRequestID(t *testing.T) {
+	for _, id := range []string{"", "has spaces", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest(http.MethodPost, "/hosts", nil)
+		req.Header.Set(constants.RequestIDHeaderKey, id)
+		w, seen := serve(req)
+		_, err := uuid.Parse(seen)
+		assert.NoError(t, err)
+		assert.Equal(t, seen, w.Header().Get(constants.RequestIDHeaderKey))
+	}
+}
+
+func TestEntry(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+	assert.Equal(t, entry, Entry(context.Background(), entry))
+
+	withID := Entry(NewContext(context.Background(), "7d4b2a6c"), entry)
+	assert.Equal(t, "7d4b2a6c", withID.Data[LogField])
+	assert.NotContains(t, entry.Data, LogField)
+}
+
+func TestWriteCombinedLog(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/sgx-hvs/v2/hosts", nil)
+	req.RemoteAddr = "10.0.0.1:41000"
+	req.Header.Set("User-Agent", "shvs-agent")
+	req = req.WithContext(NewContext(req.Context(), "7d4b2a6c"))
+
+	var buf bytes.Buffer
+	WriteCombinedLog(&buf, handlers.LogFormatterParams{
+		Request:    req,
+		URL:        *req.URL,
+		TimeStamp:  time.Date(2022, 5, 4, 10, 30, 0, 0, time.UTC),
+		StatusCode: http.StatusCreated,
+		Size:       96,
+	})
+	assert.Equal(t, `10.0.0.1 - - [04/May/2022:10:30:00 +0000] "POST /sgx-hvs/v2/hosts HTTP/1.1" 201 96 "" "shvs-agent" "7d4b2a6c"`+"\n", buf.String())
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/lib/common/v5/context"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	"intel/isecl/shvs/v5/audit"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/requestid"
+	"intel/isecl/shvs/v5/types"
+)
+
+var auditEventsRetrieveParams = map[string]bool{"action": true, "subject": true, "target": true, "result": true,
+	"fromTime": true, "toTime": true, "limit": true}
+
+var auditActions = map[string]bool{
+	constants.AuditActionHostRegister: true,
+	constants.AuditActionHostUpdate:   true,
+	constants.AuditActionHostRestore:  true,
+	constants.AuditActionHostDelete:   true,
+	constants.AuditActionPolicyCreate: true,
+	constants.AuditActionPolicyUpdate: true,
+	constants.AuditActionPolicyDelete: true,
+	constants.AuditActionJobRetry:     true,
+	constants.AuditActionJobCancel:    true,
+	constants.AuditActionConfigReload: true,
+}
+
+var auditResults = map[string]bool{
+	constants.AuditResultSuccess: true,
+	constants.AuditResultFailure: true,
+	constants.AuditResultDenied:  true,
+}
+
+var auditLog *audit.Log
+
+// SetAuditLog sets the hash-chained file the audit events are appended to, besides the database
+func SetAuditLog(l *audit.Log) {
+	auditLog = l
+}
+
+type auditEventKey struct{}
+
+// auditedAction is the event recorded for the action handling a request, unless the handler skips it
+type auditedAction struct {
+	event *types.AuditEvent
+	skip  bool
+}
+
+// audited records the outcome of an administrative action once its handler returns, including the requests
+// denied by the handler. The target of the action is the {id} of the route, unless the handler names it
+// through the event given by auditEvent.
+func audited(action string, db repository.SHVSDatabase, next errorHandlerFunc) errorHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		audited := &auditedAction{event: &types.AuditEvent{Action: action, Target: mux.Vars(r)["id"]}}
+		err := next(w, r.WithContext(stdcontext.WithValue(r.Context(), auditEventKey{}, audited)))
+		if !audited.skip || err != nil {
+			recordAudit(r, db, audited.event, err)
+		}
+		return err
+	}
+}
+
+// auditEvent returns the event recorded for the action handling the request
+func auditEvent(r *http.Request) *types.AuditEvent {
+	if audited, ok := r.Context().Value(auditEventKey{}).(*auditedAction); ok {
+		return audited.event
+	}
+	return &types.AuditEvent{}
+}
+
+// skipAudit leaves the action handling the request unrecorded when it succeeds. It keeps routine requests,
+// such as the periodic re-registration of an unchanged host, from growing the append-only audit log.
+func skipAudit(r *http.Request) {
+	if audited, ok := r.Context().Value(auditEventKey{}).(*auditedAction); ok {
+		audited.skip = true
+	}
+}
+
+func recordAudit(r *http.Request, db repository.SHVSDatabase, event *types.AuditEvent, err error) {
+	event.Subject, _ = context.GetTokenSubject(r)
+	roles, _ := context.GetUserRoles(r)
+	for _, role := range roles {
+		name := role.Service + ":" + role.Name
+		if role.Context != "" {
+			name += ":" + role.Context
+		}
+		event.Roles = append(event.Roles, name)
+	}
+	event.RequestID = requestid.FromContext(r.Context())
+	event.RemoteAddr = r.RemoteAddr
+	event.Result = constants.AuditResultSuccess
+	if err != nil {
+		problem := errorProblem(r, err)
+		event.Result = constants.AuditResultFailure
+		if problem.Status == http.StatusUnauthorized || problem.Status == http.StatusForbidden {
+			event.Result = constants.AuditResultDenied
+		}
+		event.Detail = problem.Detail
+	}
+
+	err = audit.Record(db.WithContext(r.Context()), auditLog, event)
+	if err != nil {
+		requestLog(r, slog).WithError(err).Errorf("resource/audit: recordAudit() Failed to record %s of %s",
+			event.Action, event.Target)
+	}
+}
+
+func AuditEventOps(r *mux.Router, db repository.SHVSDatabase) {
+	log.Trace("resource/audit: AuditEventOps() Entering")
+	defer log.Trace("resource/audit: AuditEventOps() Leaving")
+
+	r.Handle("/audit-events", queryAuditEvents(db)).Methods("GET")
+}
+
+func queryAuditEvents(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/audit: queryAuditEvents() Entering")
+		defer log.Trace("resource/audit: queryAuditEvents() Leaving")
+
+		err := authorizeEndpoint(r, constants.AuditReaderGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		filter, err := auditEventFilter(r)
+		if err != nil {
+			slog.WithError(err).Errorf("resource/audit: queryAuditEvents() %s", commLogMsg.InvalidInputBadParam)
+			return err
+		}
+
+		events, err := db.AuditEventRepository().RetrieveAll(filter)
+		if err != nil {
+			log.WithError(err).Info("failed to retrieve audit events")
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		if events == nil {
+			events = types.AuditEvents{}
+		}
+		slog.Infof("%s: Audit events retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+
+		js, err := json.Marshal(events)
+		if err != nil {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+		_, err = w.Write(js)
+		if err != nil {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		return nil
+	})
+}
+
+// auditEventFilter reads the filter of the audit events from the query parameters, the times being RFC3339
+func auditEventFilter(r *http.Request) (*types.AuditEventFilter, error) {
+	params := r.URL.Query()
+	if err := validateQueryParams(params, auditEventsRetrieveParams); err != nil {
+		return nil, &resourceError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+	}
+
+	filter := &types.AuditEventFilter{
+		Action:  params.Get("action"),
+		Subject: params.Get("subject"),
+		Target:  params.Get("target"),
+		Result:  strings.ToUpper(params.Get("result")),
+		Limit:   constants.DefaultAuditEventsLimit,
+	}
+	var fieldErrors []FieldError
+	if filter.Action != "" && !auditActions[filter.Action] {
+		fieldErrors = append(fieldErrors, FieldError{Field: "action", Message: "invalid action"})
+	}
+	if filter.Result != "" && !auditResults[filter.Result] {
+		fieldErrors = append(fieldErrors, FieldError{Field: "result", Message: "invalid result"})
+	}
+	if len(filter.Subject) > constants.MaxQueryParamsLength {
+		fieldErrors = append(fieldErrors, FieldError{Field: "subject", Message: "invalid subject"})
+	}
+	if len(filter.Target) > constants.MaxQueryParamsLength {
+		fieldErrors = append(fieldErrors, FieldError{Field: "target", Message: "invalid target"})
+	}
+	times := []struct {
+		name  string
+		value *time.Time
+	}{
+		{"fromTime", &filter.FromTime},
+		{"toTime", &filter.ToTime},
+	}
+	for _, t := range times {
+		if params.Get(t.name) == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, params.Get(t.name))
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: t.name, Message: "invalid RFC3339 time"})
+			continue
+		}
+		*t.value = parsed
+	}
+	if params.Get("limit") != "" {
+		limit, err := strconv.Atoi(params.Get("limit"))
+		if err != nil || limit <= 0 || limit > constants.MaxAuditEventsLimit {
+			fieldErrors = append(fieldErrors, FieldError{Field: "limit", Message: "limit must be between 1 and " +
+				strconv.Itoa(constants.MaxAuditEventsLimit)})
+		} else {
+			filter.Limit = limit
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &resourceError{Message: "Invalid audit event filter", StatusCode: http.StatusBadRequest,
+			Code: ErrorCodeInvalidInput, FieldErrors: fieldErrors}
+	}
+	return filter, nil
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditEvents", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	hostRepo := mock.MockHostRepository{}
+	hostStatusRepo := mock.MockHostStatusRepository{}
+	hostSgxRepo := mock.MockHostSgxDataRepository{}
+
+	db := mock.NewMockDatabase(hostRepo, hostStatusRepo, hostSgxRepo)
+
+	host := types.Host{
+		ID:           uuid.New(),
+		Name:         "audited-host",
+		HardwareUUID: uuid.New(),
+		CreatedTime:  time.Now(),
+		UpdatedTime:  time.Now(),
+	}
+	_, _ = db.HostRepository().Create(&host)
+	_, _ = db.HostStatusRepository().Create(&types.HostStatus{
+		ID:          host.ID,
+		HostID:      uuid.New(),
+		Status:      constants.HostStatusConnected,
+		CreatedTime: time.Now(),
+		UpdatedTime: time.Now(),
+		ExpiryTime:  time.Now().Add(time.Hour),
+	})
+
+	hostManagerRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.HostListManagerGroupName,
+			Context: "type=SHVS",
+		},
+	}
+	auditReaderRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.AuditReaderGroupName,
+		},
+	}
+
+	sendRequest := func(method, path, subject string, roles []aas.RoleInfo) {
+		req, err := http.NewRequest(method, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req = context.SetUserRoles(req, roles)
+		req = context.SetTokenSubject(req, subject)
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	registerHost := func(hardwareUUID uuid.UUID, info SGXHostInfo) {
+		body, _ := json.Marshal(info)
+		req, err := http.NewRequest(http.MethodPost, "/hosts", bytes.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req = context.SetUserRoles(req, []aas.RoleInfo{{Service: constants.ServiceName, Name: constants.HostDataUpdaterGroupName}})
+		req = context.SetTokenSubject(req, hardwareUUID.String())
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	queryEvents := func(query string) types.AuditEvents {
+		sendRequest(http.MethodGet, "/audit-events"+query, "auditor", auditReaderRoles)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var events types.AuditEvents
+		err := json.Unmarshal(w.Body.Bytes(), &events)
+		Expect(err).NotTo(HaveOccurred())
+		return events
+	}
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		SGXHostRegisterOps(router, db)
+		AuditEventOps(router, db)
+	})
+
+	Describe("Record administrative actions", func() {
+		It("Should record a denied host deletion", func() {
+			sendRequest(http.MethodDelete, "/hosts/"+host.ID.String(), "auditor", auditReaderRoles)
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+
+			events := queryEvents("?action=host-delete&result=DENIED")
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Subject).To(Equal("auditor"))
+			Expect(events[0].Target).To(Equal(host.ID.String()))
+		})
+
+		It("Should record a host deletion", func() {
+			sendRequest(http.MethodDelete, "/hosts/"+host.ID.String(), "admin", hostManagerRoles)
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+
+			events := queryEvents("?action=host-delete&result=SUCCESS")
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Subject).To(Equal("admin"))
+			Expect(events[0].Roles).To(Equal(types.AuditRoles{"SHVS:HostListManager:type=SHVS"}))
+			Expect(events[0].Target).To(Equal(host.ID.String()))
+			Expect(events[0].Result).To(Equal(constants.AuditResultSuccess))
+		})
+
+		It("Should record a host update only when the registration changes the host", func() {
+			agentHost := types.Host{
+				ID:           uuid.New(),
+				Name:         "agent-host",
+				Description:  "rack 4",
+				HardwareUUID: uuid.New(),
+				CreatedTime:  time.Now(),
+				UpdatedTime:  time.Now(),
+			}
+			_, _ = db.HostRepository().Create(&agentHost)
+			_, _ = db.HostStatusRepository().Create(&types.HostStatus{ID: agentHost.ID, HostID: agentHost.HardwareUUID,
+				Status: constants.HostStatusConnected, CreatedTime: time.Now(), UpdatedTime: time.Now()})
+			info := SGXHostInfo{HostName: agentHost.Name, Description: "rack 4", UUID: agentHost.HardwareUUID.String(),
+				SgxSupported: true}
+
+			registerHost(agentHost.HardwareUUID, info)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(queryEvents("?target=" + agentHost.ID.String())).To(HaveLen(0))
+
+			info.Description = "rack 5"
+			registerHost(agentHost.HardwareUUID, info)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			events := queryEvents("?target=" + agentHost.ID.String())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal(constants.AuditActionHostUpdate))
+		})
+	})
+
+	Describe("Retrieve audit events", func() {
+		It("Should filter audit events by subject and limit", func() {
+			Expect(queryEvents("?subject=admin")).To(HaveLen(1))
+			Expect(queryEvents("?limit=1")).To(HaveLen(1))
+			Expect(queryEvents("?toTime=2000-01-01T00:00:00Z")).To(HaveLen(0))
+		})
+
+		It("Should not query audit events - Insufficient roles were given", func() {
+			sendRequest(http.MethodGet, "/audit-events", "admin", hostManagerRoles)
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("Should not query audit events - Invalid filter was given", func() {
+			sendRequest(http.MethodGet, "/audit-events?result=MAYBE&limit=5000", "auditor", auditReaderRoles)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+			var problem Problem
+			err := json.Unmarshal(w.Body.Bytes(), &problem)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problem.Errors).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"crypto/x509"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"intel/isecl/lib/common/v5/context"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	ct "intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+)
+
+// ClientCertAuth authenticates the agents presenting a client certificate verified by the TLS handshake
+// against the CMS CAs. The hardware UUID named by the certificate becomes the token subject of the request,
+// with the HostDataUpdater role, so that the agent can register its own host only. Requests carrying a bearer
+// token or no verified certificate are authenticated by tokenAuth.
+func ClientCertAuth(tokenAuth mux.MiddlewareFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		tokenAuthHandler := tokenAuth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 ||
+				len(r.TLS.VerifiedChains[0]) == 0 {
+				tokenAuthHandler.ServeHTTP(w, r)
+				return
+			}
+
+			cert := r.TLS.VerifiedChains[0][0]
+			hardwareUUID, err := certHardwareUUID(cert)
+			if err != nil {
+				requestLog(r, slog).WithError(err).Warnf("resource/client_cert_auth: %s: Invalid client certificate %q, requested from %s",
+					commLogMsg.AuthenticationFailed, cert.Subject.String(), r.RemoteAddr)
+				writeProblem(w, newProblem(r, http.StatusUnauthorized, "", "Client certificate does not name a hardware UUID", nil))
+				return
+			}
+
+			r = context.SetTokenSubject(r, hardwareUUID)
+			r = context.SetUserRoles(r, []ct.RoleInfo{{Service: constants.ServiceName, Name: constants.HostDataUpdaterGroupName}})
+			requestLog(r, slog).Infof("resource/client_cert_auth: %s: Host %s authenticated with a client certificate from %s",
+				commLogMsg.AuthorizedAccess, hardwareUUID, r.RemoteAddr)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// certHardwareUUID returns the hardware UUID given by the common name or the DNS and URI SANs of the
+// certificate, a URI SAN being written as urn:uuid:<hardware uuid>. All of them which are UUIDs must agree. The
+// UUID is returned in lower case, as the hardware UUIDs are stored, whatever the case of the certificate.
+func certHardwareUUID(cert *x509.Certificate) (string, error) {
+	names := []string{cert.Subject.CommonName}
+	names = append(names, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		if strings.EqualFold(uri.Scheme, "urn") && strings.HasPrefix(strings.ToLower(uri.Opaque), "uuid:") {
+			names = append(names, uri.Opaque[len("uuid:"):])
+		}
+	}
+
+	hardwareUUID := ""
+	var parsed uuid.UUID
+	for _, name := range names {
+		id, err := uuid.Parse(name)
+		if err != nil || len(name) != len(id.String()) {
+			continue
+		}
+		if hardwareUUID == "" {
+			hardwareUUID, parsed = name, id
+		} else if id != parsed {
+			return "", errors.Errorf("certificate names both %s and %s", hardwareUUID, name)
+		}
+	}
+	if hardwareUUID == "" {
+		return "", errors.New("certificate names no hardware UUID")
+	}
+	return parsed.String(), nil
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/shvs/v5/constants"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientCertAuth", func() {
+	const hardwareUUID = "88888888-8887-1214-0516-3707a5a5a5a5"
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	var subject string
+	var tokenAuthCalled bool
+
+	tokenAuth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenAuthCalled = true
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+
+	sendRequest := func(cert *x509.Certificate, authorization string) {
+		req, err := http.NewRequest(http.MethodPost, "/hosts", nil)
+		Expect(err).NotTo(HaveOccurred())
+		if cert != nil {
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	BeforeEach(func() {
+		subject = ""
+		tokenAuthCalled = false
+		router = mux.NewRouter()
+		router.Use(ClientCertAuth(tokenAuth))
+		router.HandleFunc("/hosts", func(w http.ResponseWriter, r *http.Request) {
+			subject, _ = context.GetTokenSubject(r)
+			if authorizeEndpoint(r, constants.HostDataUpdaterGroupName, true) != nil {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	It("Should authenticate an agent by the common name of its certificate", func() {
+		sendRequest(&x509.Certificate{Subject: pkix.Name{CommonName: hardwareUUID}}, "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(subject).To(Equal(hardwareUUID))
+		Expect(tokenAuthCalled).To(BeFalse())
+	})
+
+	It("Should authenticate an agent by the URI SAN of its certificate", func() {
+		uri, _ := url.Parse("urn:uuid:" + hardwareUUID)
+		sendRequest(&x509.Certificate{Subject: pkix.Name{CommonName: "SGX Agent"}, URIs: []*url.URL{uri}}, "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(subject).To(Equal(hardwareUUID))
+	})
+
+	It("Should authenticate an agent whose certificate names its hardware UUID in upper case", func() {
+		uri, _ := url.Parse("urn:uuid:" + hardwareUUID)
+		sendRequest(&x509.Certificate{Subject: pkix.Name{CommonName: strings.ToUpper(hardwareUUID)}, URIs: []*url.URL{uri}}, "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(subject).To(Equal(hardwareUUID))
+	})
+
+	It("Should not authenticate a certificate without a hardware UUID", func() {
+		sendRequest(&x509.Certificate{Subject: pkix.Name{CommonName: "SGX Agent"}, DNSNames: []string{"node1.acme.example"}}, "")
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeProblemJSON))
+		Expect(tokenAuthCalled).To(BeFalse())
+
+		sendRequest(&x509.Certificate{Subject: pkix.Name{CommonName: hardwareUUID},
+			DNSNames: []string{"99999999-8887-1214-0516-3707a5a5a5a5"}}, "")
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("Should leave bearer tokens and requests without certificate to the token authentication", func() {
+		sendRequest(&x509.Certificate{Subject: pkix.Name{CommonName: hardwareUUID}}, "Bearer token")
+		Expect(tokenAuthCalled).To(BeTrue())
+
+		tokenAuthCalled = false
+		sendRequest(nil, "")
+		Expect(tokenAuthCalled).To(BeTrue())
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	})
+})
@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+func ComplianceReportOps(r *mux.Router, db repository.SHVSDatabase) {
+	log.Trace("resource/compliance_report: ComplianceReportOps() Entering")
+	defer log.Trace("resource/compliance_report: ComplianceReportOps() Leaving")
+
+	r.Handle("/reports", contentTypeHandler(queryComplianceReports(db), "application/json")).Methods("GET")
+	r.Handle("/reports/{id}", contentTypeHandler(getComplianceReport(db), "application/json")).Methods("GET")
+}
+
+// GenerateComplianceReport takes a snapshot of the fleet and stores it as a compliance report. A host is
+// non-compliant when SGX is not enabled, its TCB is not up to date or it has been IN-ACTIVE for longer than
+// inactiveHours. The status of a host is updated when it turns IN-ACTIVE, while its heartbeats do not update the
+// host itself, so the time it has been IN-ACTIVE is measured from the update of its status.
+func GenerateComplianceReport(ctx context.Context, db repository.SHVSDatabase, inactiveHours int) (*types.ComplianceReport, error) {
+	log.Trace("resource/compliance_report: GenerateComplianceReport() Entering")
+	defer log.Trace("resource/compliance_report: GenerateComplianceReport() Leaving")
+
+	now := time.Now()
+	inactiveBefore := now.Add(-time.Duration(inactiveHours) * time.Hour)
+	report := types.ComplianceReport{
+		ID:          uuid.New(),
+		CreatedTime: now,
+	}
+
+	err := db.HostRepository().StreamHostInventory(&types.Host{}, nil, func(host *types.HostInventory) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report.TotalHosts++
+		nch := types.NonCompliantHost{
+			HostID:       host.HostID,
+			HostName:     host.HostName,
+			HardwareUUID: host.HardwareUUID,
+			SgxDisabled:  host.SgxEnabled == nil || !*host.SgxEnabled,
+			TcbOutOfDate: host.TcbUptodate == nil || !*host.TcbUptodate,
+			LastSeenTime: host.LastSeenTime,
+		}
+		if host.Status != nil {
+			nch.Status = *host.Status
+			nch.Inactive = nch.Status == constants.HostStatusInactive && host.UpdatedTime != nil &&
+				host.UpdatedTime.Before(inactiveBefore)
+		}
+
+		if nch.SgxDisabled {
+			report.SgxDisabledHosts++
+		}
+		if nch.TcbOutOfDate {
+			report.TcbOutOfDateHosts++
+		}
+		if nch.Inactive {
+			report.InactiveHosts++
+		}
+		if !nch.SgxDisabled && !nch.TcbOutOfDate && !nch.Inactive {
+			report.CompliantHosts++
+			return nil
+		}
+		report.NonCompliantHosts = append(report.NonCompliantHosts, nch)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "GenerateComplianceReport: Error while reading host inventory")
+	}
+	if ctx.Err() != nil {
+		return nil, errors.Wrap(ctx.Err(), "GenerateComplianceReport: stopped before storing compliance report")
+	}
+
+	createdReport, err := db.ComplianceReportRepository().Create(&report)
+	if err != nil {
+		return nil, errors.Wrap(err, "GenerateComplianceReport: Error while storing compliance report")
+	}
+	return createdReport, nil
+}
+
+// authorizeReportEndpoint authorizes the requests reading the compliance reports. A report covers all hosts, so
+// the roles scoped to some hosts cannot read it.
+func authorizeReportEndpoint(r *http.Request) error {
+	scopes, err := authorizeHostEndpoint(r, constants.HostListReaderGroupName)
+	if err != nil {
+		return err
+	}
+	if scopes != nil {
+		requestLog(r, slog).Infof("resource/compliance_report: authorizeReportEndpoint() %s: Compliance reports requested by a role scoped to some hosts from: %s",
+			commLogMsg.UnauthorizedAccess, r.RemoteAddr)
+		return &resourceError{Message: "Compliance reports cover all hosts and cannot be read by a role scoped to some hosts",
+			StatusCode: http.StatusForbidden}
+	}
+	return nil
+}
+
+func queryComplianceReports(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/compliance_report: queryComplianceReports() Entering")
+		defer log.Trace("resource/compliance_report: queryComplianceReports() Leaving")
+
+		err := authorizeReportEndpoint(r)
+		if err != nil {
+			return err
+		}
+
+		if len(r.URL.Query()) != 0 {
+			slog.Errorf("resource/compliance_report: queryComplianceReports() %s", commLogMsg.InvalidInputBadParam)
+			return &resourceError{Message: "Invalid query parameters provided", StatusCode: http.StatusBadRequest}
+		}
+
+		reports, err := db.ComplianceReportRepository().RetrieveAll()
+		if err != nil {
+			log.WithError(err).Info("failed to retrieve compliance reports")
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		if len(reports) == 0 {
+			log.Error("resource/compliance_report: queryComplianceReports() no data is found")
+			return &resourceError{Message: "no compliance report is found", StatusCode: http.StatusNotFound}
+		}
+
+		js, err := json.Marshal(reports)
+		if err != nil {
+			log.WithError(err).Info("resource/compliance_report: queryComplianceReports() Marshalling unsuccessful")
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(js)
+		if err != nil {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		slog.Infof("%s: Compliance reports retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return nil
+	})
+}
+
+func getComplianceReport(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/compliance_report: getComplianceReport() Entering")
+		defer log.Trace("resource/compliance_report: getComplianceReport() Leaving")
+
+		err := authorizeReportEndpoint(r)
+		if err != nil {
+			return err
+		}
+
+		id, validationErr := uuid.Parse(mux.Vars(r)["id"])
+		if validationErr != nil {
+			slog.Errorf("resource/compliance_report: getComplianceReport() Input validation failed for report ID")
+			return &resourceError{Message: validationErr.Error(), StatusCode: http.StatusBadRequest}
+		}
+
+		report, err := db.ComplianceReportRepository().Retrieve(&types.ComplianceReport{ID: id})
+		if report == nil || err != nil {
+			log.WithError(err).WithField("id", id).Info("attempt to fetch invalid compliance report")
+			return &resourceError{Message: "Compliance report with given id don't exist",
+				StatusCode: http.StatusNotFound}
+		}
+
+		js, err := json.Marshal(report)
+		if err != nil {
+			log.WithError(err).Info("resource/compliance_report: getComplianceReport() Marshalling unsuccessful")
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(js)
+		if err != nil {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		slog.Infof("%s: Compliance report retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return nil
+	})
+}
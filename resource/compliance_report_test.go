@@ -0,0 +1,198 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ComplianceReports", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	hostRepo := mock.MockHostRepository{}
+	hostStatusRepo := mock.MockHostStatusRepository{}
+	hostSgxRepo := mock.MockHostSgxDataRepository{}
+
+	db := mock.NewMockDatabase(hostRepo, hostStatusRepo, hostSgxRepo)
+
+	reportReaderRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.HostListReaderGroupName,
+			Context: "type=SHVS",
+		},
+	}
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+	})
+
+	Describe("Query compliance reports", func() {
+		Context("Validate query compliance reports request", func() {
+			It("Should not query compliance reports - Insufficient roles were given", func() {
+				ComplianceReportOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/reports", nil)
+				val := []aas.RoleInfo{
+					{
+						Service: constants.ServiceName,
+						Name:    constants.HostDataReaderGroupName,
+						Context: "type=SHVS",
+					},
+				}
+				req = context.SetUserRoles(req, val)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+			})
+
+			It("Should not query compliance reports - No data found", func() {
+				ComplianceReportOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/reports", nil)
+				req = context.SetUserRoles(req, reportReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+
+			It("Should not query compliance reports - Invalid query param were given", func() {
+				ComplianceReportOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/reports?status=all", nil)
+				req = context.SetUserRoles(req, reportReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+
+			It("Should generate and query compliance reports", func() {
+				host := types.Host{
+					ID:           uuid.New(),
+					Name:         "reporthostname",
+					HardwareUUID: uuid.New(),
+					CreatedTime:  time.Now(),
+					UpdatedTime:  time.Now(),
+				}
+				db.HostRepository().Create(&host)
+
+				report, err := GenerateComplianceReport(stdcontext.Background(), db, constants.DefaultReportInactiveHours)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.TotalHosts).To(Equal(report.CompliantHosts + len(report.NonCompliantHosts)))
+				Expect(report.SgxDisabledHosts).To(Equal(report.TotalHosts))
+
+				ComplianceReportOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/reports", nil)
+				req = context.SetUserRoles(req, reportReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var reports types.ComplianceReports
+				err = json.Unmarshal(w.Body.Bytes(), &reports)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reports).To(HaveLen(1))
+				Expect(reports[0].NonCompliantHosts).To(BeEmpty())
+
+				req, err = http.NewRequest(http.MethodGet, "/reports/"+report.ID.String(), nil)
+				req = context.SetUserRoles(req, reportReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var fetchedReport types.ComplianceReport
+				err = json.Unmarshal(w.Body.Bytes(), &fetchedReport)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fetchedReport.NonCompliantHosts).To(HaveLen(len(report.NonCompliantHosts)))
+			})
+
+			It("Should measure the time a host has been IN-ACTIVE from its status", func() {
+				reportDB := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+				registered := time.Now().Add(-30 * 24 * time.Hour)
+				recentlyInactive := types.Host{ID: uuid.New(), Name: "recently-inactive", HardwareUUID: uuid.New(),
+					CreatedTime: registered, UpdatedTime: registered}
+				longInactive := types.Host{ID: uuid.New(), Name: "long-inactive", HardwareUUID: uuid.New(),
+					CreatedTime: registered, UpdatedTime: registered}
+				for host, inactiveSince := range map[*types.Host]time.Time{
+					&recentlyInactive: time.Now().Add(-5 * time.Minute),
+					&longInactive:     time.Now().Add(-time.Duration(constants.DefaultReportInactiveHours+1) * time.Hour),
+				} {
+					_, _ = reportDB.HostRepository().Create(host)
+					_, _ = reportDB.HostStatusRepository().Create(&types.HostStatus{ID: uuid.New(), HostID: host.ID,
+						Status: constants.HostStatusInactive, CreatedTime: registered, UpdatedTime: inactiveSince,
+						ExpiryTime: inactiveSince})
+				}
+
+				report, err := GenerateComplianceReport(stdcontext.Background(), reportDB, constants.DefaultReportInactiveHours)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.InactiveHosts).To(Equal(1))
+				for _, host := range report.NonCompliantHosts {
+					Expect(host.Inactive).To(Equal(host.HostID == longInactive.ID))
+				}
+			})
+		})
+	})
+
+	Describe("Get compliance report", func() {
+		Context("Validate get compliance report request", func() {
+			It("Should not get compliance report - Invalid report id were given", func() {
+				ComplianceReportOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/reports/invalidUUID", nil)
+				req = context.SetUserRoles(req, reportReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+
+			It("Should not get compliance report - Unknown report id were given", func() {
+				ComplianceReportOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/reports/"+uuid.New().String(), nil)
+				req = context.SetUserRoles(req, reportReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+})
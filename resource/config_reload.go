@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+)
+
+// ConfigReloadStatus is the response payload of the configuration reload endpoint
+type ConfigReloadStatus struct {
+	Status string `json:"status"`
+	// RestartRequired lists the changed settings which take effect on the next restart
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+var configReloader func() ([]string, error)
+
+// SetConfigReloader sets the function reloading the configuration, returning the changed settings which
+// take effect on the next restart
+func SetConfigReloader(reload func() ([]string, error)) {
+	configReloader = reload
+}
+
+func ConfigReloadOps(r *mux.Router, db repository.SHVSDatabase) {
+	log.Trace("resource/config_reload: ConfigReloadOps() Entering")
+	defer log.Trace("resource/config_reload: ConfigReloadOps() Leaving")
+
+	r.Handle("/admin/config/reload", audited(constants.AuditActionConfigReload, db, reloadConfig())).Methods("POST")
+}
+
+func reloadConfig() errorHandlerFunc {
+	return withRequest(nil, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, _ repository.SHVSDatabase) error {
+		log.Trace("resource/config_reload: reloadConfig() Entering")
+		defer log.Trace("resource/config_reload: reloadConfig() Leaving")
+
+		err := authorizeEndpoint(r, constants.ConfigAdminGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		if configReloader == nil {
+			return &resourceError{Message: "Configuration reload is not available", StatusCode: http.StatusServiceUnavailable}
+		}
+		restartRequired, err := configReloader()
+		if err != nil {
+			log.WithError(err).Error("resource/config_reload: reloadConfig() Failed to reload configuration")
+			return &resourceError{Message: "Failed to reload configuration: " + err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+
+		slog.Infof("%s: Configuration reloaded by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		if len(restartRequired) > 0 {
+			auditEvent(r).Detail = "restart required: " + strings.Join(restartRequired, ", ")
+		}
+		js, err := json.Marshal(ConfigReloadStatus{Status: "reloaded", RestartRequired: restartRequired})
+		if err != nil {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+		_, err = w.Write(js)
+		if err != nil {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"errors"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfigReload", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+
+	configAdminRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.ConfigAdminGroupName,
+			Context: "type=SHVS",
+		},
+	}
+	jobAdminRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.JobAdminGroupName,
+			Context: "type=SHVS",
+		},
+	}
+
+	sendReloadRequest := func(roles []aas.RoleInfo) {
+		req, err := http.NewRequest(http.MethodPost, "/admin/config/reload", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req = context.SetUserRoles(req, roles)
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		ConfigReloadOps(router, db)
+	})
+
+	AfterEach(func() {
+		SetConfigReloader(nil)
+	})
+
+	Describe("Reload configuration", func() {
+		Context("Validate reload configuration request", func() {
+			It("Should not reload configuration - Insufficient roles were given", func() {
+				reloaded := false
+				SetConfigReloader(func() ([]string, error) {
+					reloaded = true
+					return nil, nil
+				})
+				sendReloadRequest(jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+				Expect(reloaded).To(BeFalse())
+			})
+
+			It("Should reload configuration", func() {
+				SetConfigReloader(func() ([]string, error) {
+					return []string{"Port"}, nil
+				})
+				sendReloadRequest(configAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var status ConfigReloadStatus
+				err := json.Unmarshal(w.Body.Bytes(), &status)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Status).To(Equal("reloaded"))
+				Expect(status.RestartRequired).To(Equal([]string{"Port"}))
+			})
+
+			It("Should not reload configuration - Configuration is invalid", func() {
+				SetConfigReloader(func() ([]string, error) {
+					return nil, errors.New("configuration is invalid")
+				})
+				sendReloadRequest(configAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusInternalServerError))
+			})
+
+			It("Should not reload configuration - Reload is not available", func() {
+				sendReloadRequest(configAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+			})
+		})
+	})
+})
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"intel/isecl/shvs/v5/constants"
+)
+
+// HealthStatus is the response payload of the health endpoint
+type HealthStatus struct {
+	Status string `json:"status"`
+	// Leader tells whether this instance runs the periodic schedulers
+	Leader bool `json:"leader"`
+}
+
+var leaderStatus func() bool
+
+// SetLeaderStatus sets the function reporting whether this instance is the scheduler leader
+func SetLeaderStatus(isLeader func() bool) {
+	leaderStatus = isLeader
+}
+
+func SetHealthRoutes(r *mux.Router) {
+	r.Handle("/health", getHealth()).Methods("GET")
+}
+
+func getHealth() http.HandlerFunc {
+	log.Trace("resource/health:getHealth() Entering")
+	defer log.Trace("resource/health:getHealth() Leaving")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := HealthStatus{Status: "UP"}
+		if leaderStatus != nil {
+			health.Leader = leaderStatus()
+		}
+		js, err := json.Marshal(health)
+		if err != nil {
+			log.WithError(err).Error("Could not marshal health status")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+		_, err = w.Write(js)
+		if err != nil {
+			log.WithError(err).Error("Could not write health status to response")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HealthTest", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		SetHealthRoutes(router)
+	})
+
+	AfterEach(func() {
+		SetLeaderStatus(nil)
+	})
+
+	Describe("GetHealth", func() {
+		Context("GetHealth request", func() {
+			It("Should report the leadership of this instance", func() {
+				SetLeaderStatus(func() bool { return true })
+				req, err := http.NewRequest(http.MethodGet, "/health", nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var health HealthStatus
+				err = json.Unmarshal(w.Body.Bytes(), &health)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(health.Status).To(Equal("UP"))
+				Expect(health.Leader).To(BeTrue())
+			})
+
+			It("Should report a follower without leader election", func() {
+				req, err := http.NewRequest(http.MethodGet, "/health", nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var health HealthStatus
+				err = json.Unmarshal(w.Body.Bytes(), &health)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(health.Leader).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/lib/common/v5/context"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	"intel/isecl/shvs/v5/config"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+// HostHeartbeatResponse is the response payload of the host heartbeat endpoint
+type HostHeartbeatResponse struct {
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
+	// ValidTo is the time until which the host stays connected without another heartbeat
+	ValidTo time.Time `json:"validTo"`
+}
+
+// heartbeatStatuses are the statuses a heartbeat keeps connected. An IN-ACTIVE or removed host has
+// to register again, as its platform data may be outdated.
+var heartbeatStatuses = []string{constants.HostStatusConnected, constants.HostStatusStale}
+
+// heartbeatHost extends the expiry time of a registered host without pushing its platform data again
+func heartbeatHost(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/host_heartbeat: heartbeatHost() Entering")
+		defer log.Trace("resource/host_heartbeat: heartbeatHost() Leaving")
+
+		err := authorizeEndpoint(r, constants.HostDataUpdaterGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		id, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			slog.Errorf("resource/host_heartbeat: heartbeatHost() %s : Input validation failed for host Id", commLogMsg.InvalidInputBadParam)
+			return &resourceError{Message: "Invalid host Id", StatusCode: http.StatusBadRequest}
+		}
+
+		// an unknown host is rejected as a host of another token subject is, so that the response does not
+		// tell which host IDs exist
+		invalidToken := &resourceError{Message: "Invalid Token", StatusCode: http.StatusUnauthorized, Code: ErrorCodeTokenMismatch}
+		host, err := db.HostRepository().Retrieve(&types.Host{ID: id}, nil)
+		if host == nil || err != nil {
+			log.WithError(err).WithField("id", id).Info("heartbeatHost: attempt to heartbeat unknown host")
+			slog.Errorf("resource/host_heartbeat: heartbeatHost() %s : Failed to match host identity from token", commLogMsg.AuthenticationFailed)
+			return invalidToken
+		}
+
+		tokenSubject, err := context.GetTokenSubject(r)
+		if err != nil || !strings.EqualFold(host.HardwareUUID.String(), tokenSubject) {
+			slog.Errorf("resource/host_heartbeat: heartbeatHost() %s : Failed to match host identity from token", commLogMsg.AuthenticationFailed)
+			return invalidToken
+		}
+
+		conf := config.Global()
+		if conf == nil {
+			return errors.New("heartbeatHost: Configuration pointer is null")
+		}
+
+		validTo := time.Now().Add(hostExpiryDuration(conf, host.HardwareUUID))
+		extended, err := db.HostStatusRepository().ExtendExpiry(id, validTo, heartbeatStatuses)
+		if err != nil {
+			log.WithError(err).WithField("id", id).Error("heartbeatHost: Error while extending host expiry")
+			return &resourceError{Message: "Error while extending host expiry", StatusCode: http.StatusInternalServerError}
+		}
+		if !extended {
+			return &resourceError{Message: "Host is not connected, register the host again", StatusCode: http.StatusConflict,
+				Code: ErrorCodeHostNotConnected}
+		}
+
+		slog.Infof("%s: Host heartbeat received from: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return writeHeartbeatResponse(w, HostHeartbeatResponse{
+			ID:      id,
+			Status:  constants.HostStatusConnected,
+			ValidTo: validTo,
+		})
+	})
+}
+
+func writeHeartbeatResponse(w http.ResponseWriter, res HostHeartbeatResponse) error {
+	js, err := json.Marshal(res)
+	if err != nil {
+		return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(js)
+	if err != nil {
+		return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+	}
+	return nil
+}
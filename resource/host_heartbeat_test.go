@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/config"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HostHeartbeat", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	hostRepo := mock.MockHostRepository{}
+	hostStatusRepo := mock.MockHostStatusRepository{}
+	hostSgxRepo := mock.MockHostSgxDataRepository{}
+
+	db := mock.NewMockDatabase(hostRepo, hostStatusRepo, hostSgxRepo)
+
+	hostUpdaterRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.HostDataUpdaterGroupName,
+			Context: "type=SHVS",
+		},
+	}
+
+	createHost := func(status string) types.Host {
+		host := types.Host{
+			ID:           uuid.New(),
+			Name:         "heartbeat-" + status,
+			HardwareUUID: uuid.New(),
+			CreatedTime:  time.Now(),
+			UpdatedTime:  time.Now(),
+		}
+		_, _ = db.HostRepository().Create(&host)
+		// the mock repository looks up the status of a host by the id of the status
+		_, _ = db.HostStatusRepository().Create(&types.HostStatus{
+			ID:          host.ID,
+			HostID:      host.ID,
+			Status:      status,
+			CreatedTime: time.Now(),
+			UpdatedTime: time.Now(),
+			ExpiryTime:  time.Now().Add(-time.Minute),
+		})
+		return host
+	}
+	connectedHost := createHost(constants.HostStatusConnected)
+	staleHost := createHost(constants.HostStatusStale)
+	inactiveHost := createHost(constants.HostStatusInactive)
+
+	sendHeartbeat := func(id string, tokenSubject string) {
+		req, err := http.NewRequest(http.MethodPost, "/hosts/"+id+"/heartbeat", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req = context.SetUserRoles(req, hostUpdaterRoles)
+		req = context.SetTokenSubject(req, tokenSubject)
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	hostStatus := func(hostID uuid.UUID) *types.HostStatus {
+		status, err := db.HostStatusRepository().Retrieve(&types.HostStatus{HostID: hostID})
+		Expect(err).NotTo(HaveOccurred())
+		return status
+	}
+
+	conf := config.Global()
+	var expiryTime int
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		SGXHostRegisterOps(router, db)
+		expiryTime = conf.SHVSHostInfoExpiryTime
+		conf.SHVSHostInfoExpiryTime = 60
+	})
+
+	AfterEach(func() {
+		conf.SHVSHostInfoExpiryTime = expiryTime
+		conf.HostExpiryOverrides = nil
+	})
+
+	Describe("Heartbeat host", func() {
+		Context("Validate host heartbeat request", func() {
+			It("Should extend the expiry of a connected host", func() {
+				sendHeartbeat(connectedHost.ID.String(), connectedHost.HardwareUUID.String())
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var res HostHeartbeatResponse
+				err := json.Unmarshal(w.Body.Bytes(), &res)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.ID).To(Equal(connectedHost.ID))
+				Expect(res.ValidTo.After(time.Now())).To(BeTrue())
+				Expect(hostStatus(connectedHost.ID).ExpiryTime.After(time.Now())).To(BeTrue())
+			})
+
+			It("Should reconnect a stale host", func() {
+				sendHeartbeat(staleHost.ID.String(), staleHost.HardwareUUID.String())
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(hostStatus(staleHost.ID).Status).To(Equal(constants.HostStatusConnected))
+			})
+
+			It("Should apply the expiry override of the host", func() {
+				conf.HostExpiryOverrides = map[string]int{connectedHost.HardwareUUID.String(): 24 * 60}
+
+				sendHeartbeat(connectedHost.ID.String(), connectedHost.HardwareUUID.String())
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(hostStatus(connectedHost.ID).ExpiryTime.After(time.Now().Add(23 * time.Hour))).To(BeTrue())
+			})
+
+			It("Should not heartbeat host - Token subject does not match the host", func() {
+				sendHeartbeat(connectedHost.ID.String(), staleHost.HardwareUUID.String())
+				Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			})
+
+			It("Should not heartbeat host - Host is in-active", func() {
+				sendHeartbeat(inactiveHost.ID.String(), inactiveHost.HardwareUUID.String())
+				Expect(w.Code).To(Equal(http.StatusConflict))
+				Expect(hostStatus(inactiveHost.ID).Status).To(Equal(constants.HostStatusInactive))
+			})
+
+			It("Should not heartbeat host - Unknown host id was given", func() {
+				sendHeartbeat(uuid.New().String(), connectedHost.HardwareUUID.String())
+				// the same response as for a host of another token subject
+				Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			})
+
+			It("Should not heartbeat host - Invalid host id was given", func() {
+				sendHeartbeat("not-a-uuid", connectedHost.HardwareUUID.String())
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+})
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"intel/isecl/lib/common/v5/auth"
+	"intel/isecl/lib/common/v5/context"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	ct "intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+// hostScope restricts a role to the hosts carrying all of its labels and named after its hostname pattern,
+// such as *.acme.example. Either may be left empty.
+type hostScope struct {
+	labels   types.HostLabels
+	hostname string
+}
+
+func (s hostScope) matches(hostName string, labels types.HostLabels) bool {
+	for key, value := range s.labels {
+		if hostValue, ok := labels[key]; !ok || hostValue != value {
+			return false
+		}
+	}
+	if s.hostname != "" {
+		matched, err := path.Match(s.hostname, strings.ToLower(hostName))
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// hostScopes are the scopes of the roles of a request, a host is covered when any of them matches it.
+// nil scopes cover all hosts.
+type hostScopes []hostScope
+
+func (s hostScopes) allows(hostName string, labels types.HostLabels) bool {
+	if s == nil {
+		return true
+	}
+	for _, scope := range s {
+		if scope.matches(hostName, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsID tells whether the host of the ID is covered, the host being looked up for scoped roles only. Hosts
+// which cannot be read are not covered.
+func (s hostScopes) allowsID(db repository.SHVSDatabase, hostID uuid.UUID) bool {
+	if s == nil {
+		return true
+	}
+	host, err := db.HostRepository().Retrieve(&types.Host{ID: hostID}, nil)
+	if host == nil || err != nil {
+		return false
+	}
+	return s.allows(host.Name, host.Labels)
+}
+
+// hostIDsInScope are the IDs of the hosts covered by the scopes of a request, nil covering all hosts
+type hostIDsInScope map[uuid.UUID]bool
+
+func (ids hostIDsInScope) allows(hostID uuid.UUID) bool {
+	return ids == nil || ids[hostID]
+}
+
+// hostIDs reads the hosts covered by the scopes in a single query, so that listing the data of many hosts
+// does not look up each host in turn as allowsID does. Nothing is read for nil scopes.
+func (s hostScopes) hostIDs(db repository.SHVSDatabase) (hostIDsInScope, error) {
+	if s == nil {
+		return nil, nil
+	}
+	ids := hostIDsInScope{}
+	err := db.HostRepository().StreamHostInventory(&types.Host{}, nil, func(host *types.HostInventory) error {
+		if s.allows(host.HostName, host.Labels) {
+			ids[host.HostID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "hostIDs: Error while retrieving the hosts in scope")
+	}
+	return ids, nil
+}
+
+// parseHostScope reads the scope of a role context made of ;-separated clauses, such as
+// type=SHVS;labels=tenant:acme,env:prod;hostname=*.acme.example. It returns false when the context has
+// neither a labels nor a hostname clause, the role then covering all hosts.
+func parseHostScope(roleContext string) (hostScope, bool, error) {
+	var scope hostScope
+	scoped := false
+	for _, clause := range strings.Split(roleContext, ";") {
+		name, value, found := strings.Cut(strings.TrimSpace(clause), "=")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case constants.RoleContextLabels:
+			scoped = true
+			scope.labels = types.HostLabels{}
+			for _, label := range strings.Split(value, ",") {
+				key, labelValue, found := strings.Cut(strings.TrimSpace(label), ":")
+				if !found || !validateInputString(constants.LabelKey, key) ||
+					!validateInputString(constants.LabelValue, labelValue) {
+					return hostScope{}, false, errors.Errorf("invalid label %q", label)
+				}
+				scope.labels[key] = labelValue
+			}
+		case constants.RoleContextHostname:
+			scoped = true
+			scope.hostname = strings.ToLower(strings.TrimSpace(value))
+			if _, err := path.Match(scope.hostname, ""); err != nil || scope.hostname == "" {
+				return hostScope{}, false, errors.Errorf("invalid hostname pattern %q", value)
+			}
+		}
+	}
+	return scope, scoped, nil
+}
+
+// authorizeHostEndpoint authorizes the request as authorizeEndpoint does and returns the scopes of the
+// matching roles. A role without scope covers all hosts, while a role with an invalid scope covers none.
+func authorizeHostEndpoint(r *http.Request, roleName string) (hostScopes, error) {
+	log.Trace("resource/host_scope:authorizeHostEndpoint() Entering")
+	defer log.Trace("resource/host_scope:authorizeHostEndpoint() Leaving")
+
+	err := authorizeEndpoint(r, roleName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	privileges, _ := context.GetUserRoles(r)
+	roleContexts, _ := auth.ValidatePermissionAndGetRoleContext(privileges,
+		[]ct.RoleInfo{{Service: constants.ServiceName, Name: roleName}}, true)
+	if roleContexts == nil {
+		return nil, nil
+	}
+	scopes := hostScopes{}
+	for roleContext := range *roleContexts {
+		scope, scoped, err := parseHostScope(roleContext)
+		if err != nil {
+			requestLog(r, slog).WithError(err).Warnf("resource/host_scope: authorizeHostEndpoint() %s: Ignoring role %s with context %q",
+				commLogMsg.InvalidInputBadParam, roleName, roleContext)
+			continue
+		}
+		if !scoped {
+			return nil, nil
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
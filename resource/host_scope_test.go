@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HostScope", func() {
+	Describe("Parse role contexts", func() {
+		It("Should parse the labels and the hostname of a role context", func() {
+			scope, scoped, err := parseHostScope("type=SHVS;labels=tenant:acme,env:prod;hostname=*.Acme.example")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scoped).To(BeTrue())
+			Expect(scope).To(Equal(hostScope{labels: types.HostLabels{"tenant": "acme", "env": "prod"},
+				hostname: "*.acme.example"}))
+
+			Expect(scope.matches("node1.acme.example", types.HostLabels{"tenant": "acme", "env": "prod", "rack": "4"})).To(BeTrue())
+			Expect(scope.matches("node1.acme.example", types.HostLabels{"tenant": "acme"})).To(BeFalse())
+			Expect(scope.matches("node1.other.example", types.HostLabels{"tenant": "acme", "env": "prod"})).To(BeFalse())
+		})
+
+		It("Should not scope a role context without labels or hostname", func() {
+			_, scoped, err := parseHostScope("type=SHVS")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scoped).To(BeFalse())
+		})
+
+		It("Should not parse invalid scopes", func() {
+			_, _, err := parseHostScope("labels=tenant")
+			Expect(err).To(HaveOccurred())
+			_, _, err = parseHostScope("hostname=[acme")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Scope hosts", func() {
+		var router *mux.Router
+		var w *httptest.ResponseRecorder
+		db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+
+		acmeHost := types.Host{ID: uuid.New(), Name: "node1.acme.example", HardwareUUID: uuid.New(),
+			Labels: types.HostLabels{"tenant": "acme"}, CreatedTime: time.Now(), UpdatedTime: time.Now()}
+		otherHost := types.Host{ID: uuid.New(), Name: "node1.other.example", HardwareUUID: uuid.New(),
+			Labels: types.HostLabels{"tenant": "other"}, CreatedTime: time.Now(), UpdatedTime: time.Now()}
+		for _, host := range []*types.Host{&acmeHost, &otherHost} {
+			created, _ := db.HostRepository().Create(host)
+			_, _ = db.HostStatusRepository().Create(&types.HostStatus{ID: created.ID, HostID: created.ID,
+				Status: constants.HostStatusConnected, CreatedTime: time.Now(), UpdatedTime: time.Now(),
+				ExpiryTime: time.Now().Add(time.Hour)})
+			_, _ = db.HostSgxDataRepository().Create(&types.HostSgxData{ID: uuid.New(), HostID: created.ID,
+				SgxSupported: true, SgxEnabled: true, CreatedTime: time.Now()})
+		}
+
+		sendRequest := func(method, path, accept, roleName, roleContext string) {
+			req, err := http.NewRequest(method, path, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req = context.SetUserRoles(req, []aas.RoleInfo{
+				{
+					Service: constants.ServiceName,
+					Name:    roleName,
+					Context: roleContext,
+				},
+			})
+			req.Header.Set("Accept", accept)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+		}
+
+		registerHost := func(host types.Host, roleContext string, labels types.HostLabels) {
+			body, _ := json.Marshal(SGXHostInfo{HostName: host.Name, UUID: host.HardwareUUID.String(),
+				SgxSupported: true, Labels: labels})
+			req, err := http.NewRequest(http.MethodPost, "/hosts", bytes.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			req = context.SetUserRoles(req, []aas.RoleInfo{
+				{
+					Service: constants.ServiceName,
+					Name:    constants.HostDataUpdaterGroupName,
+					Context: roleContext,
+				},
+			})
+			req = context.SetTokenSubject(req, host.HardwareUUID.String())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+		}
+
+		BeforeEach(func() {
+			router = mux.NewRouter()
+			SGXHostRegisterOps(router, db)
+			ComplianceReportOps(router, db)
+		})
+
+		It("Should list the hosts in scope only", func() {
+			sendRequest(http.MethodGet, "/hosts", constants.HTTPMediaTypeCSV, constants.HostListReaderGroupName,
+				"type=SHVS;labels=tenant:acme")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(acmeHost.Name))
+			Expect(w.Body.String()).NotTo(ContainSubstring(otherHost.Name))
+
+			sendRequest(http.MethodGet, "/hosts", constants.HTTPMediaTypeCSV, constants.HostListReaderGroupName, "type=SHVS")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(strings.Count(w.Body.String(), "\n")).To(Equal(3))
+		})
+
+		It("Should not read a host out of scope", func() {
+			sendRequest(http.MethodGet, "/hosts/"+otherHost.ID.String(), consts.HTTPMediaTypeJson,
+				constants.HostListReaderGroupName, "hostname=*.acme.example")
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+
+			sendRequest(http.MethodGet, "/hosts/"+acmeHost.ID.String(), consts.HTTPMediaTypeJson,
+				constants.HostListReaderGroupName, "hostname=*.acme.example")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"labels":{"tenant":"acme"}`))
+		})
+
+		It("Should not read any host with an invalid scope", func() {
+			sendRequest(http.MethodGet, "/hosts/"+acmeHost.ID.String(), consts.HTTPMediaTypeJson,
+				constants.HostListReaderGroupName, "labels=tenant")
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("Should return the platform data of the hosts in scope only", func() {
+			for _, accept := range []string{consts.HTTPMediaTypeJson, constants.HTTPMediaTypeNDJSON} {
+				sendRequest(http.MethodGet, "/platform-data", accept, constants.HostDataReaderGroupName,
+					"labels=tenant:acme")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).To(ContainSubstring(acmeHost.ID.String()))
+				Expect(w.Body.String()).NotTo(ContainSubstring(otherHost.ID.String()))
+			}
+
+			sendRequest(http.MethodGet, "/platform-data?HostName="+otherHost.Name, consts.HTTPMediaTypeJson,
+				constants.HostDataReaderGroupName, "labels=tenant:acme")
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("Should read the hosts in scope at once", func() {
+			scope, _, err := parseHostScope("labels=tenant:acme")
+			Expect(err).NotTo(HaveOccurred())
+			hostIDs, err := hostScopes{scope}.hostIDs(db)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hostIDs.allows(acmeHost.ID)).To(BeTrue())
+			Expect(hostIDs.allows(otherHost.ID)).To(BeFalse())
+
+			// nothing is read without scopes, all hosts being covered
+			hostIDs, err = hostScopes(nil).hostIDs(db)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hostIDs).To(BeNil())
+			Expect(hostIDs.allows(otherHost.ID)).To(BeTrue())
+		})
+
+		It("Should not return the status of a host out of scope", func() {
+			sendRequest(http.MethodGet, "/host-status?hostId="+otherHost.ID.String(), consts.HTTPMediaTypeJson,
+				constants.HostDataReaderGroupName, "hostname=*.acme.example")
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+			sendRequest(http.MethodGet, "/host-status?hostId="+acmeHost.ID.String(), consts.HTTPMediaTypeJson,
+				constants.HostDataReaderGroupName, "hostname=*.acme.example")
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Should not return the compliance reports of all hosts to a scoped role", func() {
+			sendRequest(http.MethodGet, "/reports", consts.HTTPMediaTypeJson, constants.HostListReaderGroupName,
+				"labels=tenant:acme")
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+
+			sendRequest(http.MethodGet, "/reports/"+uuid.New().String(), consts.HTTPMediaTypeJson,
+				constants.HostListReaderGroupName, "labels=tenant:acme")
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("Should keep the labels of a host registered without labels", func() {
+			registerHost(acmeHost, "labels=tenant:acme", nil)
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Should not register a host labelled out of the scope of the agent", func() {
+			registerHost(acmeHost, "labels=tenant:acme", types.HostLabels{"tenant": "other"})
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+
+			registerHost(otherHost, "type=SHVS", types.HostLabels{"tenant": "other"})
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Should not delete a host out of scope", func() {
+			sendRequest(http.MethodDelete, "/hosts/"+otherHost.ID.String(), consts.HTTPMediaTypeJson,
+				constants.HostListManagerGroupName, "labels=tenant:acme")
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		})
+	})
+})
@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/lib/common/v5/crypt"
+	jwtauth "intel/isecl/lib/common/v5/jwt"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+type verdictSigner struct {
+	factory  *jwtauth.JwtFactory
+	certPem  []byte
+	validity time.Duration
+}
+
+var hostVerdictSigner *verdictSigner
+
+// InitVerdictSigner loads the key and certificate provisioned by the download_cert_signing setup
+// task. Verdicts are valid for the given duration or until the host platform data expires, whichever
+// comes first.
+func InitVerdictSigner(keyFile, certFile string, includeKid bool, validity time.Duration) error {
+	log.Trace("resource/host_verdict: InitVerdictSigner() Entering")
+	defer log.Trace("resource/host_verdict: InitVerdictSigner() Leaving")
+
+	keyDer, err := crypt.GetPKCS8PrivKeyDerFromFile(keyFile)
+	if err != nil {
+		return errors.Wrap(err, "InitVerdictSigner: Error while reading signing key")
+	}
+	certPem, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return errors.Wrap(err, "InitVerdictSigner: Error while reading signing certificate")
+	}
+	if validity <= 0 {
+		validity = constants.DefaultVerdictValidityMins * time.Minute
+	}
+	factory, err := jwtauth.NewTokenFactory(keyDer, includeKid, certPem, constants.VerdictTokenIssuer, validity)
+	if err != nil {
+		return errors.Wrap(err, "InitVerdictSigner: Error while creating verdict token factory")
+	}
+	hostVerdictSigner = &verdictSigner{factory: factory, certPem: certPem, validity: validity}
+	return nil
+}
+
+func HostVerdictOps(r *mux.Router, db repository.SHVSDatabase) {
+	log.Trace("resource/host_verdict: HostVerdictOps() Entering")
+	defer log.Trace("resource/host_verdict: HostVerdictOps() Leaving")
+
+	r.Handle("/hosts/{id}/verdict", getHostVerdict(db)).Methods("GET")
+	r.Handle("/verdict-signing-certificate", getVerdictSigningCertificate()).Methods("GET")
+}
+
+func getHostVerdict(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/host_verdict: getHostVerdict() Entering")
+		defer log.Trace("resource/host_verdict: getHostVerdict() Leaving")
+
+		scopes, err := authorizeHostEndpoint(r, constants.HostDataReaderGroupName)
+		if err != nil {
+			return err
+		}
+
+		if hostVerdictSigner == nil {
+			log.Error("resource/host_verdict: getHostVerdict() verdict signing key is not configured")
+			return &resourceError{Message: "Host verdict signing is not configured", StatusCode: http.StatusServiceUnavailable}
+		}
+
+		id, validationErr := uuid.Parse(mux.Vars(r)["id"])
+		if validationErr != nil {
+			slog.Errorf("resource/host_verdict: getHostVerdict() Input validation failed for host ID")
+			return &resourceError{Message: validationErr.Error(), StatusCode: http.StatusBadRequest}
+		}
+
+		if len(r.URL.Query()) != 0 {
+			slog.Errorf("resource/host_verdict: getHostVerdict() %s", commLogMsg.InvalidInputBadParam)
+			return &resourceError{Message: "Invalid query parameters provided", StatusCode: http.StatusBadRequest}
+		}
+
+		host, err := db.HostRepository().Retrieve(&types.Host{ID: id}, nil)
+		if host == nil || err != nil {
+			log.WithError(err).WithField("id", id).Info("attempt to fetch verdict of invalid host")
+			return &resourceError{Message: "Host with given id don't exist", StatusCode: http.StatusNotFound}
+		}
+		if !scopes.allows(host.Name, host.Labels) {
+			slog.Infof("%s: Host %s out of the scope of the roles of: %s", commLogMsg.UnauthorizedAccess, id, r.RemoteAddr)
+			return &resourceError{Message: "Host with given id don't exist", StatusCode: http.StatusNotFound}
+		}
+
+		hostStatus, err := db.HostStatusRepository().RetrieveNonExpiredHost(&types.HostStatus{HostID: id})
+		if hostStatus == nil || err != nil || !hostStatus.ExpiryTime.After(time.Now()) {
+			log.WithError(err).WithField("id", id).Info("host has no valid platform data")
+			return &resourceError{Message: "Host with given id has no valid platform data", StatusCode: http.StatusNotFound}
+		}
+
+		sgxData, err := db.HostSgxDataRepository().Retrieve(&types.HostSgxData{HostID: id})
+		if sgxData == nil || err != nil {
+			log.WithError(err).WithField("id", id).Info("host has no platform data")
+			return &resourceError{Message: "Host with given id has no valid platform data", StatusCode: http.StatusNotFound}
+		}
+
+		verdict := types.HostVerdict{
+			HostID:       host.ID,
+			HostName:     host.Name,
+			HardwareUUID: host.HardwareUUID,
+			SgxSupported: sgxData.SgxSupported,
+			SgxEnabled:   sgxData.SgxEnabled,
+			FlcEnabled:   sgxData.FlcEnabled,
+			EpcSize:      sgxData.EpcSize,
+			TcbUptodate:  sgxData.TcbUptodate,
+			ValidTo:      hostStatus.ExpiryTime,
+		}
+		policyVerdict, err := db.HostPolicyVerdictRepository().Retrieve(&types.HostPolicyVerdict{HostID: id})
+		if policyVerdict != nil && err == nil {
+			verdict.Compliant = &policyVerdict.Compliant
+		}
+
+		validity := hostVerdictSigner.validity
+		if remaining := time.Until(hostStatus.ExpiryTime); remaining < validity {
+			validity = remaining
+		}
+		token, err := hostVerdictSigner.factory.Create(&verdict, id.String(), validity)
+		if err != nil {
+			log.WithError(err).Error("resource/host_verdict: getHostVerdict() failed to sign host verdict")
+			return &resourceError{Message: "Failed to sign host verdict", StatusCode: http.StatusInternalServerError}
+		}
+
+		w.Header().Set("Content-Type", constants.HTTPMediaTypeJWT)
+		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write([]byte(token))
+		if err != nil {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		slog.Infof("%s: Host verdict retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return nil
+	})
+}
+
+func getVerdictSigningCertificate() errorHandlerFunc {
+	return withRequest(nil, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, _ repository.SHVSDatabase) error {
+		log.Trace("resource/host_verdict: getVerdictSigningCertificate() Entering")
+		defer log.Trace("resource/host_verdict: getVerdictSigningCertificate() Leaving")
+
+		err := authorizeEndpoint(r, constants.HostDataReaderGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		if hostVerdictSigner == nil {
+			log.Error("resource/host_verdict: getVerdictSigningCertificate() verdict signing key is not configured")
+			return &resourceError{Message: "Host verdict signing is not configured", StatusCode: http.StatusServiceUnavailable}
+		}
+
+		w.Header().Set("Content-Type", constants.HTTPMediaTypePemFile)
+		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(hostVerdictSigner.certPem)
+		if err != nil {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		slog.Infof("%s: Verdict signing certificate retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return nil
+	})
+}
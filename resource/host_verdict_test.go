@@ -0,0 +1,219 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/crypt"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func createVerdictSigningKey() (string, string) {
+	dir, err := ioutil.TempDir("", "shvs-verdict")
+	Expect(err).NotTo(HaveOccurred())
+	certDer, keyDer, err := crypt.CreateKeyPairAndCertificate("SHVS Signing Certificate", "",
+		constants.DefaultKeyAlgorithm, constants.DefaultKeyAlgorithmLength)
+	Expect(err).NotTo(HaveOccurred())
+
+	keyFile := filepath.Join(dir, "signing.key")
+	certFile := filepath.Join(dir, "signing-cert.pem")
+	Expect(crypt.SavePrivateKeyAsPKCS8(keyDer, keyFile)).To(Succeed())
+	Expect(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0600)).To(Succeed())
+	return keyFile, certFile
+}
+
+var _ = Describe("HostVerdict", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	hostRepo := mock.MockHostRepository{}
+	hostStatusRepo := mock.MockHostStatusRepository{}
+	hostSgxRepo := mock.MockHostSgxDataRepository{}
+
+	db := mock.NewMockDatabase(hostRepo, hostStatusRepo, hostSgxRepo)
+	keyFile, certFile := createVerdictSigningKey()
+
+	connectedHost := types.Host{
+		ID:           uuid.New(),
+		Name:         "verdict-host",
+		HardwareUUID: uuid.New(),
+		CreatedTime:  time.Now(),
+	}
+	db.HostRepository().Create(&connectedHost)
+	// the mock repositories expire a host status at its update time and look platform data up by ID only
+	db.HostStatusRepository().Create(&types.HostStatus{
+		ID:          uuid.New(),
+		HostID:      connectedHost.ID,
+		Status:      constants.HostStatusConnected,
+		CreatedTime: time.Now(),
+		UpdatedTime: time.Now().Add(1 * time.Hour),
+	})
+	db.HostSgxDataRepository().Create(&types.HostSgxData{
+		HostID:       connectedHost.ID,
+		SgxSupported: true,
+		SgxEnabled:   true,
+		FlcEnabled:   true,
+		EpcSize:      "2.0 GB",
+		TcbUptodate:  true,
+		CreatedTime:  time.Now(),
+	})
+
+	expiredHost := types.Host{
+		ID:           uuid.New(),
+		Name:         "expired-host",
+		HardwareUUID: uuid.New(),
+		CreatedTime:  time.Now(),
+	}
+	db.HostRepository().Create(&expiredHost)
+	db.HostStatusRepository().Create(&types.HostStatus{
+		ID:          uuid.New(),
+		HostID:      expiredHost.ID,
+		Status:      constants.HostStatusConnected,
+		CreatedTime: time.Now(),
+		UpdatedTime: time.Now().Add(-1 * time.Minute),
+	})
+
+	verdictReaderRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.HostDataReaderGroupName,
+			Context: "type=SHVS",
+		},
+	}
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		Expect(InitVerdictSigner(keyFile, certFile, true, 5*time.Minute)).To(Succeed())
+	})
+
+	Describe("Get host verdict", func() {
+		Context("Validate get host verdict request", func() {
+			It("Should not get host verdict - Insufficient roles were given", func() {
+				HostVerdictOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts/"+connectedHost.ID.String()+"/verdict", nil)
+				val := []aas.RoleInfo{
+					{
+						Service: constants.ServiceName,
+						Name:    constants.HostListReaderGroupName,
+						Context: "type=SHVS",
+					},
+				}
+				req = context.SetUserRoles(req, val)
+
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+			})
+
+			It("Should not get host verdict - Signing key is not configured", func() {
+				hostVerdictSigner = nil
+				HostVerdictOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts/"+connectedHost.ID.String()+"/verdict", nil)
+				req = context.SetUserRoles(req, verdictReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+			})
+
+			It("Should not get host verdict - Host does not exist", func() {
+				HostVerdictOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts/"+uuid.New().String()+"/verdict", nil)
+				req = context.SetUserRoles(req, verdictReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+
+			It("Should not get host verdict - Platform data has expired", func() {
+				HostVerdictOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts/"+expiredHost.ID.String()+"/verdict", nil)
+				req = context.SetUserRoles(req, verdictReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+
+			It("Should get signed host verdict", func() {
+				HostVerdictOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts/"+connectedHost.ID.String()+"/verdict", nil)
+				req = context.SetUserRoles(req, verdictReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeJWT))
+
+				parts := strings.Split(w.Body.String(), ".")
+				Expect(parts).To(HaveLen(3))
+				payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+				Expect(err).NotTo(HaveOccurred())
+
+				var claims map[string]interface{}
+				Expect(json.Unmarshal(payload, &claims)).To(Succeed())
+				Expect(claims["sub"]).To(Equal(connectedHost.ID.String()))
+				Expect(claims["iss"]).To(Equal(constants.VerdictTokenIssuer))
+				Expect(claims["uuid"]).To(Equal(connectedHost.HardwareUUID.String()))
+				Expect(claims["sgx_enabled"]).To(Equal(true))
+				Expect(claims["tcb_upToDate"]).To(Equal(true))
+				Expect(claims).To(HaveKey("validTo"))
+				Expect(claims["exp"]).To(BeNumerically("<=", float64(time.Now().Add(5*time.Minute).Unix())))
+			})
+		})
+	})
+
+	Describe("Get verdict signing certificate", func() {
+		Context("Validate get verdict signing certificate request", func() {
+			It("Should get verdict signing certificate", func() {
+				HostVerdictOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/verdict-signing-certificate", nil)
+				req = context.SetUserRoles(req, verdictReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				certPem, err := ioutil.ReadFile(certFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w.Body.String()).To(Equal(string(certPem)))
+			})
+
+			It("Should not load verdict signer - Signing key does not exist", func() {
+				err := InitVerdictSigner(filepath.Join(filepath.Dir(keyFile), "missing.key"), certFile, true, 5*time.Minute)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+var hostsCSVHeader = []string{"host_name", "hardware_uuid", "status", "sgx_supported", "sgx_enabled",
+	"flc_enabled", "tcb_upToDate", "epc_size", "updated_time", constants.ExpiryTimeKeyName}
+
+// writeHostsCSV streams the inventory of the hosts matching filter and the scopes as a CSV document
+func writeHostsCSV(w http.ResponseWriter, r *http.Request, db repository.SHVSDatabase, filter *types.Host,
+	criteria *types.HostInfoFetchCriteria, scopes hostScopes) error {
+	log.Trace("resource/hosts_csv: writeHostsCSV() Entering")
+	defer log.Trace("resource/hosts_csv: writeHostsCSV() Leaving")
+
+	cw := csv.NewWriter(w)
+	flusher, _ := w.(http.Flusher)
+	rows := 0
+	err := db.HostRepository().StreamHostInventory(filter, criteria, func(host *types.HostInventory) error {
+		if !scopes.allows(host.HostName, host.Labels) {
+			return nil
+		}
+		if rows == 0 {
+			w.Header().Set("Content-Type", constants.HTTPMediaTypeCSV)
+			w.Header().Set("Content-Disposition", "attachment; filename=\""+constants.HostsCSVFileName+"\"")
+			w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+			w.WriteHeader(http.StatusOK)
+			if err := cw.Write(hostsCSVHeader); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(hostInventoryCSVRecord(host)); err != nil {
+			return err
+		}
+		rows++
+		if rows%constants.StreamFlushRowCount == 0 {
+			cw.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		log.WithError(err).WithField("filter", filter).Info("failed to export hosts as csv")
+		if rows == 0 {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		return nil
+	}
+	if rows == 0 {
+		log.Error("resource/hosts_csv: writeHostsCSV() no data is found")
+		return &resourceError{Message: "no host is found", StatusCode: http.StatusNotFound}
+	}
+	slog.Infof("%s: Host inventory exported by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return nil
+}
+
+func hostInventoryCSVRecord(host *types.HostInventory) []string {
+	return []string{
+		csvText(host.HostName),
+		host.HardwareUUID.String(),
+		csvText(valueOrEmpty(host.Status)),
+		csvBool(host.SgxSupported),
+		csvBool(host.SgxEnabled),
+		csvBool(host.FlcEnabled),
+		csvBool(host.TcbUptodate),
+		csvText(valueOrEmpty(host.EpcSize)),
+		csvTime(host.UpdatedTime),
+		csvTime(host.ExpiryTime),
+	}
+}
+
+// csvText keeps spreadsheets from evaluating a text field as a formula, prefixing it with a quote when it
+// starts with one of the formula characters
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func csvBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
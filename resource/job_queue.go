@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+// QueueJob stores a job of the given type in the durable job queue, where it is picked up by the job
+// dispatcher of any SHVS instance
+func QueueJob(db repository.SHVSDatabase, jobType string, payload interface{}) (*types.Job, error) {
+	log.Trace("resource/job_queue: QueueJob() Entering")
+	defer log.Trace("resource/job_queue: QueueJob() Leaving")
+
+	job, err := newJob(jobType, payload)
+	if err != nil {
+		return nil, err
+	}
+	createdJob, err := db.JobRepository().Create(job)
+	if err != nil {
+		return nil, errors.Wrap(err, "QueueJob: Error while storing job")
+	}
+	log.Debugf("resource/job_queue: job %s of type %s queued", createdJob.ID, jobType)
+	return createdJob, nil
+}
+
+func newJob(jobType string, payload interface{}) (*types.Job, error) {
+	var data types.JobPayload
+	if payload != nil {
+		js, err := json.Marshal(payload)
+		if err != nil {
+			return nil, errors.Wrap(err, "QueueJob: Error while marshalling job payload")
+		}
+		data = js
+	}
+
+	now := time.Now()
+	return &types.Job{
+		ID:          uuid.New(),
+		Type:        jobType,
+		Status:      constants.JobStatusQueued,
+		MaxAttempts: constants.DefaultJobMaxAttempts,
+		Payload:     data,
+		NextRunTime: now,
+		CreatedTime: now,
+		UpdatedTime: now,
+	}, nil
+}
+
+// queuePolicyReevaluation queues the reevaluation of all hosts unless one is already waiting to run,
+// in which case that job picks up the latest policies
+func queuePolicyReevaluation(db repository.SHVSDatabase) error {
+	return queueJobOnce(db, constants.JobTypePolicyReevaluation)
+}
+
+// QueueComplianceReport queues the generation of a compliance report unless one is already waiting to run
+func QueueComplianceReport(db repository.SHVSDatabase) error {
+	return queueJobOnce(db, constants.JobTypeComplianceReport)
+}
+
+// queueJobOnce queues a job of the given type unless one is already queued, which the job queue checks
+// as it stores the job so that concurrent callers do not queue it twice
+func queueJobOnce(db repository.SHVSDatabase, jobType string) error {
+	job, err := newJob(jobType, nil)
+	if err != nil {
+		return err
+	}
+	queued, err := db.JobRepository().CreateUnlessQueued(job)
+	if err != nil {
+		return errors.Wrap(err, "queueJobOnce: Error while storing job")
+	}
+	if queued {
+		log.Debugf("resource/job_queue: job %s of type %s queued", job.ID, jobType)
+	}
+	return nil
+}
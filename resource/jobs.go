@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+var jobsRetrieveParams = map[string]bool{"status": true, "type": true, "limit": true}
+
+var jobStatuses = map[string]bool{
+	constants.JobStatusQueued:     true,
+	constants.JobStatusProcessing: true,
+	constants.JobStatusCompleted:  true,
+	constants.JobStatusError:      true,
+	constants.JobStatusCancelled:  true,
+}
+
+// jobCanceller stops a job running on this instance at once, the other instances stop theirs when they
+// next poll the job queue
+var jobCanceller func(id uuid.UUID)
+
+// SetJobCanceller sets the function stopping a job running on this instance
+func SetJobCanceller(cancel func(id uuid.UUID)) {
+	jobCanceller = cancel
+}
+
+func JobOps(r *mux.Router, db repository.SHVSDatabase) {
+	log.Trace("resource/jobs: JobOps() Entering")
+	defer log.Trace("resource/jobs: JobOps() Leaving")
+
+	r.Handle("/jobs", queryJobs(db)).Methods("GET")
+	r.Handle("/jobs/{id}", getJob(db)).Methods("GET")
+	r.Handle("/jobs/{id}/retry", audited(constants.AuditActionJobRetry, db, retryJob(db))).Methods("POST")
+	r.Handle("/jobs/{id}/cancel", audited(constants.AuditActionJobCancel, db, cancelJob(db))).Methods("POST")
+}
+
+func queryJobs(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/jobs: queryJobs() Entering")
+		defer log.Trace("resource/jobs: queryJobs() Leaving")
+
+		err := authorizeEndpoint(r, constants.JobAdminGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		if err := validateQueryParams(r.URL.Query(), jobsRetrieveParams); err != nil {
+			slog.WithError(err).Errorf("resource/jobs: queryJobs() %s", commLogMsg.InvalidInputBadParam)
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+		}
+
+		filter := types.Job{
+			Status: r.URL.Query().Get("status"),
+			Type:   r.URL.Query().Get("type"),
+		}
+		if filter.Status != "" && !jobStatuses[filter.Status] {
+			slog.Errorf("resource/jobs: queryJobs() %s : Invalid job status", commLogMsg.InvalidInputBadParam)
+			return &resourceError{Message: "Invalid job status provided", StatusCode: http.StatusBadRequest}
+		}
+		if filter.Type != "" && !validateInputString(constants.JobType, filter.Type) {
+			slog.Errorf("resource/jobs: queryJobs() %s : Invalid job type", commLogMsg.InvalidInputBadParam)
+			return &resourceError{Message: "Invalid job type provided", StatusCode: http.StatusBadRequest}
+		}
+
+		limit := constants.DefaultJobsLimit
+		if r.URL.Query().Get("limit") != "" {
+			limit, err = strconv.Atoi(r.URL.Query().Get("limit"))
+			if err != nil || limit <= 0 || limit > constants.MaxJobsLimit {
+				slog.Errorf("resource/jobs: queryJobs() %s : Invalid limit", commLogMsg.InvalidInputBadParam)
+				return &resourceError{Message: "limit must be between 1 and " + strconv.Itoa(constants.MaxJobsLimit),
+					StatusCode: http.StatusBadRequest}
+			}
+		}
+
+		jobs, err := db.JobRepository().RetrieveAll(&filter, limit)
+		if err != nil {
+			log.WithError(err).Info("failed to retrieve jobs")
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		if jobs == nil {
+			jobs = types.Jobs{}
+		}
+		slog.Infof("%s: Jobs retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return writeJobResponse(w, http.StatusOK, jobs)
+	})
+}
+
+func getJob(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/jobs: getJob() Entering")
+		defer log.Trace("resource/jobs: getJob() Leaving")
+
+		err := authorizeEndpoint(r, constants.JobAdminGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		job, err := retrieveJobFromPath(r, db)
+		if err != nil {
+			return err
+		}
+		slog.Infof("%s: Job retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return writeJobResponse(w, http.StatusOK, job)
+	})
+}
+
+// retryJob queues a failed or cancelled job again with a fresh set of attempts
+func retryJob(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/jobs: retryJob() Entering")
+		defer log.Trace("resource/jobs: retryJob() Leaving")
+
+		err := authorizeEndpoint(r, constants.JobAdminGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		job, err := retrieveJobFromPath(r, db)
+		if err != nil {
+			return err
+		}
+		if job.Status != constants.JobStatusError && job.Status != constants.JobStatusCancelled {
+			slog.Errorf("resource/jobs: retryJob() Job %s in status %s can not be retried", job.ID, job.Status)
+			return &resourceError{Message: "Only failed or cancelled jobs can be retried", StatusCode: http.StatusConflict}
+		}
+
+		now := time.Now()
+		retried := *job
+		retried.Status = constants.JobStatusQueued
+		retried.Attempts = 0
+		retried.Result = nil
+		retried.Owner = ""
+		retried.NextRunTime = now
+		retried.StartTime = nil
+		retried.EndTime = nil
+		retried.UpdatedTime = now
+		err = swapJob(db, job, &retried)
+		if err != nil {
+			return err
+		}
+		slog.Infof("%s: Job %s queued again by: %s", commLogMsg.AuthorizedAccess, job.ID, r.RemoteAddr)
+		return writeJobResponse(w, http.StatusOK, &retried)
+	})
+}
+
+// cancelJob cancels a queued job, or a processing job which is then stopped by the dispatcher running it
+func cancelJob(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/jobs: cancelJob() Entering")
+		defer log.Trace("resource/jobs: cancelJob() Leaving")
+
+		err := authorizeEndpoint(r, constants.JobAdminGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		job, err := retrieveJobFromPath(r, db)
+		if err != nil {
+			return err
+		}
+		if job.Status != constants.JobStatusQueued && job.Status != constants.JobStatusProcessing {
+			slog.Errorf("resource/jobs: cancelJob() Job %s in status %s can not be cancelled", job.ID, job.Status)
+			return &resourceError{Message: "Only queued or processing jobs can be cancelled", StatusCode: http.StatusConflict}
+		}
+
+		now := time.Now()
+		cancelled := *job
+		cancelled.Status = constants.JobStatusCancelled
+		cancelled.EndTime = &now
+		cancelled.UpdatedTime = now
+		err = swapJob(db, job, &cancelled)
+		if err != nil {
+			return err
+		}
+		if jobCanceller != nil && job.Status == constants.JobStatusProcessing {
+			jobCanceller(job.ID)
+		}
+		slog.Infof("%s: Job %s cancelled by: %s", commLogMsg.AuthorizedAccess, job.ID, r.RemoteAddr)
+		return writeJobResponse(w, http.StatusOK, &cancelled)
+	})
+}
+
+func swapJob(db repository.SHVSDatabase, old, updated *types.Job) error {
+	swapped, err := db.JobRepository().CompareAndSwap(old, updated)
+	if err != nil {
+		log.WithError(err).Info("resource/jobs: swapJob() failed to update job")
+		return &resourceError{Message: "Failed to update job", StatusCode: http.StatusInternalServerError}
+	}
+	if !swapped {
+		slog.Errorf("resource/jobs: swapJob() Job %s changed while being updated", old.ID)
+		return &resourceError{Message: "Job status changed, please retry", StatusCode: http.StatusConflict}
+	}
+	return nil
+}
+
+func retrieveJobFromPath(r *http.Request, db repository.SHVSDatabase) (*types.Job, error) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		slog.Errorf("resource/jobs: retrieveJobFromPath() Input validation failed for job ID")
+		return nil, &resourceError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+	}
+
+	job, err := db.JobRepository().Retrieve(&types.Job{ID: id})
+	if job == nil || err != nil {
+		log.WithError(err).WithField("id", id).Info("attempt to fetch invalid job")
+		return nil, &resourceError{Message: "Job with given id don't exist", StatusCode: http.StatusNotFound}
+	}
+	return job, nil
+}
+
+func writeJobResponse(w http.ResponseWriter, status int, v interface{}) error {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "writeJobResponse: Marshalling unsuccessful")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+	w.WriteHeader(status)
+	_, err = w.Write(js)
+	if err != nil {
+		return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Jobs", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	hostRepo := mock.MockHostRepository{}
+	hostStatusRepo := mock.MockHostStatusRepository{}
+	hostSgxRepo := mock.MockHostSgxDataRepository{}
+
+	db := mock.NewMockDatabase(hostRepo, hostStatusRepo, hostSgxRepo)
+
+	jobAdminRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.JobAdminGroupName,
+			Context: "type=SHVS",
+		},
+	}
+	hostReaderRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.HostListReaderGroupName,
+			Context: "type=SHVS",
+		},
+	}
+
+	queuedJob, _ := QueueJob(db, constants.JobTypePolicyReevaluation, nil)
+	failedJob := types.Job{
+		ID:          uuid.New(),
+		Type:        constants.JobTypePolicyReevaluation,
+		Status:      constants.JobStatusError,
+		Attempts:    constants.DefaultJobMaxAttempts,
+		MaxAttempts: constants.DefaultJobMaxAttempts,
+		LastError:   "failed to retrieve policies",
+		NextRunTime: time.Now(),
+		CreatedTime: time.Now(),
+		UpdatedTime: time.Now(),
+	}
+	_, _ = db.JobRepository().Create(&failedJob)
+
+	sendJobRequest := func(method, path string, roles []aas.RoleInfo) {
+		req, err := http.NewRequest(method, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req = context.SetUserRoles(req, roles)
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		JobOps(router, db)
+	})
+
+	Describe("Retrieve jobs", func() {
+		Context("Validate retrieve jobs request", func() {
+			It("Should not query jobs - Insufficient roles were given", func() {
+				sendJobRequest(http.MethodGet, "/jobs", hostReaderRoles)
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+			})
+
+			It("Should query all jobs", func() {
+				sendJobRequest(http.MethodGet, "/jobs", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var jobs types.Jobs
+				err := json.Unmarshal(w.Body.Bytes(), &jobs)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(jobs).To(HaveLen(2))
+			})
+
+			It("Should query jobs by status", func() {
+				sendJobRequest(http.MethodGet, "/jobs?status=ERROR", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var jobs types.Jobs
+				err := json.Unmarshal(w.Body.Bytes(), &jobs)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(jobs).To(HaveLen(1))
+				Expect(jobs[0].ID).To(Equal(failedJob.ID))
+				Expect(jobs[0].LastError).To(Equal(failedJob.LastError))
+			})
+
+			It("Should query the newest jobs up to the limit", func() {
+				sendJobRequest(http.MethodGet, "/jobs?limit=1", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var jobs types.Jobs
+				err := json.Unmarshal(w.Body.Bytes(), &jobs)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(jobs).To(HaveLen(1))
+				Expect(jobs[0].ID).To(Equal(failedJob.ID))
+			})
+
+			It("Should not query jobs - Invalid limit was given", func() {
+				sendJobRequest(http.MethodGet, "/jobs?limit=5000", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+				sendJobRequest(http.MethodGet, "/jobs?limit=0", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+
+			It("Should not query jobs - Invalid status was given", func() {
+				sendJobRequest(http.MethodGet, "/jobs?status=RUNNING", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+
+			It("Should not query jobs - Invalid query parameter was given", func() {
+				sendJobRequest(http.MethodGet, "/jobs?owner=shvs", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+
+			It("Should retrieve job", func() {
+				sendJobRequest(http.MethodGet, "/jobs/"+queuedJob.ID.String(), jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var job types.Job
+				err := json.Unmarshal(w.Body.Bytes(), &job)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(job.Status).To(Equal(constants.JobStatusQueued))
+			})
+
+			It("Should not retrieve job - Unknown id was given", func() {
+				sendJobRequest(http.MethodGet, "/jobs/"+uuid.New().String(), jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("Retry and cancel jobs", func() {
+		Context("Validate retry and cancel job requests", func() {
+			It("Should not retry job - Job is queued", func() {
+				sendJobRequest(http.MethodPost, "/jobs/"+queuedJob.ID.String()+"/retry", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusConflict))
+			})
+
+			It("Should retry failed job", func() {
+				sendJobRequest(http.MethodPost, "/jobs/"+failedJob.ID.String()+"/retry", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				job, err := db.JobRepository().Retrieve(&types.Job{ID: failedJob.ID})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(job.Status).To(Equal(constants.JobStatusQueued))
+				Expect(job.Attempts).To(Equal(0))
+			})
+
+			It("Should not cancel job - Insufficient roles were given", func() {
+				sendJobRequest(http.MethodPost, "/jobs/"+queuedJob.ID.String()+"/cancel", hostReaderRoles)
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+			})
+
+			It("Should cancel queued job", func() {
+				sendJobRequest(http.MethodPost, "/jobs/"+queuedJob.ID.String()+"/cancel", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				job, err := db.JobRepository().Retrieve(&types.Job{ID: queuedJob.ID})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(job.Status).To(Equal(constants.JobStatusCancelled))
+				Expect(job.EndTime).NotTo(BeNil())
+			})
+
+			It("Should not cancel job - Job is already cancelled", func() {
+				sendJobRequest(http.MethodPost, "/jobs/"+queuedJob.ID.String()+"/cancel", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusConflict))
+			})
+
+			It("Should cancel processing job and stop it", func() {
+				processingJob := types.Job{
+					ID:          uuid.New(),
+					Type:        constants.JobTypeComplianceReport,
+					Status:      constants.JobStatusProcessing,
+					Attempts:    1,
+					MaxAttempts: constants.DefaultJobMaxAttempts,
+					Owner:       "shvs-1",
+					NextRunTime: time.Now(),
+					CreatedTime: time.Now(),
+					UpdatedTime: time.Now(),
+				}
+				_, _ = db.JobRepository().Create(&processingJob)
+				var stopped []uuid.UUID
+				SetJobCanceller(func(id uuid.UUID) {
+					stopped = append(stopped, id)
+				})
+				defer SetJobCanceller(nil)
+
+				sendJobRequest(http.MethodPost, "/jobs/"+processingJob.ID.String()+"/cancel", jobAdminRoles)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(stopped).To(Equal([]uuid.UUID{processingJob.ID}))
+			})
+		})
+	})
+})
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"intel/isecl/shvs/v5/constants"
+)
+
+// ndjsonWriter writes one JSON document per line to the response and flushes
+// it periodically, so large result sets never have to be held in memory
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+	started bool
+	rows    int
+}
+
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	flusher, _ := w.(http.Flusher)
+	return &ndjsonWriter{
+		w:       w,
+		enc:     json.NewEncoder(w),
+		flusher: flusher,
+	}
+}
+
+// Write sends the response headers before the first record, so a handler can
+// still report an error status as long as nothing has been streamed yet
+func (nw *ndjsonWriter) Write(v interface{}) error {
+	nw.writeHeader()
+	if err := nw.enc.Encode(v); err != nil {
+		return err
+	}
+	nw.rows++
+	if nw.rows%constants.StreamFlushRowCount == 0 {
+		nw.Flush()
+	}
+	return nil
+}
+
+func (nw *ndjsonWriter) writeHeader() {
+	if nw.started {
+		return
+	}
+	nw.started = true
+	nw.w.Header().Set("Content-Type", constants.HTTPMediaTypeNDJSON)
+	nw.w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+	nw.w.WriteHeader(http.StatusOK)
+}
+
+// Close completes the response, writing the headers of an empty stream if no record was sent
+func (nw *ndjsonWriter) Close() {
+	nw.writeHeader()
+	nw.Flush()
+}
+
+func (nw *ndjsonWriter) Flush() {
+	if nw.flusher != nil {
+		nw.flusher.Flush()
+	}
+}
+
+func (nw *ndjsonWriter) Rows() int {
+	return nw.rows
+}
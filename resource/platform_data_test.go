@@ -5,6 +5,7 @@
 package resource
 
 import (
+	"encoding/json"
 	"fmt"
 	"intel/isecl/lib/common/v5/context"
 	"intel/isecl/lib/common/v5/types/aas"
@@ -12,6 +13,7 @@ import (
 	"intel/isecl/shvs/v5/types"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -98,7 +100,7 @@ var _ = Describe("Platform Data", func() {
 				hostStatus := &types.HostStatus{
 					ID:          hostSgxData.ID,
 					HostID:      hostSgxData.HostID,
-					Status:      "active",
+					Status:      constants.HostStatusConnected,
 					CreatedTime: hostSgxData.CreatedTime,
 					ExpiryTime:  hostSgxData.CreatedTime.Add(1 * time.Hour),
 				}
@@ -258,7 +260,7 @@ var _ = Describe("Platform Data", func() {
 				hostStatus := &types.HostStatus{
 					ID:          host.ID,
 					HostID:      hostSgxData.HostID,
-					Status:      "active",
+					Status:      constants.HostStatusConnected,
 					CreatedTime: hostSgxData.CreatedTime,
 					ExpiryTime:  hostSgxData.CreatedTime.Add(1 * time.Hour),
 				}
@@ -282,6 +284,154 @@ var _ = Describe("Platform Data", func() {
 				router.ServeHTTP(w, req)
 				Expect(w.Code).To(Equal(http.StatusOK))
 			})
+
+			It("Should stream platform-data as ndjson - Valid request with all required roles and permissions given", func() {
+				req, err := http.NewRequest(http.MethodGet, "/platform-data?numberOfMinutes=10", nil)
+				val := []aas.RoleInfo{
+					{
+						Service: constants.ServiceName,
+						Name:    constants.HostDataReaderGroupName,
+						Context: "type=SHVS",
+					},
+				}
+				req = context.SetUserRoles(req, val)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeNDJSON)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeNDJSON))
+
+				lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+				Expect(lines).To(HaveLen(len(db.(*mock.MockDatabase).MockHostSgxDataRepository.HostSGXData)))
+			})
+
+			It("Should stream the platform-data rows as they are listed in the JSON array", func() {
+				getRows := func(mediaType string) []map[string]interface{} {
+					req, err := http.NewRequest(http.MethodGet, "/platform-data?numberOfMinutes=10", nil)
+					Expect(err).NotTo(HaveOccurred())
+					req = context.SetUserRoles(req, []aas.RoleInfo{
+						{
+							Service: constants.ServiceName,
+							Name:    constants.HostDataReaderGroupName,
+							Context: "type=SHVS",
+						},
+					})
+					req.Header.Set("Accept", mediaType)
+					w = httptest.NewRecorder()
+					router.ServeHTTP(w, req)
+					Expect(w.Code).To(Equal(http.StatusOK))
+
+					var rows []map[string]interface{}
+					if mediaType == consts.HTTPMediaTypeJson {
+						Expect(json.Unmarshal(w.Body.Bytes(), &rows)).To(Succeed())
+						return rows
+					}
+					for _, line := range strings.Split(strings.TrimSpace(w.Body.String()), "\n") {
+						var row map[string]interface{}
+						Expect(json.Unmarshal([]byte(line), &row)).To(Succeed())
+						rows = append(rows, row)
+					}
+					return rows
+				}
+
+				jsonRows := getRows(consts.HTTPMediaTypeJson)
+				ndjsonRows := getRows(constants.HTTPMediaTypeNDJSON)
+				Expect(jsonRows).NotTo(BeEmpty())
+				Expect(ndjsonRows).NotTo(BeEmpty())
+				Expect(keys(ndjsonRows[0])).To(Equal(keys(jsonRows[0])))
+				for _, row := range ndjsonRows {
+					_, err := time.Parse(time.RFC3339, row[constants.ExpiryTimeKeyName].(string))
+					Expect(err).NotTo(HaveOccurred())
+				}
+			})
+
+			It("Should list the expiry time of the platform-data in RFC3339", func() {
+				expiryTime := time.Date(2022, 3, 1, 10, 0, 0, 512312000, time.UTC)
+				row, err := platformDataRow(&types.HostSgxData{HostID: uuid.New(), EpcSize: "0x67890"}, expiryTime)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(row[constants.ExpiryTimeKeyName]).To(Equal("2022-03-01T10:00:00Z"))
+				Expect(row["epc_size"]).To(Equal("0x67890"))
+			})
+
+			It("Should get platform-data including stale hosts - Valid includeStale value given", func() {
+				hostIDs := map[string]uuid.UUID{}
+				for _, status := range []string{constants.HostStatusConnected, constants.HostStatusStale, constants.HostStatusInactive} {
+					hostSgxData := &types.HostSgxData{
+						ID:          uuid.New(),
+						HostID:      uuid.New(),
+						CreatedTime: time.Now(),
+					}
+					db.HostSgxDataRepository().Create(hostSgxData)
+					db.HostStatusRepository().Create(&types.HostStatus{
+						ID:          uuid.New(),
+						HostID:      hostSgxData.HostID,
+						Status:      status,
+						CreatedTime: time.Now(),
+						UpdatedTime: time.Now(),
+					})
+					hostIDs[status] = hostSgxData.HostID
+				}
+
+				getPlatformData := func(query string) string {
+					req, err := http.NewRequest(http.MethodGet, "/platform-data?numberOfMinutes=10"+query, nil)
+					val := []aas.RoleInfo{
+						{
+							Service: constants.ServiceName,
+							Name:    constants.HostDataReaderGroupName,
+							Context: "type=SHVS",
+						},
+					}
+					req = context.SetUserRoles(req, val)
+
+					Expect(err).NotTo(HaveOccurred())
+					req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+					w = httptest.NewRecorder()
+					router.ServeHTTP(w, req)
+					Expect(w.Code).To(Equal(http.StatusOK))
+					return w.Body.String()
+				}
+
+				body := getPlatformData("")
+				Expect(body).To(ContainSubstring(hostIDs[constants.HostStatusConnected].String()))
+				Expect(body).NotTo(ContainSubstring(hostIDs[constants.HostStatusStale].String()))
+				Expect(body).NotTo(ContainSubstring(hostIDs[constants.HostStatusInactive].String()))
+
+				body = getPlatformData("&includeStale=true")
+				Expect(body).To(ContainSubstring(hostIDs[constants.HostStatusConnected].String()))
+				Expect(body).To(ContainSubstring(hostIDs[constants.HostStatusStale].String()))
+				Expect(body).NotTo(ContainSubstring(hostIDs[constants.HostStatusInactive].String()))
+			})
+
+			It("Should not get platform-data - Invalid query parameter value for includeStale", func() {
+				SGXHostRegisterOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/platform-data?includeStale=sometimes", nil)
+				val := []aas.RoleInfo{
+					{
+						Service: constants.ServiceName,
+						Name:    constants.HostDataReaderGroupName,
+						Context: "type=SHVS",
+					},
+				}
+				req = context.SetUserRoles(req, val)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
 		})
 	})
 })
+
+func keys(row map[string]interface{}) []string {
+	var names []string
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	commLogMsg "intel/isecl/lib/common/v5/log/message"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+// PolicyInfo is the request payload used to create or update a policy
+type PolicyInfo struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Rules       types.PolicyRules `json:"rules"`
+}
+
+func PolicyOps(r *mux.Router, db repository.SHVSDatabase) {
+	log.Trace("resource/policy: PolicyOps() Entering")
+	defer log.Trace("resource/policy: PolicyOps() Leaving")
+
+	r.Handle("/policies", contentTypeHandler(audited(constants.AuditActionPolicyCreate, db, createPolicy(db)), "application/json")).Methods("POST")
+	r.Handle("/policies", contentTypeHandler(queryPolicies(db), "application/json")).Methods("GET")
+	r.Handle("/policies/{id}", contentTypeHandler(getPolicy(db), "application/json")).Methods("GET")
+	r.Handle("/policies/{id}", contentTypeHandler(audited(constants.AuditActionPolicyUpdate, db, updatePolicy(db)), "application/json")).Methods("PUT")
+	r.Handle("/policies/{id}", audited(constants.AuditActionPolicyDelete, db, deletePolicy(db))).Methods("DELETE")
+}
+
+func createPolicy(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/policy: createPolicy() Entering")
+		defer log.Trace("resource/policy: createPolicy() Leaving")
+
+		err := authorizeEndpoint(r, constants.HostListManagerGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		data, err := decodePolicyInfo(r)
+		if err != nil {
+			return err
+		}
+
+		existingPolicy, err := db.PolicyRepository().Retrieve(&types.Policy{Name: data.Name})
+		if existingPolicy != nil && err == nil {
+			slog.Errorf("resource/policy: createPolicy() Policy with name %s already exists", data.Name)
+			return &resourceError{Message: "Policy with given name already exists", StatusCode: http.StatusConflict}
+		}
+
+		policy := types.Policy{
+			ID:          uuid.New(),
+			Name:        data.Name,
+			Description: data.Description,
+			Rules:       data.Rules,
+			CreatedTime: time.Now(),
+			UpdatedTime: time.Now(),
+		}
+		createdPolicy, err := db.PolicyRepository().Create(&policy)
+		if err != nil {
+			log.WithError(err).Info("resource/policy: createPolicy() failed to create policy")
+			return &resourceError{Message: "Failed to create policy", StatusCode: http.StatusInternalServerError}
+		}
+		slog.Infof("%s: Policy %s created by: %s", commLogMsg.AuthorizedAccess, createdPolicy.Name, r.RemoteAddr)
+		auditEvent(r).Target = createdPolicy.ID.String()
+
+		err = queuePolicyReevaluation(db)
+		if err != nil {
+			log.WithError(err).Error("resource/policy: createPolicy() failed to queue reevaluation of hosts")
+		}
+		return writePolicyResponse(w, http.StatusCreated, createdPolicy)
+	})
+}
+
+func queryPolicies(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/policy: queryPolicies() Entering")
+		defer log.Trace("resource/policy: queryPolicies() Leaving")
+
+		err := authorizeEndpoint(r, constants.HostListReaderGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		if len(r.URL.Query()) != 0 {
+			slog.Errorf("resource/policy: queryPolicies() %s", commLogMsg.InvalidInputBadParam)
+			return &resourceError{Message: "Invalid query parameters provided", StatusCode: http.StatusBadRequest}
+		}
+
+		policies, err := db.PolicyRepository().RetrieveAll()
+		if err != nil {
+			log.WithError(err).Info("failed to retrieve policies")
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		if policies == nil {
+			policies = types.Policies{}
+		}
+		slog.Infof("%s: Policies retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return writePolicyResponse(w, http.StatusOK, policies)
+	})
+}
+
+func getPolicy(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/policy: getPolicy() Entering")
+		defer log.Trace("resource/policy: getPolicy() Leaving")
+
+		err := authorizeEndpoint(r, constants.HostListReaderGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		policy, err := retrievePolicyFromPath(r, db)
+		if err != nil {
+			return err
+		}
+		slog.Infof("%s: Policy retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+		return writePolicyResponse(w, http.StatusOK, policy)
+	})
+}
+
+func updatePolicy(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/policy: updatePolicy() Entering")
+		defer log.Trace("resource/policy: updatePolicy() Leaving")
+
+		err := authorizeEndpoint(r, constants.HostListManagerGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		existingPolicy, err := retrievePolicyFromPath(r, db)
+		if err != nil {
+			return err
+		}
+
+		data, err := decodePolicyInfo(r)
+		if err != nil {
+			return err
+		}
+
+		if data.Name != existingPolicy.Name {
+			namedPolicy, err := db.PolicyRepository().Retrieve(&types.Policy{Name: data.Name})
+			if namedPolicy != nil && err == nil {
+				slog.Errorf("resource/policy: updatePolicy() Policy with name %s already exists", data.Name)
+				return &resourceError{Message: "Policy with given name already exists", StatusCode: http.StatusConflict}
+			}
+		}
+
+		policy := types.Policy{
+			ID:          existingPolicy.ID,
+			Name:        data.Name,
+			Description: data.Description,
+			Rules:       data.Rules,
+			CreatedTime: existingPolicy.CreatedTime,
+			UpdatedTime: time.Now(),
+		}
+		err = db.PolicyRepository().Update(&policy)
+		if err != nil {
+			log.WithError(err).Info("resource/policy: updatePolicy() failed to update policy")
+			return &resourceError{Message: "Failed to update policy", StatusCode: http.StatusInternalServerError}
+		}
+		slog.Infof("%s: Policy %s updated by: %s", commLogMsg.AuthorizedAccess, policy.Name, r.RemoteAddr)
+
+		err = queuePolicyReevaluation(db)
+		if err != nil {
+			log.WithError(err).Error("resource/policy: updatePolicy() failed to queue reevaluation of hosts")
+		}
+		return writePolicyResponse(w, http.StatusOK, &policy)
+	})
+}
+
+func deletePolicy(db repository.SHVSDatabase) errorHandlerFunc {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
+		log.Trace("resource/policy: deletePolicy() Entering")
+		defer log.Trace("resource/policy: deletePolicy() Leaving")
+
+		err := authorizeEndpoint(r, constants.HostListManagerGroupName, true)
+		if err != nil {
+			return err
+		}
+
+		policy, err := retrievePolicyFromPath(r, db)
+		if err != nil {
+			return err
+		}
+
+		err = db.PolicyRepository().Delete(policy)
+		if err != nil {
+			log.WithError(err).Info("resource/policy: deletePolicy() failed to delete policy")
+			return &resourceError{Message: "Failed to delete policy", StatusCode: http.StatusInternalServerError}
+		}
+		slog.Infof("%s: Policy %s deleted by: %s", commLogMsg.AuthorizedAccess, policy.Name, r.RemoteAddr)
+
+		err = queuePolicyReevaluation(db)
+		if err != nil {
+			log.WithError(err).Error("resource/policy: deletePolicy() failed to queue reevaluation of hosts")
+		}
+		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
+
+func decodePolicyInfo(r *http.Request) (*PolicyInfo, error) {
+	if r.ContentLength == 0 {
+		slog.Error("resource/policy: decodePolicyInfo() The request body was not provided")
+		return nil, &resourceError{Message: "The request body was not provided", StatusCode: http.StatusBadRequest}
+	}
+
+	var data PolicyInfo
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&data)
+	if err != nil {
+		slog.WithError(err).Errorf("resource/policy: decodePolicyInfo() %s : Failed to decode request body", commLogMsg.InvalidInputBadEncoding)
+		if tooLarge := bodyTooLargeError(err); tooLarge != nil {
+			return nil, tooLarge
+		}
+		return nil, &resourceError{Message: "Unable to decode JSON request body", StatusCode: http.StatusBadRequest}
+	}
+
+	if !validateInputString(constants.PolicyName, data.Name) || !validateInputString(constants.PolicyDesc, data.Description) {
+		slog.Errorf("resource/policy: decodePolicyInfo() %s : Input validation failed", commLogMsg.InvalidInputBadParam)
+		return nil, &resourceError{Message: "Invalid policy name or description", StatusCode: http.StatusBadRequest}
+	}
+	if len(data.Rules) == 0 {
+		slog.Errorf("resource/policy: decodePolicyInfo() %s : Policy has no rules", commLogMsg.InvalidInputBadParam)
+		return nil, &resourceError{Message: "Policy must have at least one rule", StatusCode: http.StatusBadRequest}
+	}
+	for _, rule := range data.Rules {
+		if err = validatePolicyRule(rule); err != nil {
+			slog.WithError(err).Errorf("resource/policy: decodePolicyInfo() %s : Invalid policy rule", commLogMsg.InvalidInputBadParam)
+			return nil, &resourceError{Message: "Invalid policy rule: " + err.Error(), StatusCode: http.StatusBadRequest}
+		}
+	}
+	return &data, nil
+}
+
+func retrievePolicyFromPath(r *http.Request, db repository.SHVSDatabase) (*types.Policy, error) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		slog.Errorf("resource/policy: retrievePolicyFromPath() Input validation failed for policy ID")
+		return nil, &resourceError{Message: err.Error(), StatusCode: http.StatusBadRequest}
+	}
+
+	policy, err := db.PolicyRepository().Retrieve(&types.Policy{ID: id})
+	if policy == nil || err != nil {
+		log.WithError(err).WithField("id", id).Info("attempt to fetch invalid policy")
+		return nil, &resourceError{Message: "Policy with given id don't exist", StatusCode: http.StatusNotFound}
+	}
+	return policy, nil
+}
+
+func writePolicyResponse(w http.ResponseWriter, status int, v interface{}) error {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "writePolicyResponse: Marshalling unsuccessful")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
+	w.WriteHeader(status)
+	_, err = w.Write(js)
+	if err != nil {
+		return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/types"
+)
+
+const (
+	policyAttrSgxSupported = "sgx_supported"
+	policyAttrSgxEnabled   = "sgx_enabled"
+	policyAttrFlcEnabled   = "flc_enabled"
+	policyAttrTcbUptodate  = "tcb_upToDate"
+	policyAttrEpcSize      = "epc_size"
+)
+
+var boolPolicyOperators = map[string]bool{"==": true, "!=": true}
+var sizePolicyOperators = map[string]bool{"==": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true}
+
+var epcSizeRegEx = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([KMG]?B)?$`)
+var epcSizeUnits = map[string]float64{"": 1, "B": 1, "KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30}
+
+// validatePolicyRule checks that the rule refers to a known attribute with an operator and
+// value applicable to it
+func validatePolicyRule(rule types.PolicyRule) error {
+	switch rule.Attribute {
+	case policyAttrSgxSupported, policyAttrSgxEnabled, policyAttrFlcEnabled, policyAttrTcbUptodate:
+		if !boolPolicyOperators[rule.Operator] {
+			return errors.Errorf("operator %q is not supported for %s", rule.Operator, rule.Attribute)
+		}
+		if _, err := strconv.ParseBool(rule.Value); err != nil {
+			return errors.Errorf("value of %s must be boolean", rule.Attribute)
+		}
+	case policyAttrEpcSize:
+		if !sizePolicyOperators[rule.Operator] {
+			return errors.Errorf("operator %q is not supported for %s", rule.Operator, rule.Attribute)
+		}
+		if _, err := parseEPCSize(rule.Value); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("unknown policy attribute %q", rule.Attribute)
+	}
+	return nil
+}
+
+// parseEPCSize returns the number of bytes of an EPC size given either as a byte count
+// (decimal or 0x prefixed hex) or as a number followed by B, KB, MB or GB
+func parseEPCSize(size string) (float64, error) {
+	size = strings.TrimSpace(size)
+	if strings.HasPrefix(size, "0x") || strings.HasPrefix(size, "0X") {
+		n, err := strconv.ParseUint(size[2:], 16, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid epc size %q", size)
+		}
+		return float64(n), nil
+	}
+	match := epcSizeRegEx.FindStringSubmatch(strings.ToUpper(size))
+	if match == nil {
+		return 0, errors.Errorf("invalid epc size %q", size)
+	}
+	n, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid epc size %q", size)
+	}
+	return n * epcSizeUnits[match[2]], nil
+}
+
+// evaluatePolicyRule reports whether the platform data satisfies the rule, along with the
+// value of the attribute found on the platform
+func evaluatePolicyRule(rule types.PolicyRule, data *types.HostSgxData) (bool, string) {
+	var actual bool
+	switch rule.Attribute {
+	case policyAttrSgxSupported:
+		actual = data.SgxSupported
+	case policyAttrSgxEnabled:
+		actual = data.SgxEnabled
+	case policyAttrFlcEnabled:
+		actual = data.FlcEnabled
+	case policyAttrTcbUptodate:
+		actual = data.TcbUptodate
+	case policyAttrEpcSize:
+		return compareEPCSize(rule, data.EpcSize), data.EpcSize
+	default:
+		return false, ""
+	}
+
+	expected, err := strconv.ParseBool(rule.Value)
+	if err != nil {
+		return false, strconv.FormatBool(actual)
+	}
+	if rule.Operator == "!=" {
+		return actual != expected, strconv.FormatBool(actual)
+	}
+	return actual == expected, strconv.FormatBool(actual)
+}
+
+func compareEPCSize(rule types.PolicyRule, epcSize string) bool {
+	actual, err := parseEPCSize(epcSize)
+	if err != nil {
+		return false
+	}
+	expected, err := parseEPCSize(rule.Value)
+	if err != nil {
+		return false
+	}
+	switch rule.Operator {
+	case "==":
+		return actual == expected
+	case "!=":
+		return actual != expected
+	case ">":
+		return actual > expected
+	case ">=":
+		return actual >= expected
+	case "<":
+		return actual < expected
+	case "<=":
+		return actual <= expected
+	}
+	return false
+}
+
+// evaluatePolicies returns the verdict of the platform data against all the policies. A host is
+// compliant when it satisfies every rule of every policy
+func evaluatePolicies(policies types.Policies, data *types.HostSgxData) (bool, types.FailedPolicyRules) {
+	var failedRules types.FailedPolicyRules
+	for _, policy := range policies {
+		for _, rule := range policy.Rules {
+			if ok, actual := evaluatePolicyRule(rule, data); !ok {
+				failedRules = append(failedRules, types.FailedPolicyRule{
+					Policy:     policy.Name,
+					PolicyRule: rule,
+					Actual:     actual,
+				})
+			}
+		}
+	}
+	return len(failedRules) == 0, failedRules
+}
+
+func evaluateHostPolicies(log *logrus.Entry, hostID uuid.UUID, db repository.SHVSDatabase, data *types.HostSgxData) error {
+	log.Trace("resource/policy_engine: evaluateHostPolicies() Entering")
+	defer log.Trace("resource/policy_engine: evaluateHostPolicies() Leaving")
+
+	policies, err := db.PolicyRepository().RetrieveAll()
+	if err != nil {
+		return errors.Wrap(err, "evaluateHostPolicies: Error while retrieving policies")
+	}
+	return saveHostPolicyVerdict(log, hostID, db, policies, data)
+}
+
+func saveHostPolicyVerdict(log *logrus.Entry, hostID uuid.UUID, db repository.SHVSDatabase, policies types.Policies, data *types.HostSgxData) error {
+	compliant, failedRules := evaluatePolicies(policies, data)
+	verdict := types.HostPolicyVerdict{
+		HostID:        hostID,
+		Compliant:     compliant,
+		FailedRules:   failedRules,
+		EvaluatedTime: time.Now(),
+	}
+
+	existingVerdict, err := db.HostPolicyVerdictRepository().Retrieve(&types.HostPolicyVerdict{HostID: hostID})
+	if existingVerdict == nil || err != nil {
+		verdict.ID = uuid.New()
+		_, err = db.HostPolicyVerdictRepository().Create(&verdict)
+	} else {
+		verdict.ID = existingVerdict.ID
+		err = db.HostPolicyVerdictRepository().Update(&verdict)
+	}
+	if err != nil {
+		return errors.Wrap(err, "saveHostPolicyVerdict: Error while storing host policy verdict")
+	}
+	log.Debugf("resource/policy_engine: host %s evaluated, compliant: %t", hostID, compliant)
+	return nil
+}
+
+// ReevaluateHostPolicies refreshes the verdicts of all hosts with platform data after the
+// policies were changed. It stops between hosts once ctx is done.
+func ReevaluateHostPolicies(ctx context.Context, db repository.SHVSDatabase) error {
+	log.Trace("resource/policy_engine: ReevaluateHostPolicies() Entering")
+	defer log.Trace("resource/policy_engine: ReevaluateHostPolicies() Leaving")
+
+	policies, err := db.PolicyRepository().RetrieveAll()
+	if err != nil {
+		return errors.Wrap(err, "ReevaluateHostPolicies: Error while retrieving policies")
+	}
+
+	var hosts []types.HostSgxData
+	err = db.HostRepository().StreamHostInventory(&types.Host{}, nil, func(host *types.HostInventory) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if host.SgxSupported == nil {
+			return nil
+		}
+		hosts = append(hosts, types.HostSgxData{
+			HostID:       host.HostID,
+			SgxSupported: *host.SgxSupported,
+			SgxEnabled:   host.SgxEnabled != nil && *host.SgxEnabled,
+			FlcEnabled:   host.FlcEnabled != nil && *host.FlcEnabled,
+			TcbUptodate:  host.TcbUptodate != nil && *host.TcbUptodate,
+			EpcSize:      valueOrEmpty(host.EpcSize),
+		})
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "ReevaluateHostPolicies: Error while reading host inventory")
+	}
+
+	failed := 0
+	for i := range hosts {
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "ReevaluateHostPolicies: stopped after %d of %d hosts", i, len(hosts))
+		}
+		err = saveHostPolicyVerdict(log, hosts[i].HostID, db, policies, &hosts[i])
+		if err != nil {
+			log.WithError(err).WithField("hostID", hosts[i].HostID).Error("ReevaluateHostPolicies: failed to evaluate host")
+			failed++
+		}
+	}
+	if failed != 0 {
+		return errors.Errorf("ReevaluateHostPolicies: %d of %d hosts could not be evaluated", failed, len(hosts))
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policies", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	hostRepo := mock.MockHostRepository{}
+	hostStatusRepo := mock.MockHostStatusRepository{}
+	hostSgxRepo := mock.MockHostSgxDataRepository{}
+
+	db := mock.NewMockDatabase(hostRepo, hostStatusRepo, hostSgxRepo)
+
+	policyManagerRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.HostListManagerGroupName,
+			Context: "type=SHVS",
+		},
+	}
+	policyReaderRoles := []aas.RoleInfo{
+		{
+			Service: constants.ServiceName,
+			Name:    constants.HostListReaderGroupName,
+			Context: "type=SHVS",
+		},
+	}
+
+	sgxPolicy := PolicyInfo{
+		Name:        "sgx-baseline",
+		Description: "SGX enabled AND FLC enabled AND TCB up to date AND EPC >= 64MB",
+		Rules: types.PolicyRules{
+			{Attribute: "sgx_enabled", Operator: "==", Value: "true"},
+			{Attribute: "flc_enabled", Operator: "==", Value: "true"},
+			{Attribute: "tcb_upToDate", Operator: "==", Value: "true"},
+			{Attribute: "epc_size", Operator: ">=", Value: "64MB"},
+		},
+	}
+	var createdPolicy types.Policy
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+	})
+
+	Describe("Evaluate policies", func() {
+		Context("Evaluate platform data against policies", func() {
+			It("Should parse epc sizes", func() {
+				size, err := parseEPCSize("64MB")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(size).To(Equal(float64(64 << 20)))
+
+				size, err = parseEPCSize("189.5 MB")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(size).To(Equal(189.5 * (1 << 20)))
+
+				size, err = parseEPCSize("0x4000000")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(size).To(Equal(float64(64 << 20)))
+
+				_, err = parseEPCSize("64 TB")
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("Should report the failing rules", func() {
+				policies := types.Policies{{Name: sgxPolicy.Name, Rules: sgxPolicy.Rules}}
+				compliant, failedRules := evaluatePolicies(policies, &types.HostSgxData{
+					SgxSupported: true,
+					SgxEnabled:   true,
+					FlcEnabled:   true,
+					TcbUptodate:  false,
+					EpcSize:      "32 MB",
+				})
+				Expect(compliant).To(BeFalse())
+				Expect(failedRules).To(HaveLen(2))
+				Expect(failedRules[0].Attribute).To(Equal("tcb_upToDate"))
+				Expect(failedRules[1].Actual).To(Equal("32 MB"))
+
+				compliant, failedRules = evaluatePolicies(policies, &types.HostSgxData{
+					SgxSupported: true,
+					SgxEnabled:   true,
+					FlcEnabled:   true,
+					TcbUptodate:  true,
+					EpcSize:      "128 MB",
+				})
+				Expect(compliant).To(BeTrue())
+				Expect(failedRules).To(BeEmpty())
+			})
+
+			It("Should not accept invalid rules", func() {
+				Expect(validatePolicyRule(types.PolicyRule{Attribute: "sgx_enabled", Operator: ">=", Value: "true"})).To(HaveOccurred())
+				Expect(validatePolicyRule(types.PolicyRule{Attribute: "sgx_enabled", Operator: "==", Value: "yes"})).To(HaveOccurred())
+				Expect(validatePolicyRule(types.PolicyRule{Attribute: "epc_size", Operator: ">=", Value: "lots"})).To(HaveOccurred())
+				Expect(validatePolicyRule(types.PolicyRule{Attribute: "unknown", Operator: "==", Value: "true"})).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Create policy", func() {
+		Context("Validate create policy request", func() {
+			It("Should not create policy - Insufficient roles were given", func() {
+				PolicyOps(router, db)
+
+				body, _ := json.Marshal(sgxPolicy)
+				req, err := http.NewRequest(http.MethodPost, "/policies", bytes.NewReader(body))
+				req = context.SetUserRoles(req, policyReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+			})
+
+			It("Should not create policy - Invalid rule were given", func() {
+				PolicyOps(router, db)
+
+				invalidPolicy := PolicyInfo{
+					Name:  "invalid-policy",
+					Rules: types.PolicyRules{{Attribute: "epc_size", Operator: "~", Value: "64MB"}},
+				}
+				body, _ := json.Marshal(invalidPolicy)
+				req, err := http.NewRequest(http.MethodPost, "/policies", bytes.NewReader(body))
+				req = context.SetUserRoles(req, policyManagerRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+
+			It("Should create policy", func() {
+				PolicyOps(router, db)
+
+				body, _ := json.Marshal(sgxPolicy)
+				req, err := http.NewRequest(http.MethodPost, "/policies", bytes.NewReader(body))
+				req = context.SetUserRoles(req, policyManagerRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+
+				err = json.Unmarshal(w.Body.Bytes(), &createdPolicy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(createdPolicy.Name).To(Equal(sgxPolicy.Name))
+				Expect(createdPolicy.Rules).To(HaveLen(len(sgxPolicy.Rules)))
+			})
+
+			It("Should not create policy - Duplicate name were given", func() {
+				PolicyOps(router, db)
+
+				body, _ := json.Marshal(sgxPolicy)
+				req, err := http.NewRequest(http.MethodPost, "/policies", bytes.NewReader(body))
+				req = context.SetUserRoles(req, policyManagerRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusConflict))
+			})
+		})
+	})
+
+	Describe("Retrieve policies", func() {
+		Context("Validate retrieve policies request", func() {
+			It("Should query policies", func() {
+				PolicyOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/policies", nil)
+				req = context.SetUserRoles(req, policyReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var policies types.Policies
+				err = json.Unmarshal(w.Body.Bytes(), &policies)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policies).To(HaveLen(1))
+			})
+
+			It("Should not get policy - Unknown policy id were given", func() {
+				PolicyOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/policies/"+uuid.New().String(), nil)
+				req = context.SetUserRoles(req, policyReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("Host verdict", func() {
+		Context("Validate host compliance verdict", func() {
+			It("Should show the verdict of a host on get hosts", func() {
+				host := types.Host{
+					ID:           uuid.New(),
+					Name:         "policyhostname",
+					HardwareUUID: uuid.New(),
+					CreatedTime:  time.Now(),
+				}
+				db.HostRepository().Create(&host)
+
+				err := pushSGXEnablementInfoToDB(log, host.ID, db, &SGXHostInfo{
+					SgxSupported: true,
+					SgxEnabled:   true,
+					FlcEnabled:   false,
+					TcbUptodate:  true,
+					EpcSize:      "128 MB",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				SGXHostRegisterOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts/"+host.ID.String(), nil)
+				req = context.SetUserRoles(req, policyReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var hostInfo types.HostInfo
+				err = json.Unmarshal(w.Body.Bytes(), &hostInfo)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hostInfo.Compliance).NotTo(BeNil())
+				Expect(hostInfo.Compliance.Compliant).To(BeFalse())
+				Expect(hostInfo.Compliance.FailedRules).To(HaveLen(1))
+				Expect(hostInfo.Compliance.FailedRules[0].Attribute).To(Equal("flc_enabled"))
+			})
+
+			It("Should not query hosts - Invalid compliant filter were given", func() {
+				SGXHostRegisterOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts?compliant=maybe", nil)
+				req = context.SetUserRoles(req, policyReaderRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("Update and delete policy", func() {
+		Context("Validate update and delete policy request", func() {
+			It("Should update policy", func() {
+				PolicyOps(router, db)
+
+				updatedPolicy := sgxPolicy
+				updatedPolicy.Rules = types.PolicyRules{{Attribute: "sgx_enabled", Operator: "==", Value: "true"}}
+				body, _ := json.Marshal(updatedPolicy)
+				req, err := http.NewRequest(http.MethodPut, "/policies/"+createdPolicy.ID.String(), bytes.NewReader(body))
+				req = context.SetUserRoles(req, policyManagerRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				policy, err := db.PolicyRepository().Retrieve(&types.Policy{ID: createdPolicy.ID})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy.Rules).To(HaveLen(1))
+
+				// the reevaluation queued on create has not run yet, so no other one is queued
+				jobs := db.(*mock.MockDatabase).MockJobRepository.Jobs
+				Expect(jobs).To(HaveLen(1))
+				Expect(jobs[0].Type).To(Equal(constants.JobTypePolicyReevaluation))
+				Expect(jobs[0].Status).To(Equal(constants.JobStatusQueued))
+			})
+
+			It("Should delete policy", func() {
+				PolicyOps(router, db)
+
+				req, err := http.NewRequest(http.MethodDelete, "/policies/"+createdPolicy.ID.String(), nil)
+				req = context.SetUserRoles(req, policyManagerRoles)
+
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNoContent))
+
+				policies, err := db.PolicyRepository().RetrieveAll()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policies).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"intel/isecl/shvs/v5/constants"
+)
+
+// Error codes of the problem responses. Clients can switch on them, they are not changed once released.
+const (
+	ErrorCodeBadRequest         = "bad_request"
+	ErrorCodeInvalidInput       = "invalid_input"
+	ErrorCodeInvalidBody        = "invalid_request_body"
+	ErrorCodeUnauthorized       = "unauthorized"
+	ErrorCodeTokenMismatch      = "token_subject_mismatch"
+	ErrorCodeForbidden          = "forbidden"
+	ErrorCodeNotFound           = "not_found"
+	ErrorCodeMethodNotAllowed   = "method_not_allowed"
+	ErrorCodeConflict           = "conflict"
+	ErrorCodeHostNotConnected   = "host_not_connected"
+	ErrorCodePayloadTooLarge    = "payload_too_large"
+	ErrorCodeUnsupportedMedia   = "unsupported_media_type"
+	ErrorCodeTooManyRequests    = "too_many_requests"
+	ErrorCodeServiceUnavailable = "service_unavailable"
+	ErrorCodeInternal           = "internal_error"
+)
+
+// problemTypePrefix prefixes the error code in the type URI of a problem
+const problemTypePrefix = "urn:intel:isecl:shvs:error:"
+
+// Problem is the RFC 7807 application/problem+json body of all error responses
+type Problem struct {
+	// Type is a URI naming the error code, such as urn:intel:isecl:shvs:error:not_found
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Code is the error code clients can switch on
+	Code string `json:"code"`
+	// RequestID correlates the response with the logs of the service
+	RequestID string `json:"request_id,omitempty"`
+	// Errors lists the invalid fields of the request
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError tells why a field of the request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// defaultErrorCode gives the error code of an error which has none
+func defaultErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return ErrorCodeMethodNotAllowed
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return ErrorCodeUnsupportedMedia
+	case http.StatusTooManyRequests:
+		return ErrorCodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return ErrorCodeServiceUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return ErrorCodeInternal
+	}
+	return ErrorCodeBadRequest
+}
+
+func newProblem(r *http.Request, status int, code, detail string, fieldErrors []FieldError) Problem {
+	if code == "" {
+		code = defaultErrorCode(status)
+	}
+	return Problem{
+		Type:      problemTypePrefix + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      code,
+		RequestID: r.Header.Get(constants.RequestIDHeaderKey),
+		Errors:    fieldErrors,
+	}
+}
+
+func writeProblem(w http.ResponseWriter, problem Problem) {
+	js, err := json.Marshal(problem)
+	if err != nil {
+		log.WithError(err).Error("resource/problem: writeProblem() Failed to marshal problem")
+		http.Error(w, problem.Detail, problem.Status)
+		return
+	}
+	w.Header().Set("Content-Type", constants.HTTPMediaTypeProblemJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set(constants.HstsHeaderKey, constants.HstsHeaderValue)
+	w.WriteHeader(problem.Status)
+	_, err = w.Write(js)
+	if err != nil {
+		log.WithError(err).Error("resource/problem: writeProblem() Failed to write problem")
+	}
+}
+
+// NotFoundHandler answers the requests matching no route with a problem, to be set as the NotFoundHandler of
+// the router
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, newProblem(r, http.StatusNotFound, "", "No such resource: "+r.URL.Path, nil))
+	})
+}
+
+// MethodNotAllowedHandler answers the requests matching a route by path only with a problem, to be set as
+// the MethodNotAllowedHandler of the router
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, "",
+			fmt.Sprintf("Method %s is not allowed on %s", r.Method, r.URL.Path), nil))
+	})
+}
+
+// contentTypeHandler checks the content type of the PUT, POST and PATCH requests as handlers.ContentTypeHandler
+// does, rejecting the others with a problem rather than a plain text error
+func contentTypeHandler(h http.Handler, contentTypes ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut && r.Method != http.MethodPost && r.Method != http.MethodPatch {
+			h.ServeHTTP(w, r)
+			return
+		}
+		contentType := r.Header.Get("Content-Type")
+		mediaType, _, _ := strings.Cut(contentType, ";")
+		for _, ct := range contentTypes {
+			if mediaType == ct {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+		writeProblem(w, newProblem(r, http.StatusUnsupportedMediaType, "",
+			fmt.Sprintf("Unsupported content type %q; expected one of %q", contentType, contentTypes), nil))
+	})
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Problem", func() {
+	var w *httptest.ResponseRecorder
+
+	serve := func(handler errorHandlerFunc, req *http.Request) Problem {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeProblemJSON))
+		var problem Problem
+		Expect(json.Unmarshal(w.Body.Bytes(), &problem)).To(Succeed())
+		return problem
+	}
+
+	route := func(router *mux.Router, req *http.Request) Problem {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeProblemJSON))
+		var problem Problem
+		Expect(json.Unmarshal(w.Body.Bytes(), &problem)).To(Succeed())
+		return problem
+	}
+
+	Describe("Error responses", func() {
+		It("Should describe a resource error with its code and the request ID", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/hosts", nil)
+			req.Header.Set(constants.RequestIDHeaderKey, "7d4b2a6c")
+			problem := serve(func(w http.ResponseWriter, r *http.Request) error {
+				return &resourceError{Message: "Host is not connected", StatusCode: http.StatusConflict, Code: ErrorCodeHostNotConnected}
+			}, req)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			Expect(problem).To(Equal(Problem{
+				Type:      "urn:intel:isecl:shvs:error:host_not_connected",
+				Title:     "Conflict",
+				Status:    http.StatusConflict,
+				Detail:    "Host is not connected",
+				Code:      ErrorCodeHostNotConnected,
+				RequestID: "7d4b2a6c",
+			}))
+		})
+
+		It("Should derive the code from the status code", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/hosts", nil)
+			problem := serve(func(w http.ResponseWriter, r *http.Request) error {
+				return &privilegeError{StatusCode: http.StatusForbidden}
+			}, req)
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(problem.Code).To(Equal(ErrorCodeForbidden))
+			Expect(problem.Title).To(Equal("Forbidden"))
+
+			problem = serve(func(w http.ResponseWriter, r *http.Request) error {
+				return errors.New("unexpected")
+			}, req)
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+			Expect(problem.Code).To(Equal(ErrorCodeInternal))
+			Expect(problem.Detail).To(Equal("unexpected"))
+		})
+
+		It("Should list the invalid fields of a host registration", func() {
+			router := mux.NewRouter()
+			db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+			SGXHostRegisterOps(router, db)
+
+			body, _ := json.Marshal(SGXHostInfo{HostName: "validtesthostname", UUID: "not-a-uuid"})
+			req, err := http.NewRequest(http.MethodPost, "/hosts", bytes.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			req = context.SetUserRoles(req, []aas.RoleInfo{
+				{
+					Service: constants.ServiceName,
+					Name:    constants.HostDataUpdaterGroupName,
+					Context: "type=SHVS",
+				},
+			})
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeProblemJSON))
+			var problem Problem
+			Expect(json.Unmarshal(w.Body.Bytes(), &problem)).To(Succeed())
+			Expect(problem.Code).To(Equal(ErrorCodeInvalidInput))
+			Expect(problem.Errors).To(Equal([]FieldError{{Field: "uuid", Message: "invalid hardware UUID"}}))
+		})
+
+		It("Should describe an unsupported content type", func() {
+			router := mux.NewRouter()
+			db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+			SGXHostRegisterOps(router, db)
+
+			req, err := http.NewRequest(http.MethodPost, "/hosts", bytes.NewReader([]byte("hostname")))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Content-Type", "text/plain")
+			problem := route(router, req)
+			Expect(w.Code).To(Equal(http.StatusUnsupportedMediaType))
+			Expect(problem.Code).To(Equal(ErrorCodeUnsupportedMedia))
+		})
+
+		It("Should describe the requests matching no route", func() {
+			// routed as the service routes its subrouters
+			router := mux.NewRouter()
+			router.NotFoundHandler = NotFoundHandler()
+			router.MethodNotAllowedHandler = MethodNotAllowedHandler()
+			SetVersionRoutes(router.PathPrefix("/sgx-hvs/v2/").Subrouter())
+			db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+			JobOps(router.PathPrefix("/sgx-hvs/v2/").Subrouter(), db)
+
+			req, _ := http.NewRequest(http.MethodGet, "/sgx-hvs/v2/unknown", nil)
+			problem := route(router, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(problem.Code).To(Equal(ErrorCodeNotFound))
+
+			req, _ = http.NewRequest(http.MethodDelete, "/sgx-hvs/v2/jobs", nil)
+			problem = route(router, req)
+			Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(problem.Code).To(Equal(ErrorCodeMethodNotAllowed))
+
+			// the routes of the second subrouter are still reached
+			req, _ = http.NewRequest(http.MethodGet, "/sgx-hvs/v2/jobs", nil)
+			problem = route(router, req)
+			Expect(problem.Code).NotTo(Equal(ErrorCodeNotFound))
+			Expect(problem.Code).NotTo(Equal(ErrorCodeMethodNotAllowed))
+		})
+	})
+})
@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/shvs/v5/ratelimit"
+)
+
+// RateLimitByIP rejects the requests of a remote IP beyond the rate of the limiter with 429 Too Many Requests.
+// The remote IP of a request forwarded by one of the trusted proxies is taken from its X-Forwarded-For header,
+// otherwise all clients behind a proxy would share the limit of the proxy. A nil limiter lets all requests
+// through.
+func RateLimitByIP(limiter *ratelimit.Limiter, trustedProxies []*net.IPNet) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r, trustedProxies)
+			if allowed, wait := limiter.Allow(ip); !allowed {
+				writeTooManyRequests(w, r, wait, "remote IP "+ip)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the peer IP of the request or, when the peer is a trusted proxy, the right-most address of
+// the X-Forwarded-For header which is not a trusted proxy. The addresses left of it are set by the client and
+// cannot be trusted.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(address) == nil {
+			break
+		}
+		ip = address
+		if !isTrustedProxy(ip, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimitBySubject rejects the requests of a token subject beyond the rate of the limiter with 429 Too Many
+// Requests. It follows the token authentication, which sets the subject of the requests. A nil limiter lets
+// all requests through.
+func RateLimitBySubject(limiter *ratelimit.Limiter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject, err := context.GetTokenSubject(r)
+			if err == nil && subject != "" {
+				if allowed, wait := limiter.Allow(subject); !allowed {
+					writeTooManyRequests(w, r, wait, "token subject "+subject)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeTooManyRequests tells the client to retry after the wait, rounded up to whole seconds
+func writeTooManyRequests(w http.ResponseWriter, r *http.Request, wait time.Duration, client string) {
+	requestLog(r, slog).Warnf("resource/rate_limit: Rate limit exceeded by %s from %s", client, r.RemoteAddr)
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeProblem(w, newProblem(r, http.StatusTooManyRequests, "", "Rate limit exceeded, retry after "+
+		strconv.Itoa(seconds)+" seconds", nil))
+}
+
+// LimitRequestBody rejects the requests announcing a body larger than max bytes with 413 Request Entity Too
+// Large, and bounds the bodies read by the handlers to max bytes
+func LimitRequestBody(max int64) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > max {
+				requestLog(r, slog).Warnf("resource/rate_limit: Request body of %d bytes from %s exceeds %d bytes",
+					r.ContentLength, r.RemoteAddr, max)
+				writeProblem(w, newProblem(r, http.StatusRequestEntityTooLarge, "", bodyTooLargeMessage(max), nil))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bodyTooLargeError returns the error of a handler which read past the limit of LimitRequestBody, or nil
+func bodyTooLargeError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return nil
+	}
+	return &resourceError{Message: bodyTooLargeMessage(maxBytesErr.Limit), StatusCode: http.StatusRequestEntityTooLarge}
+}
+
+func bodyTooLargeMessage(max int64) string {
+	return "Request body exceeds " + strconv.FormatInt(max, 10) + " bytes"
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"intel/isecl/lib/common/v5/context"
+	"intel/isecl/lib/common/v5/types/aas"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/ratelimit"
+	"intel/isecl/shvs/v5/repository/mock"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v5/pkg/lib/common/constants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimit", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	sendRequest := func(remoteAddr, subject string, forwardedFor ...string) {
+		req, err := http.NewRequest(http.MethodGet, "/version", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.RemoteAddr = remoteAddr
+		for _, address := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", address)
+		}
+		if subject != "" {
+			req = context.SetTokenSubject(req, subject)
+		}
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	problem := func() Problem {
+		Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeProblemJSON))
+		var problem Problem
+		Expect(json.Unmarshal(w.Body.Bytes(), &problem)).To(Succeed())
+		return problem
+	}
+
+	Describe("Rate limits", func() {
+		It("Should limit the requests of a remote IP", func() {
+			router = mux.NewRouter()
+			router.Use(RateLimitByIP(ratelimit.NewLimiter(0.5, 1), nil))
+			router.HandleFunc("/version", ok)
+
+			sendRequest("10.0.0.5:51234", "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			sendRequest("10.0.0.5:51235", "")
+			Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(w.Header().Get("Retry-After")).To(Equal("2"))
+			Expect(problem().Code).To(Equal(ErrorCodeTooManyRequests))
+
+			sendRequest("10.0.0.6:51234", "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			sendRequest("10.0.0.5:51236", "", "192.168.1.10")
+			Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("Should limit the requests of the client IP forwarded by a trusted proxy", func() {
+			_, proxies, _ := net.ParseCIDR("10.0.0.0/24")
+			router = mux.NewRouter()
+			router.Use(RateLimitByIP(ratelimit.NewLimiter(0.5, 1), []*net.IPNet{proxies}))
+			router.HandleFunc("/version", ok)
+
+			sendRequest("10.0.0.5:51234", "", "192.168.1.10")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			sendRequest("10.0.0.6:51234", "", "192.168.1.11, 10.0.0.7")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			sendRequest("10.0.0.5:51235", "", "192.168.1.10")
+			Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+
+			// Addresses left of the client IP are set by the client
+			sendRequest("10.0.0.5:51236", "", "192.168.1.12", "192.168.1.10")
+			Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+			sendRequest("10.0.0.5:51237", "", "192.168.1.10, 192.168.1.13")
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Should not limit the requests without limiter", func() {
+			router = mux.NewRouter()
+			router.Use(RateLimitByIP(nil, nil), RateLimitBySubject(nil))
+			router.HandleFunc("/version", ok)
+
+			for i := 0; i < 3; i++ {
+				sendRequest("10.0.0.5:51234", "agent")
+				Expect(w.Code).To(Equal(http.StatusOK))
+			}
+		})
+
+		It("Should limit the requests of a token subject", func() {
+			router = mux.NewRouter()
+			router.Use(RateLimitBySubject(ratelimit.NewLimiter(1, 2)))
+			router.HandleFunc("/version", ok)
+
+			sendRequest("10.0.0.5:51234", "agent")
+			sendRequest("10.0.0.6:51234", "agent")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			sendRequest("10.0.0.7:51234", "agent")
+			Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(w.Header().Get("Retry-After")).To(Equal("1"))
+
+			sendRequest("10.0.0.7:51234", "admin")
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Describe("Request body limit", func() {
+		var body []byte
+
+		BeforeEach(func() {
+			router = mux.NewRouter()
+			router.Use(LimitRequestBody(64))
+			db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+			SGXHostRegisterOps(router, db)
+			body, _ = json.Marshal(SGXHostInfo{HostName: "validtesthostname", Description: string(make([]byte, 128))})
+		})
+
+		registerHost := func(req *http.Request) {
+			req = context.SetUserRoles(req, []aas.RoleInfo{
+				{
+					Service: constants.ServiceName,
+					Name:    constants.HostDataUpdaterGroupName,
+					Context: "type=SHVS",
+				},
+			})
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+		}
+
+		It("Should reject a body announced larger than the limit", func() {
+			req, err := http.NewRequest(http.MethodPost, "/hosts", bytes.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			registerHost(req)
+
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(problem().Code).To(Equal(ErrorCodePayloadTooLarge))
+		})
+
+		It("Should stop reading a body of unknown length at the limit", func() {
+			req, err := http.NewRequest(http.MethodPost, "/hosts", ioutil.NopCloser(bytes.NewReader(body)))
+			Expect(err).NotTo(HaveOccurred())
+			req.ContentLength = -1
+			registerHost(req)
+
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(problem().Detail).To(Equal("Request body exceeds 64 bytes"))
+		})
+	})
+})
@@ -9,9 +9,17 @@ import (
 	"intel/isecl/lib/common/v5/auth"
 	"intel/isecl/lib/common/v5/context"
 	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/logging"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/requestid"
+	"intel/isecl/shvs/v5/tracing"
 	"net/http"
+	"strings"
 
+	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 	clog "intel/isecl/lib/common/v5/log"
 	commLogMsg "intel/isecl/lib/common/v5/log/message"
 	ct "intel/isecl/lib/common/v5/types/aas"
@@ -27,23 +35,46 @@ func (ehf errorHandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer log.Trace("resource/resource:ServeHTTP() Leaving")
 
 	if err := ehf(w, r); err != nil {
-		slog.WithError(err).Error("HTTP Error")
-		if gorm.IsRecordNotFoundError(err) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		switch t := err.(type) {
-		case *resourceError:
-			http.Error(w, t.Message, t.StatusCode)
-		case resourceError:
-			http.Error(w, t.Message, t.StatusCode)
-		case *privilegeError:
-			http.Error(w, t.Message, t.StatusCode)
-		case privilegeError:
-			http.Error(w, t.Message, t.StatusCode)
-		default:
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		requestLog(r, slog).WithError(err).Error("HTTP Error")
+		problem := errorProblem(r, err)
+		tracing.RecordError(r.Context(), err, problem.Status, problem.Code)
+		writeProblem(w, problem)
+	}
+}
+
+// requestHandlerFunc is a handler given the loggers of its request, tagged by requestLog, and the database
+// bound to the request context
+type requestHandlerFunc func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error
+
+// withRequest wraps the handler into an errorHandlerFunc giving it the request-scoped loggers and database,
+// db being nil for the handlers not using the database. The fields of the loggers are added to the HTTP log.
+func withRequest(db repository.SHVSDatabase, handler requestHandlerFunc) errorHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		requestDB := db
+		if db != nil {
+			requestDB = db.WithContext(r.Context())
 		}
+		logging.AddRequestFields(r.Context(), requestFields(r))
+		return handler(w, r, requestLog(r, log), requestLog(r, slog), requestDB)
+	}
+}
+
+// errorProblem gives the problem response of an error returned by a handler
+func errorProblem(r *http.Request, err error) Problem {
+	if gorm.IsRecordNotFoundError(err) {
+		return newProblem(r, http.StatusNotFound, "", err.Error(), nil)
+	}
+	switch t := err.(type) {
+	case *resourceError:
+		return newProblem(r, t.StatusCode, t.Code, t.Message, t.FieldErrors)
+	case resourceError:
+		return newProblem(r, t.StatusCode, t.Code, t.Message, t.FieldErrors)
+	case *privilegeError:
+		return newProblem(r, t.StatusCode, "", t.Message, nil)
+	case privilegeError:
+		return newProblem(r, t.StatusCode, "", t.Message, nil)
+	default:
+		return newProblem(r, http.StatusInternalServerError, "", err.Error(), nil)
 	}
 }
 
@@ -62,6 +93,10 @@ func (e privilegeError) Error() string {
 type resourceError struct {
 	StatusCode int
 	Message    string
+	// Code is the error code of the problem response, derived from the status code when not set
+	Code string
+	// FieldErrors lists the invalid fields of the request
+	FieldErrors []FieldError
 }
 
 func (e resourceError) Error() string {
@@ -71,10 +106,38 @@ func (e resourceError) Error() string {
 	return fmt.Sprintf("%d: %s", e.StatusCode, e.Message)
 }
 
+// requestLog adds the request ID, the user and, on the host endpoints, the host of the request to the log entry
+func requestLog(r *http.Request, entry *logrus.Entry) *logrus.Entry {
+	entry = requestid.Entry(r.Context(), entry)
+	fields := requestFields(r)
+	if len(fields) == 0 {
+		return entry
+	}
+	return entry.WithFields(fields)
+}
+
+// requestFields gives the user, the host and the trace of the request known to the handlers
+func requestFields(r *http.Request) logrus.Fields {
+	fields := logrus.Fields{}
+	if subject, err := context.GetTokenSubject(r); err == nil && subject != "" {
+		fields[logging.UserField] = subject
+	}
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil && strings.Contains(template, "/hosts/{id}") {
+			fields[logging.HostIDField] = mux.Vars(r)["id"]
+		}
+	}
+	if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+		fields[logging.TraceIDField] = spanContext.TraceID().String()
+	}
+	return fields
+}
+
 func authorizeEndpoint(r *http.Request, roleName string, retNilCtxForEmptyCtx bool) error {
 	log.Trace("resource/resource:authorizeEndpoint() Entering")
 	defer log.Trace("resource/resource:authorizeEndpoint() Leaving")
 
+	slog := requestLog(r, slog)
 	privileges, err := context.GetUserRoles(r)
 	if err != nil {
 		slog.WithError(err).Error("resource/resource: authorizeEndpoint() Failed to read roles and permissions")
@@ -6,6 +6,7 @@ package resource
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -488,6 +489,125 @@ var _ = Describe("GetHosts", func() {
 				router.ServeHTTP(w, req)
 				Expect(w.Code).To(Equal(http.StatusOK))
 			})
+
+			It("Should perform queryhosts - should stream hostdata as ndjson", func() {
+				host := types.Host{
+					ID:          uuid.New(),
+					Name:        "ndjsonhostname",
+					Description: "test description",
+					CreatedTime: time.Now(),
+					Deleted:     false,
+				}
+				createdHost, _ := db.HostRepository().Create(&host)
+				SGXHostRegisterOps(router, db)
+
+				validPath := fmt.Sprintf("/hosts?HostName=%s", createdHost.Name)
+				req, err := http.NewRequest(http.MethodGet, validPath, nil)
+				val := []aas.RoleInfo{
+					{
+						Service: constants.ServiceName,
+						Name:    constants.HostListReaderGroupName,
+						Context: "type=SHVS",
+					},
+				}
+				req = context.SetUserRoles(req, val)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeNDJSON)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeNDJSON))
+
+				var streamedHost types.HostInfo
+				err = json.Unmarshal(w.Body.Bytes(), &streamedHost)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(streamedHost.Name).To(Equal(createdHost.Name))
+			})
+
+			It("Should not stream hosts as ndjson - No data found", func() {
+				SGXHostRegisterOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts?HostName=unknownhostname", nil)
+				val := []aas.RoleInfo{
+					{
+						Service: constants.ServiceName,
+						Name:    constants.HostListReaderGroupName,
+						Context: "type=SHVS",
+					},
+				}
+				req = context.SetUserRoles(req, val)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeNDJSON)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+
+			It("Should perform queryhosts - should export hostdata as csv", func() {
+				host := types.Host{
+					ID:           uuid.New(),
+					Name:         "csvhostname",
+					HardwareUUID: uuid.New(),
+					Description:  "test description",
+					CreatedTime:  time.Now(),
+					Deleted:      false,
+				}
+				createdHost, _ := db.HostRepository().Create(&host)
+				SGXHostRegisterOps(router, db)
+
+				validPath := fmt.Sprintf("/hosts?HostName=%s", createdHost.Name)
+				req, err := http.NewRequest(http.MethodGet, validPath, nil)
+				val := []aas.RoleInfo{
+					{
+						Service: constants.ServiceName,
+						Name:    constants.HostListReaderGroupName,
+						Context: "type=SHVS",
+					},
+				}
+				req = context.SetUserRoles(req, val)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeCSV)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeCSV))
+
+				records, err := csv.NewReader(w.Body).ReadAll()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(records).To(HaveLen(2))
+				Expect(records[1][0]).To(Equal(createdHost.Name))
+				Expect(records[1][1]).To(Equal(createdHost.HardwareUUID.String()))
+			})
+
+			It("Should export the text fields of the hosts as csv without formulas", func() {
+				status := "=HYPERLINK(\"http://example.com\")"
+				record := hostInventoryCSVRecord(&types.HostInventory{HostName: "@SUM(A1)", Status: &status})
+				Expect(record[0]).To(Equal("'@SUM(A1)"))
+				Expect(record[2]).To(Equal("'" + status))
+			})
+
+			It("Should not export hosts as csv - No data found", func() {
+				SGXHostRegisterOps(router, db)
+
+				req, err := http.NewRequest(http.MethodGet, "/hosts?HostName=unknownhostname", nil)
+				val := []aas.RoleInfo{
+					{
+						Service: constants.ServiceName,
+						Name:    constants.HostListReaderGroupName,
+						Context: "type=SHVS",
+					},
+				}
+				req = context.SetUserRoles(req, val)
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeCSV)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
 		})
 
 		// DELETE hosts.
@@ -0,0 +1,273 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"intel/isecl/shvs/v5/config"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/resource"
+	"intel/isecl/shvs/v5/types"
+)
+
+// JobHandler runs a job claimed from the durable job queue
+type JobHandler func(ctx context.Context, payload types.JobPayload) error
+
+// JobDispatcher claims jobs from the durable job queue and runs them on the JobRunner. Every SHVS
+// instance runs a dispatcher so that the instances share the queued work.
+type JobDispatcher struct {
+	db           repository.SHVSDatabase
+	runner       *JobRunner
+	handlers     map[string]JobHandler
+	owner        string
+	pollInterval time.Duration
+	jobTimeout   time.Duration
+	retryDelay   time.Duration
+	slots        chan struct{}
+	wg           sync.WaitGroup
+
+	// running holds the cancel functions of the jobs dispatched by this instance
+	running      map[uuid.UUID]context.CancelFunc
+	runningMutex sync.Mutex
+}
+
+// NewJobDispatcher creates a dispatcher running at most slots jobs at a time. Non-positive values fall
+// back to the defaults.
+func NewJobDispatcher(db repository.SHVSDatabase, runner *JobRunner, slots int, pollInterval, jobTimeout time.Duration) *JobDispatcher {
+	if slots <= 0 {
+		slots = constants.DefaultJobWorkers
+	}
+	if pollInterval <= 0 {
+		pollInterval = constants.DefaultJobPollInterval
+	}
+	if jobTimeout <= 0 {
+		jobTimeout = constants.DefaultJobTimeout
+	}
+	hostname, _ := os.Hostname()
+
+	return &JobDispatcher{
+		db:           db,
+		runner:       runner,
+		handlers:     make(map[string]JobHandler),
+		owner:        fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		pollInterval: pollInterval,
+		jobTimeout:   jobTimeout,
+		retryDelay:   constants.DefaultJobRetryDelay,
+		slots:        make(chan struct{}, slots),
+		running:      make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// Handle registers the handler of a job type. Handlers must be registered before Start.
+func (d *JobDispatcher) Handle(jobType string, handler JobHandler) {
+	d.handlers[jobType] = handler
+}
+
+// Start polls the job queue until ctx is done
+func (d *JobDispatcher) Start(ctx context.Context) {
+	log.Trace("resource/scheduler/job_dispatcher: Start() Entering")
+	defer log.Trace("resource/scheduler/job_dispatcher: Start() Leaving")
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.pollInterval)
+		defer ticker.Stop()
+		for {
+			d.dispatch(ctx)
+			d.cancelRequested()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until polling has stopped and the outcome of every dispatched job has been stored
+func (d *JobDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// lease is how long a claimed job is held before other instances may claim it again. The job timeout
+// runs from the claim, including the wait for a worker, so the lease leaves headroom for the outcome
+// to be stored.
+func (d *JobDispatcher) lease() time.Duration {
+	return 2 * d.jobTimeout
+}
+
+func (d *JobDispatcher) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		select {
+		case d.slots <- struct{}{}:
+		default:
+			return
+		}
+
+		job, err := d.db.JobRepository().Claim(d.owner, d.lease())
+		if err != nil || job == nil {
+			<-d.slots
+			if err != nil {
+				log.WithError(err).Error("JobDispatcher: failed to claim job")
+			}
+			return
+		}
+		log.Debugf("JobDispatcher: claimed job %s of type %s, attempt %d", job.ID, job.Type, job.Attempts)
+
+		jobCtx, cancel := context.WithTimeout(context.Background(), d.jobTimeout)
+		d.runningMutex.Lock()
+		d.running[job.ID] = cancel
+		d.runningMutex.Unlock()
+
+		result, err := d.runner.Enqueue(jobCtx, Job{
+			Name:    job.Type,
+			Timeout: d.jobTimeout,
+			Func:    d.jobFunc(job),
+		})
+		if err != nil {
+			<-d.slots
+			d.finish(job, err)
+			return
+		}
+
+		d.wg.Add(1)
+		go func(job *types.Job) {
+			defer d.wg.Done()
+			res := <-result
+			<-d.slots
+			d.finish(job, res.Err)
+		}(job)
+	}
+}
+
+func (d *JobDispatcher) jobFunc(job *types.Job) JobFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		handler, ok := d.handlers[job.Type]
+		if !ok {
+			return nil, errors.Errorf("no handler registered for job type %s", job.Type)
+		}
+		return nil, handler(ctx, job.Payload)
+	}
+}
+
+// Cancel stops the job if it is running on this instance
+func (d *JobDispatcher) Cancel(id uuid.UUID) {
+	d.runningMutex.Lock()
+	defer d.runningMutex.Unlock()
+	if cancel, ok := d.running[id]; ok {
+		log.Infof("JobDispatcher: cancelling job %s", id)
+		cancel()
+	}
+}
+
+// cancelRequested cancels the running jobs which were cancelled through the job API on other instances
+func (d *JobDispatcher) cancelRequested() {
+	d.runningMutex.Lock()
+	ids := make([]uuid.UUID, 0, len(d.running))
+	for id := range d.running {
+		ids = append(ids, id)
+	}
+	d.runningMutex.Unlock()
+
+	for _, id := range ids {
+		job, err := d.db.JobRepository().Retrieve(&types.Job{ID: id})
+		if err != nil || job.Status != constants.JobStatusCancelled {
+			continue
+		}
+		d.runningMutex.Lock()
+		if cancel, ok := d.running[id]; ok {
+			log.Infof("JobDispatcher: cancelling job %s of type %s", job.ID, job.Type)
+			cancel()
+		}
+		d.runningMutex.Unlock()
+	}
+}
+
+// finish stores the outcome of a job. Failed jobs are queued again with a growing delay until they
+// run out of attempts. The outcome is dropped when the job was cancelled or claimed again meanwhile.
+func (d *JobDispatcher) finish(job *types.Job, jobErr error) {
+	d.runningMutex.Lock()
+	if cancel, ok := d.running[job.ID]; ok {
+		cancel()
+		delete(d.running, job.ID)
+	}
+	d.runningMutex.Unlock()
+
+	now := time.Now()
+	outcome := *job
+	outcome.Owner = ""
+	outcome.EndTime = &now
+	outcome.UpdatedTime = now
+	_, known := d.handlers[job.Type]
+	switch {
+	case jobErr == nil:
+		outcome.Status = constants.JobStatusCompleted
+		outcome.LastError = ""
+	case known && job.Attempts < job.MaxAttempts:
+		outcome.Status = constants.JobStatusQueued
+		outcome.LastError = jobErr.Error()
+		outcome.NextRunTime = now.Add(time.Duration(job.Attempts) * d.retryDelay)
+	default:
+		outcome.Status = constants.JobStatusError
+		outcome.LastError = jobErr.Error()
+	}
+
+	stored, err := d.db.JobRepository().CompareAndSwap(job, &outcome)
+	if err != nil {
+		log.WithError(err).Errorf("JobDispatcher: failed to store outcome of job %s", job.ID)
+	} else if !stored {
+		log.Infof("JobDispatcher: job %s was changed while running, dropping its outcome", job.ID)
+	}
+}
+
+// StartJobDispatcher starts the dispatcher of the durable job queue with the handlers of the SHVS job types
+func StartJobDispatcher(ctx context.Context, runner *JobRunner, db repository.SHVSDatabase, conf *config.Configuration) *JobDispatcher {
+	log.Trace("StartJobDispatcher: started")
+	defer log.Trace("StartJobDispatcher: Leaving")
+
+	d := NewJobDispatcher(db, runner, conf.JobRunner.Workers, conf.JobRunner.PollInterval, conf.JobRunner.JobTimeout)
+	d.Handle(constants.JobTypePolicyReevaluation, func(ctx context.Context, payload types.JobPayload) error {
+		return resource.ReevaluateHostPolicies(ctx, db)
+	})
+	d.Handle(constants.JobTypeComplianceReport, func(ctx context.Context, payload types.JobPayload) error {
+		_, inactiveHours, retentionDays := complianceReportSettings(config.Global())
+		err := shvsComplianceReportJobCB(ctx, db, inactiveHours, retentionDays)
+		if purgeErr := purgeFinishedJobs(db, conf.JobRunner.RetentionDays); err == nil {
+			err = purgeErr
+		}
+		return err
+	})
+	// runs the auto refresh sweeps retried through the job API, the scheduled sweeps are only recorded
+	d.Handle(constants.JobTypeAutoRefresh, func(ctx context.Context, payload types.JobPayload) error {
+		_, staleGrace := autoRefreshSettings(config.Global())
+		_, err := shvsAutoRefreshSchedulerJobCB(ctx, db, staleGrace)
+		return err
+	})
+	d.Start(ctx)
+	return d
+}
+
+// purgeFinishedJobs deletes the jobs which finished more than retentionDays ago, falling back to the default
+// retention when it is not positive. It runs along with the compliance report, the jobs being kept for days.
+func purgeFinishedJobs(db repository.SHVSDatabase, retentionDays int) error {
+	if retentionDays <= 0 {
+		retentionDays = constants.DefaultJobRetentionDays
+	}
+	deleted, err := db.JobRepository().DeleteFinishedBefore(time.Now().AddDate(0, 0, -retentionDays))
+	if err != nil {
+		return errors.Wrap(err, "purgeFinishedJobs: Error while purging finished jobs")
+	}
+	log.Debugf("purgeFinishedJobs: %d finished jobs purged", deleted)
+	return nil
+}
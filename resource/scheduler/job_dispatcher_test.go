@@ -0,0 +1,282 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/resource"
+	"intel/isecl/shvs/v5/types"
+)
+
+func newTestJobDispatcher(db repository.SHVSDatabase) (*JobDispatcher, *JobRunner) {
+	runner := NewJobRunner(2, 2, time.Second)
+	d := NewJobDispatcher(db, runner, 2, 5*time.Millisecond, time.Second)
+	d.retryDelay = time.Millisecond
+	return d, runner
+}
+
+func waitForJobStatus(t *testing.T, db repository.SHVSDatabase, id uuid.UUID, status string) *types.Job {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := db.JobRepository().Retrieve(&types.Job{ID: id})
+		if err == nil && job.Status == status {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not reach status %s", id, status)
+	return nil
+}
+
+func stopTestJobDispatcher(t *testing.T, cancel context.CancelFunc, d *JobDispatcher, runner *JobRunner) {
+	cancel()
+	assert.NoError(t, runner.Shutdown(context.Background()))
+	d.Wait()
+}
+
+func TestJobDispatcherRunsQueuedJob(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	d, runner := newTestJobDispatcher(db)
+
+	var received int32
+	d.Handle("test", func(ctx context.Context, payload types.JobPayload) error {
+		var value string
+		if err := json.Unmarshal(payload, &value); err != nil {
+			return err
+		}
+		if value == "payload" {
+			atomic.AddInt32(&received, 1)
+		}
+		return nil
+	})
+
+	job, err := resource.QueueJob(db, "test", "payload")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	completed := waitForJobStatus(t, db, job.ID, constants.JobStatusCompleted)
+	stopTestJobDispatcher(t, cancel, d, runner)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&received))
+	assert.Equal(t, 1, completed.Attempts)
+	assert.Empty(t, completed.Owner)
+}
+
+func TestJobDispatcherRetriesFailedJob(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	d, runner := newTestJobDispatcher(db)
+
+	var calls int32
+	d.Handle("flaky", func(ctx context.Context, payload types.JobPayload) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	d.Handle("broken", func(ctx context.Context, payload types.JobPayload) error {
+		return errors.New("permanent failure")
+	})
+
+	flaky, err := resource.QueueJob(db, "flaky", nil)
+	assert.NoError(t, err)
+	broken, err := resource.QueueJob(db, "broken", nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	completed := waitForJobStatus(t, db, flaky.ID, constants.JobStatusCompleted)
+	failed := waitForJobStatus(t, db, broken.ID, constants.JobStatusError)
+	stopTestJobDispatcher(t, cancel, d, runner)
+
+	assert.Equal(t, 2, completed.Attempts)
+	assert.Equal(t, constants.DefaultJobMaxAttempts, failed.Attempts)
+	assert.Equal(t, "permanent failure", failed.LastError)
+}
+
+func TestJobDispatcherFailsUnknownJobType(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	d, runner := newTestJobDispatcher(db)
+
+	job, err := resource.QueueJob(db, "unknown", nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	failed := waitForJobStatus(t, db, job.ID, constants.JobStatusError)
+	stopTestJobDispatcher(t, cancel, d, runner)
+
+	assert.Equal(t, 1, failed.Attempts)
+}
+
+func TestJobDispatcherReclaimsAbandonedJob(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	d, runner := newTestJobDispatcher(db)
+	d.Handle("test", func(ctx context.Context, payload types.JobPayload) error {
+		return nil
+	})
+
+	// claimed by an instance which died before the lease expired
+	abandoned := types.Job{
+		ID:          uuid.New(),
+		Type:        "test",
+		Status:      constants.JobStatusProcessing,
+		Attempts:    1,
+		MaxAttempts: constants.DefaultJobMaxAttempts,
+		Owner:       "crashed-instance",
+		NextRunTime: time.Now().Add(-time.Minute),
+	}
+	_, err := db.JobRepository().Create(&abandoned)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	completed := waitForJobStatus(t, db, abandoned.ID, constants.JobStatusCompleted)
+	stopTestJobDispatcher(t, cancel, d, runner)
+
+	assert.Equal(t, 2, completed.Attempts)
+}
+
+func TestJobDispatcherCancelsRunningJob(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	d, runner := newTestJobDispatcher(db)
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	d.Handle("long", func(ctx context.Context, payload types.JobPayload) error {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return ctx.Err()
+	})
+
+	job, err := resource.QueueJob(db, "long", nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	<-started
+
+	// cancelled through the job API
+	processing := waitForJobStatus(t, db, job.ID, constants.JobStatusProcessing)
+	cancelled := *processing
+	cancelled.Status = constants.JobStatusCancelled
+	swapped, err := db.JobRepository().CompareAndSwap(processing, &cancelled)
+	assert.NoError(t, err)
+	assert.True(t, swapped)
+
+	select {
+	case err = <-stopped:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("running job was not cancelled")
+	}
+	stopTestJobDispatcher(t, cancel, d, runner)
+
+	stored, err := db.JobRepository().Retrieve(&types.Job{ID: job.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.JobStatusCancelled, stored.Status)
+}
+
+func TestJobDispatcherTimesOutJob(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	runner := NewJobRunner(2, 2, time.Minute)
+	d := NewJobDispatcher(db, runner, 2, 5*time.Millisecond, 20*time.Millisecond)
+	d.retryDelay = time.Millisecond
+
+	// the handler ignores ctx, the outcome is stored at the job timeout nonetheless
+	release := make(chan struct{})
+	d.Handle("stuck", func(ctx context.Context, payload types.JobPayload) error {
+		<-release
+		return nil
+	})
+
+	job, err := resource.QueueJob(db, "stuck", nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	failed := waitForJobStatus(t, db, job.ID, constants.JobStatusError)
+	close(release)
+	stopTestJobDispatcher(t, cancel, d, runner)
+
+	assert.Equal(t, constants.DefaultJobMaxAttempts, failed.Attempts)
+	assert.Equal(t, context.DeadlineExceeded.Error(), failed.LastError)
+}
+
+func TestQueueComplianceReportOnce(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+
+	assert.NoError(t, resource.QueueComplianceReport(db))
+	assert.NoError(t, resource.QueueComplianceReport(db))
+	jobs, err := db.JobRepository().RetrieveAll(&types.Job{Type: constants.JobTypeComplianceReport}, constants.DefaultJobsLimit)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, constants.JobStatusQueued, jobs[0].Status)
+}
+
+func TestPurgeFinishedJobs(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	old := time.Now().AddDate(0, 0, -10)
+	for _, status := range []string{constants.JobStatusCompleted, constants.JobStatusError, constants.JobStatusQueued} {
+		_, err := db.JobRepository().Create(&types.Job{ID: uuid.New(), Type: constants.JobTypeComplianceReport,
+			Status: status, CreatedTime: old, UpdatedTime: old})
+		assert.NoError(t, err)
+	}
+	_, err := db.JobRepository().Create(&types.Job{ID: uuid.New(), Type: constants.JobTypeComplianceReport,
+		Status: constants.JobStatusCompleted, CreatedTime: time.Now(), UpdatedTime: time.Now()})
+	assert.NoError(t, err)
+
+	assert.NoError(t, purgeFinishedJobs(db, 7))
+	jobs, err := db.JobRepository().RetrieveAll(&types.Job{}, constants.DefaultJobsLimit)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	// queued jobs are kept however old they are
+	assert.Equal(t, constants.JobStatusCompleted, jobs[0].Status)
+	assert.Equal(t, constants.JobStatusQueued, jobs[1].Status)
+}
+
+func TestJobDispatcherCancelStopsLocalJob(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	runner := NewJobRunner(2, 2, time.Second)
+	// the dispatcher does not poll again during the test, so only Cancel can stop the job
+	d := NewJobDispatcher(db, runner, 2, time.Hour, time.Minute)
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	d.Handle("long", func(ctx context.Context, payload types.JobPayload) error {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return ctx.Err()
+	})
+
+	job, err := resource.QueueJob(db, "long", nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	<-started
+	d.Cancel(job.ID)
+
+	select {
+	case err = <-stopped:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("running job was not cancelled")
+	}
+	stopTestJobDispatcher(t, cancel, d, runner)
+}
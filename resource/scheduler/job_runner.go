@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	clog "intel/isecl/lib/common/v5/log"
+	"intel/isecl/shvs/v5/constants"
+)
+
+var log = clog.GetDefaultLogger()
+
+// ErrJobRunnerStopped is returned when a job is enqueued after the runner was shut down
+var ErrJobRunnerStopped = errors.New("job runner is stopped")
+
+// JobFunc is the work done by a job. It must return once ctx is done; the runner does not wait for it past
+// the timeout of the job and the result of a late return is dropped.
+type JobFunc func(ctx context.Context) (interface{}, error)
+
+// Job is a unit of work executed by the JobRunner. A zero Timeout uses the default timeout of the runner.
+type Job struct {
+	Name    string
+	Timeout time.Duration
+	Func    JobFunc
+}
+
+// JobResult is the outcome of a job, delivered once on the channel returned by Enqueue
+type JobResult struct {
+	Name      string
+	Value     interface{}
+	Err       error
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+type queuedJob struct {
+	ctx    context.Context
+	job    Job
+	result chan JobResult
+}
+
+// JobRunner dispatches enqueued jobs to a fixed pool of workers
+type JobRunner struct {
+	jobs    chan *queuedJob
+	timeout time.Duration
+
+	// ctx is cancelled when shutdown gives up waiting, aborting the jobs still running
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// stop is closed on shutdown so that Enqueue calls blocked on a full queue return
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu      sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewJobRunner starts a runner with the given number of workers, queue size and default job timeout.
+// Non-positive values fall back to the defaults.
+func NewJobRunner(workers, queueSize int, timeout time.Duration) *JobRunner {
+	log.Trace("resource/scheduler/job_runner: NewJobRunner() Entering")
+	defer log.Trace("resource/scheduler/job_runner: NewJobRunner() Leaving")
+
+	if workers <= 0 {
+		workers = constants.DefaultJobWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = constants.DefaultJobQueueSize
+	}
+	if timeout <= 0 {
+		timeout = constants.DefaultJobTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &JobRunner{
+		jobs:    make(chan *queuedJob, queueSize),
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+		stop:    make(chan struct{}),
+	}
+	r.wg.Add(workers)
+	for id := 1; id <= workers; id++ {
+		go r.worker(id)
+	}
+	return r
+}
+
+// Enqueue queues the job and returns the channel on which its result is delivered. It blocks while the
+// queue is full until ctx is done. The job is cancelled when ctx is done before it completes.
+func (r *JobRunner) Enqueue(ctx context.Context, job Job) (<-chan JobResult, error) {
+	if job.Func == nil {
+		return nil, errors.Errorf("Enqueue: job %s has no function", job.Name)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.stopped {
+		return nil, ErrJobRunnerStopped
+	}
+
+	qj := &queuedJob{ctx: ctx, job: job, result: make(chan JobResult, 1)}
+	select {
+	case r.jobs <- qj:
+		log.Debugf("Enqueue: job %s queued", job.Name)
+		return qj.result, nil
+	case <-r.stop:
+		return nil, ErrJobRunnerStopped
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "Enqueue: job %s was not queued", job.Name)
+	}
+}
+
+// Shutdown stops accepting jobs and waits for the queued and running jobs to complete. When ctx is done
+// first, the remaining jobs are cancelled and ctx's error is returned.
+func (r *JobRunner) Shutdown(ctx context.Context) error {
+	log.Trace("resource/scheduler/job_runner: Shutdown() Entering")
+	defer log.Trace("resource/scheduler/job_runner: Shutdown() Leaving")
+
+	// release the Enqueue calls waiting for room in the queue before taking the lock they hold
+	r.stopOnce.Do(func() { close(r.stop) })
+	r.mu.Lock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.jobs)
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		r.cancel()
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		return errors.Wrap(ctx.Err(), "Shutdown: jobs did not complete in time")
+	}
+}
+
+func (r *JobRunner) worker(id int) {
+	defer r.wg.Done()
+	for qj := range r.jobs {
+		res := r.run(qj)
+		if res.Err != nil {
+			log.WithError(res.Err).Errorf("Worker %d: job %s failed", id, res.Name)
+		} else {
+			log.Debugf("Worker %d: job %s completed in %s", id, res.Name, res.EndTime.Sub(res.StartTime))
+		}
+		qj.result <- res
+	}
+}
+
+func (r *JobRunner) run(qj *queuedJob) (res JobResult) {
+	res = JobResult{Name: qj.job.Name, StartTime: time.Now()}
+	defer func() {
+		res.EndTime = time.Now()
+	}()
+
+	timeout := qj.job.Timeout
+	if timeout <= 0 {
+		timeout = r.timeout
+	}
+	ctx, cancel := context.WithTimeout(qj.ctx, timeout)
+	defer cancel()
+
+	// abort the job when the runner gives up on a graceful shutdown
+	go func() {
+		select {
+		case <-r.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		res.Err = errors.Wrapf(err, "job %s was cancelled before it started", qj.job.Name)
+		return res
+	}
+
+	// the worker is released when ctx is done even if the job does not return in time
+	done := make(chan JobResult, 1)
+	go func() {
+		var value interface{}
+		var err error
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("job %s panicked: %v", qj.job.Name, p)
+			}
+			done <- JobResult{Value: value, Err: err}
+		}()
+		value, err = qj.job.Func(ctx)
+	}()
+
+	var jobRes JobResult
+	select {
+	case jobRes = <-done:
+	case <-ctx.Done():
+		select {
+		case jobRes = <-done:
+		default:
+			log.Warnf("Job %s did not return once cancelled, abandoning it", qj.job.Name)
+			jobRes.Err = ctx.Err()
+		}
+	}
+	res.Value, res.Err = jobRes.Value, jobRes.Err
+	return res
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobRunnerEnqueue(t *testing.T) {
+	r := NewJobRunner(2, 2, time.Second)
+	defer r.Shutdown(context.Background())
+
+	result, err := r.Enqueue(context.Background(), Job{
+		Name: "value",
+		Func: func(ctx context.Context) (interface{}, error) {
+			return 42, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	res := <-result
+	assert.NoError(t, res.Err)
+	assert.Equal(t, 42, res.Value)
+	assert.Equal(t, "value", res.Name)
+	assert.False(t, res.EndTime.Before(res.StartTime))
+
+	_, err = r.Enqueue(context.Background(), Job{Name: "nil"})
+	assert.Error(t, err)
+}
+
+func TestJobRunnerDispatchesToAllWorkers(t *testing.T) {
+	const workers = 4
+	r := NewJobRunner(workers, workers, time.Second)
+	defer r.Shutdown(context.Background())
+
+	var running int32
+	release := make(chan struct{})
+	allRunning := make(chan struct{})
+	var results []<-chan JobResult
+	for i := 0; i < workers; i++ {
+		result, err := r.Enqueue(context.Background(), Job{
+			Name: "concurrent",
+			Func: func(ctx context.Context) (interface{}, error) {
+				if atomic.AddInt32(&running, 1) == workers {
+					close(allRunning)
+				}
+				<-release
+				return nil, nil
+			},
+		})
+		assert.NoError(t, err)
+		results = append(results, result)
+	}
+
+	select {
+	case <-allRunning:
+	case <-time.After(5 * time.Second):
+		t.Fatal("jobs were not dispatched to all workers")
+	}
+	close(release)
+	for _, result := range results {
+		assert.NoError(t, (<-result).Err)
+	}
+}
+
+func TestJobRunnerJobTimeout(t *testing.T) {
+	r := NewJobRunner(1, 1, time.Minute)
+	defer r.Shutdown(context.Background())
+
+	result, err := r.Enqueue(context.Background(), Job{
+		Name:    "timeout",
+		Timeout: 20 * time.Millisecond,
+		Func: func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, context.DeadlineExceeded, (<-result).Err)
+}
+
+func TestJobRunnerCancelledByEnqueueContext(t *testing.T) {
+	r := NewJobRunner(1, 1, time.Minute)
+	defer r.Shutdown(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	result, err := r.Enqueue(ctx, Job{
+		Name: "cancelled",
+		Func: func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	assert.NoError(t, err)
+	<-started
+	cancel()
+	assert.Equal(t, context.Canceled, (<-result).Err)
+}
+
+func TestJobRunnerEnqueueBlocksWhenQueueIsFull(t *testing.T) {
+	r := NewJobRunner(1, 1, time.Minute)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	blocking := Job{
+		Name: "blocking",
+		Func: func(ctx context.Context) (interface{}, error) {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			return nil, nil
+		},
+	}
+
+	_, err := r.Enqueue(context.Background(), blocking)
+	assert.NoError(t, err)
+	<-started
+	_, err = r.Enqueue(context.Background(), blocking)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = r.Enqueue(ctx, blocking)
+	assert.Error(t, err)
+
+	close(release)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestJobRunnerRecoversFromPanic(t *testing.T) {
+	r := NewJobRunner(1, 1, time.Second)
+	defer r.Shutdown(context.Background())
+
+	result, err := r.Enqueue(context.Background(), Job{
+		Name: "panic",
+		Func: func(ctx context.Context) (interface{}, error) {
+			panic("unexpected")
+		},
+	})
+	assert.NoError(t, err)
+	res := <-result
+	assert.Error(t, res.Err)
+	assert.Contains(t, res.Err.Error(), "unexpected")
+
+	result, err = r.Enqueue(context.Background(), Job{
+		Name: "after-panic",
+		Func: func(ctx context.Context) (interface{}, error) {
+			return "ok", nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", (<-result).Value)
+}
+
+func TestJobRunnerShutdownDrainsQueue(t *testing.T) {
+	r := NewJobRunner(2, 10, time.Second)
+
+	var completed int32
+	var results []<-chan JobResult
+	for i := 0; i < 10; i++ {
+		result, err := r.Enqueue(context.Background(), Job{
+			Name: "drain",
+			Func: func(ctx context.Context) (interface{}, error) {
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&completed, 1)
+				return nil, nil
+			},
+		})
+		assert.NoError(t, err)
+		results = append(results, result)
+	}
+
+	assert.NoError(t, r.Shutdown(context.Background()))
+	assert.Equal(t, int32(10), atomic.LoadInt32(&completed))
+	for _, result := range results {
+		assert.NoError(t, (<-result).Err)
+	}
+
+	_, err := r.Enqueue(context.Background(), Job{
+		Name: "late",
+		Func: func(ctx context.Context) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	assert.Equal(t, ErrJobRunnerStopped, err)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestJobRunnerShutdownTimeoutCancelsJobs(t *testing.T) {
+	r := NewJobRunner(1, 1, time.Minute)
+
+	started := make(chan struct{})
+	result, err := r.Enqueue(context.Background(), Job{
+		Name: "long",
+		Func: func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	assert.NoError(t, err)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Error(t, r.Shutdown(ctx))
+	assert.Equal(t, context.Canceled, (<-result).Err)
+}
+
+func TestJobRunnerJobTimeoutWithoutCooperation(t *testing.T) {
+	r := NewJobRunner(1, 1, time.Minute)
+	release := make(chan struct{})
+	defer close(release)
+	defer r.Shutdown(context.Background())
+
+	result, err := r.Enqueue(context.Background(), Job{
+		Name:    "stuck",
+		Timeout: 20 * time.Millisecond,
+		Func: func(ctx context.Context) (interface{}, error) {
+			<-release
+			return "late", nil
+		},
+	})
+	assert.NoError(t, err)
+
+	select {
+	case res := <-result:
+		assert.Equal(t, context.DeadlineExceeded, res.Err)
+		assert.Nil(t, res.Value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("job timeout was not enforced")
+	}
+}
+
+func TestJobRunnerShutdownReleasesBlockedEnqueue(t *testing.T) {
+	r := NewJobRunner(1, 1, time.Minute)
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	blocking := Job{
+		Name: "blocking",
+		Func: func(ctx context.Context) (interface{}, error) {
+			started <- struct{}{}
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return nil, ctx.Err()
+		},
+	}
+
+	_, err := r.Enqueue(context.Background(), blocking)
+	assert.NoError(t, err)
+	<-started
+	_, err = r.Enqueue(context.Background(), blocking)
+	assert.NoError(t, err)
+
+	enqueued := make(chan error, 1)
+	go func() {
+		_, err := r.Enqueue(context.Background(), blocking)
+		enqueued <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- r.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-enqueued:
+		assert.Equal(t, ErrJobRunnerStopped, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked Enqueue was not released by Shutdown")
+	}
+	select {
+	case err := <-shutdown:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not honor its context")
+	}
+	close(release)
+}
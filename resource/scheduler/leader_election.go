@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+)
+
+// LeaderElector elects the SHVS instance running the periodic schedulers through a lock held by at
+// most one instance. The other instances keep trying to take the lock, so that one of them takes over
+// once the leader goes away.
+type LeaderElector struct {
+	lock     repository.LeaderLock
+	interval time.Duration
+	leader   int32
+	wg       sync.WaitGroup
+}
+
+// NewLeaderElector creates an elector trying to take the lock every interval. A non-positive interval
+// falls back to the default.
+func NewLeaderElector(lock repository.LeaderLock, interval time.Duration) *LeaderElector {
+	if interval <= 0 {
+		interval = constants.DefaultLeaderElectionInterval
+	}
+	return &LeaderElector{
+		lock:     lock,
+		interval: interval,
+	}
+}
+
+// Start takes part in the election until ctx is done, when the lock is released
+func (e *LeaderElector) Start(ctx context.Context) {
+	log.Trace("resource/scheduler/leader_election: Start() Entering")
+	defer log.Trace("resource/scheduler/leader_election: Start() Leaving")
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			e.elect(ctx)
+			select {
+			case <-ctx.Done():
+				e.setLeader(false)
+				if err := e.lock.Release(); err != nil {
+					log.WithError(err).Error("LeaderElector: failed to release the leader lock")
+				}
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// IsLeader reports whether this instance runs the periodic schedulers
+func (e *LeaderElector) IsLeader() bool {
+	return atomic.LoadInt32(&e.leader) == 1
+}
+
+// Wait blocks until the elector has stopped and released the lock
+func (e *LeaderElector) Wait() {
+	e.wg.Wait()
+}
+
+func (e *LeaderElector) elect(ctx context.Context) {
+	acquired, err := e.lock.TryAcquire(ctx)
+	if err != nil && ctx.Err() == nil {
+		log.WithError(err).Error("LeaderElector: failed to take the leader lock")
+	}
+	e.setLeader(acquired)
+}
+
+func (e *LeaderElector) setLeader(leader bool) {
+	var value int32
+	if leader {
+		value = 1
+	}
+	if atomic.SwapInt32(&e.leader, value) == value {
+		return
+	}
+	if leader {
+		log.Info("LeaderElector: this instance is now the leader and runs the periodic schedulers")
+	} else {
+		log.Info("LeaderElector: this instance is no longer the leader")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"intel/isecl/shvs/v5/repository/mock"
+)
+
+func waitForLeader(t *testing.T, e *LeaderElector, leader bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if e.IsLeader() == leader {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("elector did not reach leadership %t", leader)
+}
+
+func TestLeaderElectorSingleInstanceIsLeader(t *testing.T) {
+	e := NewLeaderElector(&mock.MockLeaderLock{}, 5*time.Millisecond)
+	assert.False(t, e.IsLeader())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.Start(ctx)
+	waitForLeader(t, e, true)
+
+	cancel()
+	e.Wait()
+	assert.False(t, e.IsLeader())
+}
+
+func TestLeaderElectorFailover(t *testing.T) {
+	lock := &mock.MockAdvisoryLock{}
+	first := NewLeaderElector(&mock.MockLeaderLock{Lock: lock}, 5*time.Millisecond)
+	second := NewLeaderElector(&mock.MockLeaderLock{Lock: lock}, 5*time.Millisecond)
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	first.Start(firstCtx)
+	waitForLeader(t, first, true)
+
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	second.Start(secondCtx)
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+
+	// the leader goes away and releases the lock
+	stopFirst()
+	first.Wait()
+	waitForLeader(t, second, true)
+	assert.False(t, first.IsLeader())
+
+	stopSecond()
+	second.Wait()
+}
@@ -5,66 +5,153 @@
 package scheduler
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
+	"intel/isecl/shvs/v5/config"
 	"intel/isecl/shvs/v5/constants"
 	"intel/isecl/shvs/v5/repository"
-	"intel/isecl/shvs/v5/resource"
+	"intel/isecl/shvs/v5/types"
 )
 
-func StartAutoRefreshSchedular(db repository.SHVSDatabase, timer int) {
+// StartAutoRefreshSchedular periodically queues a job that expires the hosts which stopped reporting, until
+// ctx is done. A sweep under way when ctx is done stops once its current transition completes.
+// The timer and the stale grace time are read from the global configuration, so that a configuration
+// reload applies to the following runs. Unlike the compliance reports, the sweep is not queued as a durable
+// job since a run lost to a restart is made up by the next one. Each run is recorded as a finished job
+// holding its summary though, so that the sweeps are listed by the job API along with the other jobs.
+func StartAutoRefreshSchedular(ctx context.Context, runner *JobRunner, elector *LeaderElector, db repository.SHVSDatabase) {
 	log.Trace("StartAutoRefreshSchedular: started")
 	defer log.Trace("StartAutoRefreshSchedular: Leaving")
-	stop := make(chan os.Signal)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
-		ticker := time.NewTicker(time.Second * time.Duration(timer))
+		interval, _ := autoRefreshSettings(config.Global())
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for {
 			select {
-			case <-stop:
-				fmt.Fprintln(os.Stderr, "StartAutoRefreshSchedular: Got Signal for exit and exiting.... Refresh Timer")
+			case <-ctx.Done():
+				log.Info("StartAutoRefreshSchedular: stopping auto refresh")
+				return
 			case t := <-ticker.C:
 				log.Debug("StartAutoRefreshSchedular: Timer started", t)
-				_, err := shvsAutoRefreshSchedulerJobCB(db)
+				updatedInterval, staleGrace := autoRefreshSettings(config.Global())
+				if updatedInterval != interval {
+					log.Infof("StartAutoRefreshSchedular: auto refresh timer changed to %s", updatedInterval)
+					interval = updatedInterval
+					ticker.Reset(interval)
+				}
+				if !elector.IsLeader() {
+					log.Debug("StartAutoRefreshSchedular: Not the leader, skipping auto refresh")
+					continue
+				}
+				_, err := runner.Enqueue(ctx, Job{
+					Name: constants.JobTypeAutoRefresh,
+					Func: func(jobCtx context.Context) (interface{}, error) {
+						summary, err := shvsAutoRefreshSchedulerJobCB(jobCtx, db, staleGrace)
+						recordAutoRefresh(db, summary, err)
+						return summary, err
+					},
+				})
+				if err == ErrJobRunnerStopped {
+					return
+				}
 				if err != nil {
-					log.WithError(err).Info("StartAutoRefreshSchedular: HostQueueScheduler got error")
+					log.WithError(err).Info("StartAutoRefreshSchedular: failed to queue auto refresh job")
 				}
 			}
 		}
 	}()
 }
 
-func shvsAutoRefreshSchedulerJobCB(db repository.SHVSDatabase) (bool, error) {
+// autoRefreshSettings returns the interval of the auto refresh and the stale grace time of the hosts
+func autoRefreshSettings(conf *config.Configuration) (time.Duration, time.Duration) {
+	timer := conf.SHVSRefreshTimer
+	if timer <= 0 {
+		timer = constants.DefaultSHVSAutoRefreshTimer
+	}
+	staleGraceMins := conf.SHVSHostStaleGraceTime
+	if staleGraceMins <= 0 {
+		staleGraceMins = constants.DefaultSHVSHostStaleGraceTime
+	}
+	return time.Duration(timer) * time.Second, time.Duration(staleGraceMins) * time.Minute
+}
+
+// hostExpirySummary is the outcome of one run of the auto refresh job
+type hostExpirySummary struct {
+	StartTime time.Time     `json:"-"`
+	Duration  time.Duration `json:"-"`
+	// Stale holds the hosts transitioned from CONNECTED to STALE
+	Stale []uuid.UUID `json:"stale_hosts"`
+	// Inactive holds the hosts transitioned from STALE to IN-ACTIVE
+	Inactive []uuid.UUID `json:"inactive_hosts"`
+}
 
+// shvsAutoRefreshSchedulerJobCB moves expired hosts to STALE and, once the grace time has passed as
+// well, to IN-ACTIVE. A failed transition does not keep the other from running; the next run sweeps
+// the hosts left behind, as it does when ctx is done between the transitions.
+func shvsAutoRefreshSchedulerJobCB(ctx context.Context, db repository.SHVSDatabase, staleGrace time.Duration) (*hostExpirySummary, error) {
 	log.Trace("shvsAutoRefreshSchedulerJobCB: Job stated")
 
-	expiredHosts, err := db.HostStatusRepository().RetrieveExpiredHosts()
-	if err != nil {
-		log.WithError(err).Info("StartAutoRefreshSchedular: Error in Get Host Status Repository")
-		return false, errors.New("StartAutoRefreshSchedular: Error in Get Host Status Repository")
+	var err error
+	summary := &hostExpirySummary{StartTime: time.Now()}
+	summary.Inactive, err = expireHosts(db, constants.HostStatusStale, constants.HostStatusInactive, staleGrace)
+	var staleErr error
+	if ctx.Err() != nil {
+		staleErr = errors.Wrap(ctx.Err(), "shvsAutoRefreshSchedulerJobCB: stopped before moving expired hosts to STALE")
+	} else {
+		summary.Stale, staleErr = expireHosts(db, constants.HostStatusConnected, constants.HostStatusStale, 0)
+	}
+	summary.Duration = time.Since(summary.StartTime)
+
+	log.Infof("shvsAutoRefreshSchedulerJobCB: %d hosts became %s and %d hosts became %s in %s", len(summary.Stale),
+		constants.HostStatusStale, len(summary.Inactive), constants.HostStatusInactive, summary.Duration)
+	if err == nil {
+		err = staleErr
 	}
+	return summary, err
+}
 
-	if len(expiredHosts) == 0 {
-		log.Debug("StartAutoRefreshSchedular: No Host is expired ............. Nothing to do")
-		return true, nil
+func expireHosts(db repository.SHVSDatabase, fromStatus, toStatus string, grace time.Duration) ([]uuid.UUID, error) {
+	hostIDs, err := db.HostStatusRepository().ExpireHosts(fromStatus, toStatus, grace)
+	if err != nil {
+		log.WithError(err).Errorf("shvsAutoRefreshSchedulerJobCB: failed to move expired hosts from %s to %s", fromStatus, toStatus)
+		return nil, errors.Wrapf(err, "shvsAutoRefreshSchedulerJobCB: Error while moving expired hosts to %s", toStatus)
 	}
-	log.Debug("shvsAutoRefreshSchedulerJobCB hosts found")
+	for _, hostID := range hostIDs {
+		log.Debugf("shvsAutoRefreshSchedulerJobCB: host %s moved from %s to %s", hostID, fromStatus, toStatus)
+	}
+	return hostIDs, nil
+}
 
-	// For each expired hosts change status in host_statuses = IN-ACTIVE
-	for i := 0; i < len(expiredHosts); i++ {
-		hostData := expiredHosts[i]
-		hostID := hostData.HostID
-		log.Debug("shvsAutoRefreshSchedulerJobCB hostID: is expired.", hostID)
-		err = resource.UpdateHostStatus(hostID, db, constants.HostStatusInactive)
-		if err != nil {
-			return false, errors.New("GetSGXDataFromAgentCB: Error while Updating Host Status Information: " + err.Error())
-		}
+// recordAutoRefresh stores a run of the auto refresh as a finished job, which can be retried through the
+// job API when it failed
+func recordAutoRefresh(db repository.SHVSDatabase, summary *hostExpirySummary, sweepErr error) {
+	result, err := json.Marshal(summary)
+	if err != nil {
+		log.WithError(err).Error("recordAutoRefresh: failed to marshal auto refresh summary")
+	}
+	endTime := summary.StartTime.Add(summary.Duration)
+	job := types.Job{
+		ID:          uuid.New(),
+		Type:        constants.JobTypeAutoRefresh,
+		Status:      constants.JobStatusCompleted,
+		Attempts:    1,
+		MaxAttempts: 1,
+		Result:      result,
+		NextRunTime: summary.StartTime,
+		StartTime:   &summary.StartTime,
+		EndTime:     &endTime,
+		CreatedTime: summary.StartTime,
+		UpdatedTime: endTime,
+	}
+	if sweepErr != nil {
+		job.Status = constants.JobStatusError
+		job.LastError = sweepErr.Error()
+	}
+	if _, err = db.JobRepository().Create(&job); err != nil {
+		log.WithError(err).Error("recordAutoRefresh: failed to record auto refresh job")
 	}
-	return true, nil
 }
@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+)
+
+func TestAutoRefreshExpiresHostsThroughStale(t *testing.T) {
+	expired := uuid.New()
+	staleInGrace := uuid.New()
+	staleAfterGrace := uuid.New()
+	hostStatusRepo := mock.MockHostStatusRepository{HostStatusRepo: []types.HostStatus{
+		{ID: uuid.New(), HostID: expired, Status: constants.HostStatusConnected, ExpiryTime: time.Now().Add(-time.Minute)},
+		{ID: uuid.New(), HostID: uuid.New(), Status: constants.HostStatusConnected, ExpiryTime: time.Now().Add(time.Hour)},
+		{ID: uuid.New(), HostID: staleInGrace, Status: constants.HostStatusStale, ExpiryTime: time.Now().Add(-10 * time.Minute)},
+		{ID: uuid.New(), HostID: staleAfterGrace, Status: constants.HostStatusStale, ExpiryTime: time.Now().Add(-time.Hour)},
+		{ID: uuid.New(), HostID: uuid.New(), Status: constants.HostStatusInactive, ExpiryTime: time.Now().Add(-time.Hour)},
+		{ID: uuid.New(), HostID: uuid.New(), Status: constants.HostStatusRemoved, ExpiryTime: time.Now().Add(-time.Hour)},
+	}}
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, hostStatusRepo, mock.MockHostSgxDataRepository{})
+
+	summary, err := shvsAutoRefreshSchedulerJobCB(context.Background(), db, 30*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{expired}, summary.Stale)
+	assert.Equal(t, []uuid.UUID{staleAfterGrace}, summary.Inactive)
+
+	// hosts are transitioned only once
+	summary, err = shvsAutoRefreshSchedulerJobCB(context.Background(), db, 30*time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, summary.Stale)
+	assert.Empty(t, summary.Inactive)
+
+	// staleInGrace turns inactive once the grace time has passed
+	summary, err = shvsAutoRefreshSchedulerJobCB(context.Background(), db, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{staleInGrace}, summary.Inactive)
+}
+
+func TestAutoRefreshStopsBetweenTransitionsWhenCancelled(t *testing.T) {
+	expired := uuid.New()
+	staleAfterGrace := uuid.New()
+	hostStatusRepo := mock.MockHostStatusRepository{HostStatusRepo: []types.HostStatus{
+		{ID: uuid.New(), HostID: expired, Status: constants.HostStatusConnected, ExpiryTime: time.Now().Add(-time.Minute)},
+		{ID: uuid.New(), HostID: staleAfterGrace, Status: constants.HostStatusStale, ExpiryTime: time.Now().Add(-time.Hour)},
+	}}
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, hostStatusRepo, mock.MockHostSgxDataRepository{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary, err := shvsAutoRefreshSchedulerJobCB(ctx, db, 30*time.Minute)
+	assert.Error(t, err)
+	assert.Equal(t, []uuid.UUID{staleAfterGrace}, summary.Inactive)
+	assert.Empty(t, summary.Stale)
+
+	// the next run picks up the hosts left behind
+	summary, err = shvsAutoRefreshSchedulerJobCB(context.Background(), db, 30*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{expired}, summary.Stale)
+}
+
+func TestAutoRefreshRunsAreRecordedAsJobs(t *testing.T) {
+	expired := uuid.New()
+	hostStatusRepo := mock.MockHostStatusRepository{HostStatusRepo: []types.HostStatus{
+		{ID: uuid.New(), HostID: expired, Status: constants.HostStatusConnected, ExpiryTime: time.Now().Add(-time.Minute)},
+	}}
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, hostStatusRepo, mock.MockHostSgxDataRepository{})
+
+	summary, err := shvsAutoRefreshSchedulerJobCB(context.Background(), db, 30*time.Minute)
+	recordAutoRefresh(db, summary, err)
+	recordAutoRefresh(db, &hostExpirySummary{StartTime: time.Now()}, errors.New("database is down"))
+
+	jobs, err := db.JobRepository().RetrieveAll(&types.Job{Type: constants.JobTypeAutoRefresh}, constants.DefaultJobsLimit)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, constants.JobStatusError, jobs[0].Status)
+	assert.Equal(t, "database is down", jobs[0].LastError)
+	assert.Equal(t, constants.JobStatusCompleted, jobs[1].Status)
+	assert.JSONEq(t, `{"stale_hosts": ["`+expired.String()+`"], "inactive_hosts": null}`, string(jobs[1].Result))
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"intel/isecl/shvs/v5/config"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/repository"
+	"intel/isecl/shvs/v5/resource"
+)
+
+// StartComplianceReportSchedular queues a durable job that stores a fleet compliance report and removes the
+// reports and finished jobs older than their configured retention whenever the newest stored report is older
+// than the interval, until ctx is done. The next report is worked out from the stored ones rather than from the start of the
+// process, so that restarts and leader changes do not put it off. Only the leader queues the job, which the
+// job dispatcher of any instance runs. The settings are read from the global configuration when the job
+// runs, so that a configuration reload applies to the following runs.
+func StartComplianceReportSchedular(ctx context.Context, elector *LeaderElector, db repository.SHVSDatabase) {
+	log.Trace("StartComplianceReportSchedular: started")
+	defer log.Trace("StartComplianceReportSchedular: Leaving")
+
+	go func() {
+		for {
+			wait := constants.ReportCheckInterval
+			if elector.IsLeader() {
+				interval, _, _ := complianceReportSettings(config.Global())
+				due, err := nextComplianceReport(db, interval, time.Now())
+				if err != nil {
+					log.WithError(err).Info("StartComplianceReportSchedular: failed to read the latest compliance report")
+				} else if due <= 0 {
+					err = resource.QueueComplianceReport(db)
+					if err != nil {
+						log.WithError(err).Info("StartComplianceReportSchedular: failed to queue compliance report job")
+					}
+				} else if due < wait {
+					wait = due
+				}
+			} else {
+				log.Debug("StartComplianceReportSchedular: Not the leader, skipping compliance report")
+			}
+
+			select {
+			case <-ctx.Done():
+				log.Info("StartComplianceReportSchedular: stopping compliance reports")
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// nextComplianceReport returns the time left until the next compliance report, which is due when it is 0 or
+// less, as it is when no report was stored
+func nextComplianceReport(db repository.SHVSDatabase, interval time.Duration, now time.Time) (time.Duration, error) {
+	latest, err := db.ComplianceReportRepository().RetrieveLatest()
+	if err != nil {
+		return 0, errors.Wrap(err, "nextComplianceReport: Error while retrieving the latest compliance report")
+	}
+	if latest == nil {
+		return 0, nil
+	}
+	return latest.CreatedTime.Add(interval).Sub(now), nil
+}
+
+// complianceReportSettings returns the interval of the compliance reports, the inactive host threshold in
+// hours and the report retention in days
+func complianceReportSettings(conf *config.Configuration) (time.Duration, int, int) {
+	timer := conf.ComplianceReport.Timer
+	if timer <= 0 {
+		timer = constants.DefaultReportTimer
+	}
+	inactiveHours := conf.ComplianceReport.InactiveHours
+	if inactiveHours <= 0 {
+		inactiveHours = constants.DefaultReportInactiveHours
+	}
+	retentionDays := conf.ComplianceReport.RetentionDays
+	if retentionDays <= 0 {
+		retentionDays = constants.DefaultReportRetentionDays
+	}
+	return time.Duration(timer) * time.Second, inactiveHours, retentionDays
+}
+
+func shvsComplianceReportJobCB(ctx context.Context, db repository.SHVSDatabase, inactiveHours, retentionDays int) error {
+	log.Trace("shvsComplianceReportJobCB: Job stated")
+
+	report, err := resource.GenerateComplianceReport(ctx, db, inactiveHours)
+	if err != nil {
+		return errors.Wrap(err, "shvsComplianceReportJobCB: Error while generating compliance report")
+	}
+	log.Infof("shvsComplianceReportJobCB: compliance report %s generated, %d of %d hosts compliant",
+		report.ID, report.CompliantHosts, report.TotalHosts)
+	if ctx.Err() != nil {
+		return errors.Wrap(ctx.Err(), "shvsComplianceReportJobCB: stopped before purging expired compliance reports")
+	}
+
+	deleted, err := db.ComplianceReportRepository().DeleteOlderThan(time.Now().AddDate(0, 0, -retentionDays))
+	if err != nil {
+		return errors.Wrap(err, "shvsComplianceReportJobCB: Error while purging expired compliance reports")
+	}
+	log.Debugf("shvsComplianceReportJobCB: %d expired compliance reports purged", deleted)
+	return nil
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"intel/isecl/shvs/v5/repository/mock"
+	"intel/isecl/shvs/v5/types"
+)
+
+func TestNextComplianceReportFollowsLatestReport(t *testing.T) {
+	db := mock.NewMockDatabase(mock.MockHostRepository{}, mock.MockHostStatusRepository{}, mock.MockHostSgxDataRepository{})
+	now := time.Now()
+
+	// the first report is due at once
+	due, err := nextComplianceReport(db, 24*time.Hour, now)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, due, time.Duration(0))
+
+	_, _ = db.ComplianceReportRepository().Create(&types.ComplianceReport{ID: uuid.New(), CreatedTime: now.Add(-30 * time.Hour)})
+	_, _ = db.ComplianceReportRepository().Create(&types.ComplianceReport{ID: uuid.New(), CreatedTime: now.Add(-20 * time.Hour)})
+	due, err = nextComplianceReport(db, 24*time.Hour, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 4*time.Hour, due)
+
+	// a report older than the interval, such as after a long downtime, is made up at once
+	due, err = nextComplianceReport(db, 12*time.Hour, now)
+	assert.NoError(t, err)
+	assert.Equal(t, -8*time.Hour, due)
+}
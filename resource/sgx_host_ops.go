@@ -13,10 +13,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"intel/isecl/lib/common/v5/context"
 	commLogMsg "intel/isecl/lib/common/v5/log/message"
 	"intel/isecl/shvs/v5/config"
@@ -37,10 +37,11 @@ type RegisterResponse struct {
 }
 
 type RegisterHostInfo struct {
-	HostID      uuid.UUID `json:"host_ID"`
-	HostName    string    `json:"host_name"`
-	Description string    `json:"description,omitempty"`
-	UUID        uuid.UUID `json:"uuid"`
+	HostID      uuid.UUID        `json:"host_ID"`
+	HostName    string           `json:"host_name"`
+	Description string           `json:"description,omitempty"`
+	UUID        uuid.UUID        `json:"uuid"`
+	Labels      types.HostLabels `json:"labels,omitempty"`
 }
 
 type SGXHostInfo struct {
@@ -53,6 +54,9 @@ type SGXHostInfo struct {
 	EpcOffset    string `json:"epc_offset"`
 	EpcSize      string `json:"epc_size"`
 	TcbUptodate  bool   `json:"tcb_upToDate"`
+	// Labels scope the roles allowed to read and delete the host, such as tenant: acme. The labels of a
+	// registered host are kept when omitted and removed when empty.
+	Labels types.HostLabels `json:"labels,omitempty"`
 }
 
 type AttReportThreadData struct {
@@ -60,9 +64,9 @@ type AttReportThreadData struct {
 	Conn repository.SHVSDatabase
 }
 
-var hostsSearchParams = map[string]bool{"getPlatformData": true, "getStatus": true, "HardwareUUID": true, "HostName": true}
+var hostsSearchParams = map[string]bool{"getPlatformData": true, "getStatus": true, "HardwareUUID": true, "HostName": true, "compliant": true}
 var hostsRetrieveParams = map[string]bool{"getPlatformData": true, "getStatus": true}
-var platformDataRetrieveParams = map[string]bool{"HostName": true, "numberOfMinutes": true}
+var platformDataRetrieveParams = map[string]bool{"HostName": true, "numberOfMinutes": true, "includeStale": true}
 
 const RowsNotFound = "no rows in result set"
 
@@ -70,20 +74,21 @@ func SGXHostRegisterOps(r *mux.Router, db repository.SHVSDatabase) {
 	log.Trace("resource/sgx_host_ops: SGXHostRegisterOps() Entering")
 	defer log.Trace("resource/sgx_host_ops: SGXHostRegisterOps() Leaving")
 
-	r.Handle("/hosts", handlers.ContentTypeHandler(registerHost(db), "application/json")).Methods("POST")
-	r.Handle("/hosts/{id}", handlers.ContentTypeHandler(getHosts(db), "application/json")).Methods("GET")
-	r.Handle("/hosts", handlers.ContentTypeHandler(queryHosts(db), "application/json")).Methods("GET")
-	r.Handle("/platform-data", handlers.ContentTypeHandler(getPlatformData(db), "application/json")).Methods("GET")
-	r.Handle("/host-status", handlers.ContentTypeHandler(getHostStateInformation(db), "application/json")).Methods("GET")
-	r.Handle("/hosts/{id}", deleteHost(db)).Methods("DELETE")
+	r.Handle("/hosts", contentTypeHandler(audited(constants.AuditActionHostRegister, db, registerHost(db)), "application/json")).Methods("POST")
+	r.Handle("/hosts/{id}", contentTypeHandler(getHosts(db), "application/json")).Methods("GET")
+	r.Handle("/hosts", contentTypeHandler(queryHosts(db), "application/json")).Methods("GET")
+	r.Handle("/platform-data", contentTypeHandler(getPlatformData(db), "application/json")).Methods("GET")
+	r.Handle("/host-status", contentTypeHandler(getHostStateInformation(db), "application/json")).Methods("GET")
+	r.Handle("/hosts/{id}", audited(constants.AuditActionHostDelete, db, deleteHost(db))).Methods("DELETE")
+	r.Handle("/hosts/{id}/heartbeat", heartbeatHost(db)).Methods("POST")
 }
 
 func getHosts(db repository.SHVSDatabase) errorHandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) error {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
 		log.Trace("resource/sgx_host_ops: getHosts() Entering")
 		defer log.Trace("resource/sgx_host_ops: getHosts() Leaving")
 
-		err := authorizeEndpoint(r, constants.HostListReaderGroupName, true)
+		scopes, err := authorizeHostEndpoint(r, constants.HostListReaderGroupName)
 		if err != nil {
 			return err
 		}
@@ -112,6 +117,17 @@ func getHosts(db repository.SHVSDatabase) errorHandlerFunc {
 			return &resourceError{Message: "Host with given id don't exist",
 				StatusCode: http.StatusNotFound}
 		}
+		// hosts out of the scopes of the roles are not disclosed
+		if !scopes.allows(extHost.Name, extHost.Labels) {
+			slog.Infof("%s: Host %s out of the scope of the roles of: %s", commLogMsg.UnauthorizedAccess, id, r.RemoteAddr)
+			return &resourceError{Message: "Host with given id don't exist",
+				StatusCode: http.StatusNotFound}
+		}
+
+		verdict, err := db.HostPolicyVerdictRepository().Retrieve(&types.HostPolicyVerdict{HostID: extHost.ID})
+		if err == nil {
+			extHost.Compliance = verdict
+		}
 
 		// Write the output here.
 		w.Header().Set("Content-Type", "application/json")
@@ -129,15 +145,15 @@ func getHosts(db repository.SHVSDatabase) errorHandlerFunc {
 		}
 		slog.Infof("%s: Host retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 		return nil
-	}
+	})
 }
 
 func queryHosts(db repository.SHVSDatabase) errorHandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) error {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
 		log.Trace("resource/sgx_host_ops: queryHosts() Entering")
 		defer log.Trace("resource/sgx_host_ops: queryHosts() Leaving")
 
-		err := authorizeEndpoint(r, constants.HostListReaderGroupName, true)
+		scopes, err := authorizeHostEndpoint(r, constants.HostListReaderGroupName)
 		if err != nil {
 			return err
 		}
@@ -176,12 +192,28 @@ func queryHosts(db repository.SHVSDatabase) errorHandlerFunc {
 			Name:         hostName,
 		}
 
+		if acceptsMediaType(r, constants.HTTPMediaTypeNDJSON) {
+			return streamHosts(w, r, db, &filter, criteria, scopes)
+		}
+		if acceptsMediaType(r, constants.HTTPMediaTypeCSV) {
+			return writeHostsCSV(w, r, db, &filter, criteria, scopes)
+		}
+
 		hostData, err := db.HostRepository().GetHostQuery(&filter, criteria)
 
 		if err != nil {
 			log.WithError(err).WithField("filter", filter).Info("failed to retrieve hosts")
 			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
 		}
+		if scopes != nil {
+			scopedHostData := make([]*types.HostInfo, 0, len(hostData))
+			for _, host := range hostData {
+				if scopes.allows(host.Name, host.Labels) {
+					scopedHostData = append(scopedHostData, host)
+				}
+			}
+			hostData = scopedHostData
+		}
 		if len(hostData) == 0 {
 			log.Error("resource/sgx_host_ops: queryHosts() no data is found")
 			return &resourceError{Message: "no host is found", StatusCode: http.StatusNotFound}
@@ -200,15 +232,15 @@ func queryHosts(db repository.SHVSDatabase) errorHandlerFunc {
 		}
 		slog.Infof("%s: Host searched by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 		return nil
-	}
+	})
 }
 
 func getPlatformData(db repository.SHVSDatabase) errorHandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) error {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
 		log.Trace("resource/sgx_host_ops: getPlatformData() Entering")
 		defer log.Trace("resource/sgx_host_ops: getPlatformData() Leaving")
 
-		err := authorizeEndpoint(r, constants.HostDataReaderGroupName, true)
+		scopes, err := authorizeHostEndpoint(r, constants.HostDataReaderGroupName)
 		if err != nil {
 			return err
 		}
@@ -218,8 +250,23 @@ func getPlatformData(db repository.SHVSDatabase) errorHandlerFunc {
 			return &resourceError{Message: err.Error(), StatusCode: http.StatusBadRequest}
 		}
 
+		// STALE hosts missed their refresh recently and are only returned on request
+		statuses := []string{constants.HostStatusConnected}
+		if r.URL.Query().Get("includeStale") != "" {
+			includeStale, err := strconv.ParseBool(r.URL.Query().Get("includeStale"))
+			if err != nil {
+				slog.WithError(err).Errorf("resource/sgx_host_ops: getPlatformData() %s : Invalid includeStale value", commLogMsg.InvalidInputBadParam)
+				return &resourceError{Message: "getPlatformData: Invalid query Param Data",
+					StatusCode: http.StatusBadRequest}
+			}
+			if includeStale {
+				statuses = append(statuses, constants.HostStatusStale)
+			}
+		}
+
 		var platformData *types.HostsSgxData
 		response := make([]map[string]interface{}, 0)
+		ndjson := acceptsMediaType(r, constants.HTTPMediaTypeNDJSON)
 		hostName := r.URL.Query().Get("HostName")
 		if hostName != "" {
 			if !validateInputString(constants.HostName, hostName) {
@@ -234,15 +281,19 @@ func getPlatformData(db repository.SHVSDatabase) errorHandlerFunc {
 				log.WithError(err).WithField("HostName", hostName).Info("failed to retrieve hosts")
 				return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
 			}
+			if !scopes.allows(hostData.Name, hostData.Labels) {
+				slog.Infof("%s: Host %s out of the scope of the roles of: %s", commLogMsg.UnauthorizedAccess, hostName, r.RemoteAddr)
+				return &resourceError{Message: "no host is found", StatusCode: http.StatusNotFound}
+			}
 			rs1 := types.HostSgxData{HostID: hostData.ID}
-			platformData, err = db.HostSgxDataRepository().RetrieveAll(&rs1)
+			platformData, err = db.HostSgxDataRepository().RetrieveAll(&rs1, statuses)
 			if err != nil {
 				log.WithError(err).WithField("HostName", hostName).Info("failed to retrieve host data")
 				return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
 			}
 
 			hostStatus := types.HostStatus{HostID: hostData.ID}
-			nonExpiredHosts, err := db.HostStatusRepository().RetrieveNonExpiredHost(&hostStatus)
+			nonExpiredHosts, err := db.HostStatusRepository().RetrieveInStatus(&hostStatus, statuses)
 			if err != nil {
 				log.WithError(err).WithField("HostName", hostName).Info("failed to retrieve host status.")
 				return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
@@ -273,36 +324,39 @@ func getPlatformData(db repository.SHVSDatabase) errorHandlerFunc {
 						StatusCode: http.StatusBadRequest}
 				}
 			}
-			// Get all the hosts from host_statuses which are updated recently and status="CONNECTED"
+			// Get all the hosts from host_statuses which are updated recently and status="CONNECTED", or "STALE" when requested
 			m, _ := time.ParseDuration(numberOfMinutes + "m")
 			updatedTime := time.Now().Add(-m)
 
-			var err error
-			platformData, err = db.HostSgxDataRepository().GetPlatformData(updatedTime)
+			hostIDs, err := scopes.hostIDs(db)
+			if err != nil {
+				log.WithError(err).Info("getPlatformData: failed to retrieve the hosts in scope")
+				return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+			}
+			if ndjson {
+				return streamPlatformData(w, r, db, updatedTime, statuses, hostIDs)
+			}
+
+			platformData, err = db.HostSgxDataRepository().GetPlatformData(updatedTime, statuses)
 			if err != nil {
 				log.WithError(err).WithField("numberOfMinutes", updatedTime).Info("getPlatformData: failed to retrieve updated hosts")
 				return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
 			}
 			for _, platformDataForOneHost := range *platformData {
+				if !hostIDs.allows(platformDataForOneHost.HostID) {
+					continue
+				}
 				hostStatus := types.HostStatus{HostID: platformDataForOneHost.HostID}
-				nonExpiredHosts, err := db.HostStatusRepository().RetrieveNonExpiredHost(&hostStatus)
+				nonExpiredHosts, err := db.HostStatusRepository().RetrieveInStatus(&hostStatus, statuses)
 				if err != nil {
 					log.WithError(err).WithField("numberOfMinutes", platformDataForOneHost.HostID).Info("getPlatformData: failed to retrieve host status")
 					continue
 				}
-				expiryTimeInString := (nonExpiredHosts.ExpiryTime).Format(time.RFC3339)
-				marshalledData, err := json.Marshal(platformDataForOneHost)
+				newPlatformData, err := platformDataRow(&platformDataForOneHost, nonExpiredHosts.ExpiryTime)
 				if err != nil {
-					log.Error("getPlatformData: Error marshalling the platform data")
+					log.WithError(err).Error("getPlatformData: Error building the platform data")
 					continue
 				}
-				var newPlatformData map[string]interface{}
-				err = json.Unmarshal(marshalledData, &newPlatformData)
-				if err != nil {
-					log.Error("getPlatformData: Error unmarshalling the platform data")
-					continue
-				}
-				newPlatformData[constants.ExpiryTimeKeyName] = expiryTimeInString
 				response = append(response, newPlatformData)
 			}
 		}
@@ -311,6 +365,19 @@ func getPlatformData(db repository.SHVSDatabase) errorHandlerFunc {
 			log.Info("getPlatformDataCB: no platform data has been updated")
 		}
 
+		if ndjson {
+			nw := newNDJSONWriter(w)
+			for _, platformDataForOneHost := range response {
+				if err := nw.Write(platformDataForOneHost); err != nil {
+					log.WithError(err).Error("getPlatformData: Error writing the platform data")
+					return nil
+				}
+			}
+			nw.Close()
+			slog.Infof("%s: Host platform data retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+			return nil
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK) // HTTP 200
 		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
@@ -324,15 +391,89 @@ func getPlatformData(db repository.SHVSDatabase) errorHandlerFunc {
 		}
 		slog.Infof("%s: Host platform data retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 		return nil
+	})
+}
+
+func streamHosts(w http.ResponseWriter, r *http.Request, db repository.SHVSDatabase, filter *types.Host,
+	criteria *types.HostInfoFetchCriteria, scopes hostScopes) error {
+	log.Trace("resource/sgx_host_ops: streamHosts() Entering")
+	defer log.Trace("resource/sgx_host_ops: streamHosts() Leaving")
+
+	nw := newNDJSONWriter(w)
+	err := db.HostRepository().StreamHostQuery(filter, criteria, func(host *types.HostInfo) error {
+		if !scopes.allows(host.Name, host.Labels) {
+			return nil
+		}
+		return nw.Write(host)
+	})
+	if err != nil {
+		log.WithError(err).WithField("filter", filter).Info("failed to stream hosts")
+		if nw.Rows() == 0 {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		// the status has already been sent, the client sees a truncated stream
+		return nil
+	}
+	if nw.Rows() == 0 {
+		log.Error("resource/sgx_host_ops: streamHosts() no data is found")
+		return &resourceError{Message: "no host is found", StatusCode: http.StatusNotFound}
 	}
+	nw.Close()
+	slog.Infof("%s: Host searched by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return nil
+}
+
+func streamPlatformData(w http.ResponseWriter, r *http.Request, db repository.SHVSDatabase, updatedTime time.Time, statuses []string,
+	hostIDs hostIDsInScope) error {
+	log.Trace("resource/sgx_host_ops: streamPlatformData() Entering")
+	defer log.Trace("resource/sgx_host_ops: streamPlatformData() Leaving")
+
+	nw := newNDJSONWriter(w)
+	err := db.HostSgxDataRepository().StreamPlatformData(updatedTime, statuses, func(platformData *types.PlatformData) error {
+		if !hostIDs.allows(platformData.HostID) {
+			return nil
+		}
+		row, err := platformDataRow(&platformData.HostSgxData, platformData.ExpiryTime)
+		if err != nil {
+			log.WithError(err).Error("streamPlatformData: Error building the platform data")
+			return nil
+		}
+		return nw.Write(row)
+	})
+	if err != nil {
+		log.WithError(err).WithField("numberOfMinutes", updatedTime).Info("streamPlatformData: failed to stream updated hosts")
+		if nw.Rows() == 0 {
+			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
+		}
+		return nil
+	}
+	nw.Close()
+	slog.Infof("%s: Host platform data retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return nil
+}
+
+// platformDataRow is the platform data of a host as listed by getPlatformData, with the expiry time of its
+// status in RFC3339, the same in the JSON array and the NDJSON stream
+func platformDataRow(data *types.HostSgxData, expiryTime time.Time) (map[string]interface{}, error) {
+	marshalledData, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "platformDataRow: Error marshalling the platform data")
+	}
+	var row map[string]interface{}
+	err = json.Unmarshal(marshalledData, &row)
+	if err != nil {
+		return nil, errors.Wrap(err, "platformDataRow: Error unmarshalling the platform data")
+	}
+	row[constants.ExpiryTimeKeyName] = expiryTime.Format(time.RFC3339)
+	return row, nil
 }
 
 func deleteHost(db repository.SHVSDatabase) errorHandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) error {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
 		log.Trace("resource/sgx_host_ops: deleteHost() Entering")
 		defer log.Trace("resource/sgx_host_ops: deleteHost() Leaving")
 
-		err := authorizeEndpoint(r, constants.HostListManagerGroupName, true)
+		scopes, err := authorizeHostEndpoint(r, constants.HostListManagerGroupName)
 		if err != nil {
 			return err
 		}
@@ -349,12 +490,19 @@ func deleteHost(db repository.SHVSDatabase) errorHandlerFunc {
 			w.WriteHeader(http.StatusNoContent)
 			return nil
 		}
+		// hosts out of the scopes of the roles are left alone as if they did not exist
+		if !scopes.allows(extHost.Name, extHost.Labels) {
+			slog.Infof("%s: Host %s out of the scope of the roles of: %s", commLogMsg.UnauthorizedAccess, id, r.RemoteAddr)
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
 
 		host := types.Host{
 			ID:           extHost.ID,
 			Name:         extHost.Name,
 			Description:  extHost.Description,
 			HardwareUUID: extHost.HardwareUUID,
+			Labels:       extHost.Labels,
 			CreatedTime:  extHost.CreatedTime,
 			UpdatedTime:  time.Now(),
 			Deleted:      true,
@@ -363,18 +511,18 @@ func deleteHost(db repository.SHVSDatabase) errorHandlerFunc {
 		if err != nil {
 			return errors.New("deleteHost: Error while Updating Host Information: " + err.Error())
 		}
-		slog.WithField("user", extHost).Info("User deleted by:", r.RemoteAddr)
-		err = UpdateHostStatus(extHost.ID, db, constants.HostStatusRemoved)
+		slog.Infof("%s: Host %s deleted by: %s", commLogMsg.AuthorizedAccess, extHost.ID, r.RemoteAddr)
+		err = UpdateHostStatus(log, extHost.ID, extHost.HardwareUUID, db, constants.HostStatusRemoved)
 		if err != nil {
 			return errors.New("deleteHost: Error while Updating Host Status Information: " + err.Error())
 		}
 		w.WriteHeader(http.StatusNoContent)
 		w.Header().Add(constants.HstsHeaderKey, constants.HstsHeaderValue)
 		return nil
-	}
+	})
 }
 
-func updateSGXHostInfo(db repository.SHVSDatabase, existingHostData *types.Host, hostInfo RegisterHostInfo) error {
+func updateSGXHostInfo(log *logrus.Entry, db repository.SHVSDatabase, existingHostData *types.Host, hostInfo RegisterHostInfo) error {
 	log.Trace("resource/sgx_host_ops: updateSGXHostInfo() Entering")
 	defer log.Trace("resource/sgx_host_ops: updateSGXHostInfo() Leaving")
 
@@ -383,6 +531,7 @@ func updateSGXHostInfo(db repository.SHVSDatabase, existingHostData *types.Host,
 		Name:         hostInfo.HostName,
 		Description:  hostInfo.Description,
 		HardwareUUID: hostInfo.UUID,
+		Labels:       hostInfo.Labels,
 		CreatedTime:  existingHostData.CreatedTime,
 		UpdatedTime:  time.Now(),
 
@@ -393,7 +542,7 @@ func updateSGXHostInfo(db repository.SHVSDatabase, existingHostData *types.Host,
 		return errors.New("updateSGXHostInfo: Error while Updating Host Information: " + err.Error())
 	}
 
-	err = UpdateHostStatus(existingHostData.ID, db, constants.HostStatusConnected)
+	err = UpdateHostStatus(log, existingHostData.ID, hostInfo.UUID, db, constants.HostStatusConnected)
 	if err != nil {
 		log.WithError(err).Info("updateSGXHostInfo failed")
 		return errors.New("updateSGXHostInfo: Error while Updating Host Status Information: " + err.Error())
@@ -401,7 +550,20 @@ func updateSGXHostInfo(db repository.SHVSDatabase, existingHostData *types.Host,
 	return nil
 }
 
-func createSGXHostInfo(db repository.SHVSDatabase, hostInfo RegisterHostInfo) (uuid.UUID, error) {
+// hostChanged tells whether the registration changes the description or the labels of the host
+func hostChanged(host *types.Host, hostInfo RegisterHostInfo) bool {
+	if host.Description != hostInfo.Description || len(host.Labels) != len(hostInfo.Labels) {
+		return true
+	}
+	for key, value := range hostInfo.Labels {
+		if hostValue, ok := host.Labels[key]; !ok || hostValue != value {
+			return true
+		}
+	}
+	return false
+}
+
+func createSGXHostInfo(log *logrus.Entry, db repository.SHVSDatabase, hostInfo RegisterHostInfo) (uuid.UUID, error) {
 	log.Trace("resource/sgx_host_ops: createSGXHostInfo() Entering")
 	defer log.Trace("resource/sgx_host_ops: createSGXHostInfo() Leaving")
 
@@ -411,6 +573,7 @@ func createSGXHostInfo(db repository.SHVSDatabase, hostInfo RegisterHostInfo) (u
 		Name:         hostInfo.HostName,
 		Description:  hostInfo.Description,
 		HardwareUUID: hostInfo.UUID,
+		Labels:       hostInfo.Labels,
 		CreatedTime:  time.Now(),
 		UpdatedTime:  time.Now(),
 	}
@@ -424,16 +587,13 @@ func createSGXHostInfo(db repository.SHVSDatabase, hostInfo RegisterHostInfo) (u
 		return uuid.Nil, errors.Wrap(errors.New("createSGXHostInfo: Configuration pointer is null"), "Config error")
 	}
 
-	expiryTimeInt := conf.SHVSHostInfoExpiryTime
-	expiryTimeDuration, _ := time.ParseDuration(strconv.Itoa(expiryTimeInt) + "m")
-
 	hostStatus := types.HostStatus{
 		ID:          uuid.New(),
 		HostID:      hostID,
 		Status:      constants.HostStatusConnected,
 		CreatedTime: time.Now(),
 		UpdatedTime: time.Now(),
-		ExpiryTime:  time.Now().Add(expiryTimeDuration),
+		ExpiryTime:  time.Now().Add(hostExpiryDuration(conf, hostInfo.UUID)),
 	}
 	_, err = db.HostStatusRepository().Create(&hostStatus)
 	if err != nil {
@@ -462,11 +622,11 @@ func sendHostRegisterResponse(w http.ResponseWriter, res RegisterResponse) error
 }
 
 func registerHost(db repository.SHVSDatabase) errorHandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) error {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
 		log.Trace("resource/sgx_host_ops: registerHost() Entering")
 		defer log.Trace("resource/sgx_host_ops: registerHost() Leaving")
 
-		err := authorizeEndpoint(r, constants.HostDataUpdaterGroupName, true)
+		scopes, err := authorizeHostEndpoint(r, constants.HostDataUpdaterGroupName)
 		if err != nil {
 			return err
 		}
@@ -475,10 +635,8 @@ func registerHost(db repository.SHVSDatabase) errorHandlerFunc {
 		var data SGXHostInfo
 		if r.ContentLength == 0 {
 			slog.Error("resource/sgx_host_ops: registerHost() The request body was not provided")
-			res = RegisterResponse{HTTPStatus: http.StatusBadRequest,
-				Response: ResponseJSON{Status: "Failed",
-					Message: "registerHost: No request data"}}
-			return sendHostRegisterResponse(w, res)
+			return &resourceError{Message: "registerHost: No request data", StatusCode: http.StatusBadRequest,
+				Code: ErrorCodeInvalidBody}
 		}
 
 		dec := json.NewDecoder(r.Body)
@@ -486,32 +644,41 @@ func registerHost(db repository.SHVSDatabase) errorHandlerFunc {
 		err = dec.Decode(&data)
 		if err != nil {
 			slog.WithError(err).Errorf("resource/sgx_host_ops: registerHost() %s :  Failed to decode request body", commLogMsg.InvalidInputBadEncoding)
-			res = RegisterResponse{HTTPStatus: http.StatusBadRequest,
-				Response: ResponseJSON{Status: "Failed",
-					Message: "registerHost: Invalid Json Post Data"}}
-			return sendHostRegisterResponse(w, res)
+			if tooLarge := bodyTooLargeError(err); tooLarge != nil {
+				return tooLarge
+			}
+			return &resourceError{Message: "registerHost: Invalid Json Post Data", StatusCode: http.StatusBadRequest,
+				Code: ErrorCodeInvalidBody}
 		}
 
 		log.Debug("Calling registerHost.................", data)
 
 		hardwareUUID, err := uuid.Parse(data.UUID)
 
-		if !validateInputString(constants.HostName, data.HostName) || err != nil ||
-			!validateInputString(constants.Description, data.Description) {
+		var fieldErrors []FieldError
+		if !validateInputString(constants.HostName, data.HostName) {
+			fieldErrors = append(fieldErrors, FieldError{Field: "host_name", Message: "invalid host name"})
+		}
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: "uuid", Message: "invalid hardware UUID"})
+		}
+		if !validateInputString(constants.Description, data.Description) {
+			fieldErrors = append(fieldErrors, FieldError{Field: "description", Message: "invalid description"})
+		}
+		if !validateHostLabels(data.Labels) {
+			fieldErrors = append(fieldErrors, FieldError{Field: "labels", Message: "invalid labels"})
+		}
+		if len(fieldErrors) > 0 {
 			slog.Error("resource/sgx_host_ops: registerHost() Input validation failed")
-			res = RegisterResponse{HTTPStatus: http.StatusBadRequest,
-				Response: ResponseJSON{Status: "Failed",
-					Message: "registerHost: Invalid query Param Data"}}
-			return sendHostRegisterResponse(w, res)
+			return &resourceError{Message: "registerHost: Invalid query Param Data", StatusCode: http.StatusBadRequest,
+				Code: ErrorCodeInvalidInput, FieldErrors: fieldErrors}
 		}
 
 		tokenSubject, err := context.GetTokenSubject(r)
 		if err != nil || tokenSubject != data.UUID {
 			slog.Errorf("resource/sgx_host_ops: registerHost() %s : Failed to match host identity from token", commLogMsg.AuthenticationFailed)
-			res = RegisterResponse{HTTPStatus: http.StatusUnauthorized,
-				Response: ResponseJSON{Status: "Failed",
-					Message: "registerHost: Invalid Token"}}
-			return sendHostRegisterResponse(w, res)
+			return &resourceError{Message: "registerHost: Invalid Token", StatusCode: http.StatusUnauthorized,
+				Code: ErrorCodeTokenMismatch}
 		}
 
 		host := &types.Host{
@@ -522,39 +689,50 @@ func registerHost(db repository.SHVSDatabase) errorHandlerFunc {
 			Description: data.Description,
 			HostName:    data.HostName,
 			UUID:        hardwareUUID,
+			Labels:      data.Labels,
 		}
 
+		event := auditEvent(r)
+		event.Detail = "host_name=" + data.HostName
 		existingHostData, err := db.HostRepository().RetrieveAnyIfExists(host)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			slog.Error("resource/sgx_host_ops: registerHost() Error retrieving data from database")
-			res = RegisterResponse{HTTPStatus: http.StatusInternalServerError,
-				Response: ResponseJSON{Status: "Failed",
-					Message: "registerHost: Error retrieving data from database"}}
-			return sendHostRegisterResponse(w, res)
+			return &resourceError{Message: "registerHost: Error retrieving data from database", StatusCode: http.StatusInternalServerError}
+		}
+		// agents which do not send labels keep the labels of their host
+		if data.Labels == nil && existingHostData != nil {
+			hostInfo.Labels = existingHostData.Labels
+		}
+		// an agent with a scoped role only registers hosts in its scope, and cannot label a host out of it
+		if !scopes.allows(hostInfo.HostName, hostInfo.Labels) {
+			slog.Infof("%s: Host %s with labels out of the scope of the roles of: %s", commLogMsg.UnauthorizedAccess,
+				data.HostName, r.RemoteAddr)
+			return &resourceError{Message: "registerHost: Host or labels out of the scope of the role",
+				StatusCode: http.StatusForbidden}
 		}
 		if existingHostData != nil {
 			if !strings.EqualFold(existingHostData.HardwareUUID.String(), tokenSubject) {
 				slog.Errorf("resource/sgx_host_ops: registerHost() %s : Failed to match host identity from database", commLogMsg.AuthenticationFailed)
-				res = RegisterResponse{HTTPStatus: http.StatusUnauthorized,
-					Response: ResponseJSON{Status: "Failed",
-						Message: "registerHost: Invalid Token"}}
-				return sendHostRegisterResponse(w, res)
+				return &resourceError{Message: "registerHost: Invalid Token", StatusCode: http.StatusUnauthorized,
+					Code: ErrorCodeTokenMismatch}
 			}
 
-			err = updateSGXHostInfo(db, existingHostData, hostInfo)
+			event.Target = existingHostData.ID.String()
+			event.Action = constants.AuditActionHostUpdate
+			if existingHostData.Deleted {
+				event.Action = constants.AuditActionHostRestore
+			} else if !hostChanged(existingHostData, hostInfo) {
+				// agents re-register periodically, only the registrations changing the host are audited
+				skipAudit(r)
+			}
+			err = updateSGXHostInfo(log, db, existingHostData, hostInfo)
 			if err != nil {
-				res = RegisterResponse{HTTPStatus: http.StatusInternalServerError,
-					Response: ResponseJSON{Status: "Failed",
-						Message: "registerHost: " + err.Error()}}
-				return sendHostRegisterResponse(w, res)
+				return &resourceError{Message: "registerHost: " + err.Error(), StatusCode: http.StatusInternalServerError}
 			}
 
-			err = pushSGXEnablementInfoToDB(existingHostData.ID, db, &data)
+			err = pushSGXEnablementInfoToDB(log, existingHostData.ID, db, &data)
 			if err != nil {
-				res = RegisterResponse{HTTPStatus: http.StatusInternalServerError,
-					Response: ResponseJSON{Status: "Failed",
-						Message: "registerHost: " + err.Error()}}
-				return sendHostRegisterResponse(w, res)
+				return &resourceError{Message: "registerHost: " + err.Error(), StatusCode: http.StatusInternalServerError}
 			}
 			res = RegisterResponse{HTTPStatus: http.StatusOK,
 				Response: ResponseJSON{Status: "Success",
@@ -562,19 +740,14 @@ func registerHost(db repository.SHVSDatabase) errorHandlerFunc {
 					Message: "SGX Host Data Updated Successfully"}}
 			return sendHostRegisterResponse(w, res)
 		} else {
-			hostID, err := createSGXHostInfo(db, hostInfo)
+			hostID, err := createSGXHostInfo(log, db, hostInfo)
 			if err != nil {
-				res = RegisterResponse{HTTPStatus: http.StatusInternalServerError,
-					Response: ResponseJSON{Status: "Failed",
-						Message: "registerHost: " + err.Error()}}
-				return sendHostRegisterResponse(w, res)
+				return &resourceError{Message: "registerHost: " + err.Error(), StatusCode: http.StatusInternalServerError}
 			}
-			err = pushSGXEnablementInfoToDB(hostID, db, &data)
+			event.Target = hostID.String()
+			err = pushSGXEnablementInfoToDB(log, hostID, db, &data)
 			if err != nil {
-				res = RegisterResponse{HTTPStatus: http.StatusInternalServerError,
-					Response: ResponseJSON{Status: "Failed",
-						Message: "registerHost: " + err.Error()}}
-				return sendHostRegisterResponse(w, res)
+				return &resourceError{Message: "registerHost: " + err.Error(), StatusCode: http.StatusInternalServerError}
 			}
 			res = RegisterResponse{HTTPStatus: http.StatusCreated,
 				Response: ResponseJSON{Status: "Success",
@@ -582,10 +755,10 @@ func registerHost(db repository.SHVSDatabase) errorHandlerFunc {
 					Message: "SGX Host Data Created Successfully"}}
 			return sendHostRegisterResponse(w, res)
 		}
-	}
+	})
 }
 
-func pushSGXEnablementInfoToDB(hostID uuid.UUID, db repository.SHVSDatabase, hostInfo *SGXHostInfo) error {
+func pushSGXEnablementInfoToDB(log *logrus.Entry, hostID uuid.UUID, db repository.SHVSDatabase, hostInfo *SGXHostInfo) error {
 	log.Trace("resource/sgx_atte_report_ops: pushSGXEnablementInfo() Entering")
 	defer log.Trace("resource/sgx_atte_report_ops: pushSGXEnablementInfo() Leaving")
 
@@ -595,10 +768,11 @@ func pushSGXEnablementInfoToDB(hostID uuid.UUID, db repository.SHVSDatabase, hos
 
 	hostSGXData, err := db.HostSgxDataRepository().Retrieve(hostData)
 
+	var sgxData types.HostSgxData
 	if hostSGXData == nil || err != nil {
 		log.Debug("resource/sgx_host_ops: No host record found will create new one")
 
-		sgxData := types.HostSgxData{
+		sgxData = types.HostSgxData{
 			ID:           uuid.New(),
 			HostID:       hostID,
 			SgxSupported: hostInfo.SgxSupported,
@@ -612,7 +786,7 @@ func pushSGXEnablementInfoToDB(hostID uuid.UUID, db repository.SHVSDatabase, hos
 		_, err = db.HostSgxDataRepository().Create(&sgxData)
 	} else {
 		log.Debug("resource/sgx_host_ops: Host record found will update existing one")
-		sgxData := types.HostSgxData{
+		sgxData = types.HostSgxData{
 			ID:           hostSGXData.ID,
 			HostID:       hostID,
 			SgxSupported: hostInfo.SgxSupported,
@@ -628,6 +802,13 @@ func pushSGXEnablementInfoToDB(hostID uuid.UUID, db repository.SHVSDatabase, hos
 	if err != nil {
 		return errors.Wrap(err, "resource/sgx_host_ops: Error in creating host sgx data")
 	}
+
+	// the platform data is committed by now, a failed evaluation leaves the previous verdict in place until
+	// the next registration or policy reevaluation rather than failing the registration
+	err = evaluateHostPolicies(log, hostID, db, &sgxData)
+	if err != nil {
+		log.WithError(err).WithField("hostID", hostID).Error("resource/sgx_host_ops: Error in evaluating host policies")
+	}
 	return nil
 }
 
@@ -666,6 +847,13 @@ func populateHostInfoFetchCriteria(params url.Values) (*types.HostInfoFetchCrite
 		}
 		criteria.GetStatus = getStatus
 	}
+	if params.Get("compliant") != "" {
+		compliant, err := strconv.ParseBool(params.Get("compliant"))
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid compliant query param value, must be boolean")
+		}
+		criteria.Compliant = &compliant
+	}
 
 	return &criteria, nil
 }
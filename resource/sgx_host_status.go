@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
 type HostStatusResponse struct {
@@ -25,11 +26,11 @@ type HostStatusResponse struct {
 var hostStatusRetrieveParams = map[string]bool{"hostId": true}
 
 func getHostStateInformation(db repository.SHVSDatabase) errorHandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) error {
+	return withRequest(db, func(w http.ResponseWriter, r *http.Request, log, slog *logrus.Entry, db repository.SHVSDatabase) error {
 		log.Trace("resource/sgx_host_status: getHostStateInformation() Entering")
 		defer log.Trace("resource/sgx_host_status: getHostStateInformation() Leaving")
 
-		err := authorizeEndpoint(r, constants.HostDataReaderGroupName, true)
+		scopes, err := authorizeHostEndpoint(r, constants.HostDataReaderGroupName)
 		if err != nil {
 			return err
 		}
@@ -61,6 +62,10 @@ func getHostStateInformation(db repository.SHVSDatabase) errorHandlerFunc {
 			}
 			return &resourceError{Message: err.Error(), StatusCode: http.StatusInternalServerError}
 		}
+		if !scopes.allowsID(db, hostStatusData.HostID) {
+			slog.Infof("%s: Host %s out of the scope of the roles of: %s", commLogMsg.UnauthorizedAccess, hostStatusData.HostID, r.RemoteAddr)
+			return &resourceError{Message: "Status of host with given id does not exist", StatusCode: http.StatusBadRequest}
+		}
 		log.Debug("hostStatusData", hostStatusData)
 
 		var hostStatuses []HostStatusResponse
@@ -83,5 +88,5 @@ func getHostStateInformation(db repository.SHVSDatabase) errorHandlerFunc {
 		}
 		slog.Infof("%s: Host status retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 		return nil
-	}
+	})
 }
@@ -7,7 +7,10 @@ package resource
 import (
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,7 +21,8 @@ import (
 
 var statusUpdateLock *sync.Mutex
 
-func UpdateHostStatus(hostID uuid.UUID, db repository.SHVSDatabase, status string) error {
+// UpdateHostStatus sets the status of the host and restarts its expiry, logging to the log of the request
+func UpdateHostStatus(log *logrus.Entry, hostID, hardwareUUID uuid.UUID, db repository.SHVSDatabase, status string) error {
 	log.Trace("resource/utils: UpdateHostStatus() Entering")
 	defer log.Trace("resource/utils: UpdateHostStatus() Leaving")
 
@@ -43,16 +47,13 @@ func UpdateHostStatus(hostID uuid.UUID, db repository.SHVSDatabase, status strin
 		return errors.Wrap(errors.New("UpdateHostStatus: Configuration pointer is null"), "Config error")
 	}
 
-	expiryTimeInt := conf.SHVSHostInfoExpiryTime
-	expiryTimeDuration, _ := time.ParseDuration(strconv.Itoa(expiryTimeInt) + "m")
-
 	hostStatus = types.HostStatus{
 		ID:          existingHostStatusRec.ID,
 		HostID:      hostID,
 		Status:      status,
 		CreatedTime: existingHostStatusRec.CreatedTime,
 		UpdatedTime: time.Now(),
-		ExpiryTime:  time.Now().Add(expiryTimeDuration),
+		ExpiryTime:  time.Now().Add(hostExpiryDuration(conf, hardwareUUID)),
 	}
 
 	err = db.HostStatusRepository().Update(&hostStatus)
@@ -63,3 +64,37 @@ func UpdateHostStatus(hostID uuid.UUID, db repository.SHVSDatabase, status strin
 	statusUpdateLock.Unlock()
 	return nil
 }
+
+// hostExpiryDuration returns how long the status of the host with the given hardware UUID stays valid,
+// taking the per host overrides of the configuration into account
+func hostExpiryDuration(conf *config.Configuration, hardwareUUID uuid.UUID) time.Duration {
+	expiryTimeInt := conf.SHVSHostInfoExpiryTime
+	for overrideUUID, overrideMins := range conf.HostExpiryOverrides {
+		if overrideMins > 0 && strings.EqualFold(overrideUUID, hardwareUUID.String()) {
+			expiryTimeInt = overrideMins
+			break
+		}
+	}
+	expiryTimeDuration, _ := time.ParseDuration(strconv.Itoa(expiryTimeInt) + "m")
+	return expiryTimeDuration
+}
+
+// acceptsMediaType reports whether the Accept header of the request lists the given media type
+func acceptsMediaType(r *http.Request, mediaType string) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, acceptedType := range strings.Split(accept, ",") {
+			if strings.TrimSpace(strings.Split(acceptedType, ";")[0]) == mediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// valueOrEmpty dereferences s, returning an empty string for nil
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
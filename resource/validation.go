@@ -6,6 +6,7 @@ package resource
 
 import (
 	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/types"
 	"regexp"
 )
 
@@ -15,6 +16,11 @@ var regExMap = map[string]*regexp.Regexp{
 	constants.ID:          regexp.MustCompile(`([a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}){1}`),
 	constants.HostID:      regexp.MustCompile(`([a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}){1}`),
 	constants.HostStatus:  regexp.MustCompile(`^[A-Za-z]*$`),
+	constants.PolicyName:  regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-]{0,63}$`),
+	constants.PolicyDesc:  regexp.MustCompile(`^[0-9a-zA-Z ,.()&=<>\-]{0,255}$`),
+	constants.JobType:     regexp.MustCompile(`^[a-z0-9][a-z0-9\-]{0,63}$`),
+	constants.LabelKey:    regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-/]{0,62}$`),
+	constants.LabelValue:  regexp.MustCompile(`^[A-Za-z0-9_.\-]{0,63}$`),
 	constants.UUID:        regexp.MustCompile(`([a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}){1}`)}
 
 func validateInputString(key, inString string) bool {
@@ -28,3 +34,17 @@ func validateInputString(key, inString string) bool {
 	}
 	return true
 }
+
+// validateHostLabels checks the keys and values of the labels of a host, of which there are at most
+// constants.MaxHostLabels
+func validateHostLabels(labels types.HostLabels) bool {
+	if len(labels) > constants.MaxHostLabels {
+		return false
+	}
+	for key, value := range labels {
+		if !validateInputString(constants.LabelKey, key) || !validateInputString(constants.LabelValue, value) {
+			return false
+		}
+	}
+	return true
+}
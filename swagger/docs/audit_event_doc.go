@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package docs
+
+import (
+	"intel/isecl/shvs/v5/types"
+)
+
+// AuditEvents response payload
+// swagger:response AuditEvents
+type SwaggAuditEvents struct {
+	// in:body
+	Body types.AuditEvents
+}
+
+// swagger:operation GET /audit-events AuditEvent queryAuditEvents
+// ---
+// description: |
+//   Retrieves the audit events recorded for the administrative actions taken on SHVS, newest first. An event
+//   tells who took the action, with which roles, on which host, policy or job, and whether the action
+//   succeeded, failed or was denied. The actions are host-register, host-update, host-restore, host-delete,
+//   policy-create, policy-update, policy-delete, job-retry, job-cancel and config-reload. A successful
+//   host-update is recorded only when the registration changes the description or the labels of the host,
+//   not for the periodic re-registrations of the agents.
+//   The events are also appended to the hash-chained audit log file, which is checked by 'shvs audit verify'.
+//   A valid bearer token with the AuditReader role is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: action
+//   description: Action of the events.
+//   in: query
+//   type: string
+// - name: subject
+//   description: Token subject the actions were taken with.
+//   in: query
+//   type: string
+// - name: target
+//   description: ID of the host, policy or job the actions were taken on.
+//   in: query
+//   type: string
+// - name: result
+//   description: Result of the actions.
+//   in: query
+//   type: string
+//   enum: [SUCCESS, FAILURE, DENIED]
+// - name: fromTime
+//   description: RFC3339 time of the oldest events.
+//   in: query
+//   type: string
+//   format: date-time
+// - name: toTime
+//   description: RFC3339 time of the newest events.
+//   in: query
+//   type: string
+//   format: date-time
+// - name: limit
+//   description: Maximum number of events, 100 by default and at most 1000.
+//   in: query
+//   type: integer
+// responses:
+//   '200':
+//     description: Successfully retrieved the audit events.
+//     schema:
+//       "$ref": "#/definitions/AuditEvents"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/audit-events?action=host-delete
+// x-sample-call-output: |
+//  [
+//      {
+//          "id": "0b6f5c1e-3a7d-4b2e-8f9a-6c5d4e3b2a19",
+//          "time": "2022-03-01T10:00:00.100221Z",
+//          "subject": "admin",
+//          "roles": ["SHVS:HostListManager:type=SHVS"],
+//          "action": "host-delete",
+//          "target": "f1d2c4d0-4c1b-4b8e-9a3b-2f9a3c1e5d21",
+//          "result": "SUCCESS",
+//          "request_id": "4a5b6c7d-8e9f-4a0b-9c1d-2e3f4a5b6c7d",
+//          "remote_addr": "10.0.0.5:51234"
+//      }
+//  ]
+// ---
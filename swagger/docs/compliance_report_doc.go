@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package docs
+
+import (
+	"intel/isecl/shvs/v5/types"
+)
+
+// ComplianceReport response payload
+// swagger:response ComplianceReport
+type SwaggComplianceReport struct {
+	// in:body
+	Body types.ComplianceReport
+}
+
+// ComplianceReports response payload
+// swagger:response ComplianceReports
+type SwaggComplianceReports struct {
+	// in:body
+	Body types.ComplianceReports
+}
+
+// swagger:operation GET /reports ComplianceReport queryComplianceReports
+// ---
+// description: |
+//   Retrieves the summaries of the fleet compliance reports generated by the SHVS scheduler, newest first.
+//   Reports older than the configured retention are purged automatically. A report covers all hosts, so the
+//   roles scoped to some hosts by a labels or hostname context cannot read it.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the compliance reports.
+//     schema:
+//       "$ref": "#/definitions/ComplianceReports"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/reports
+// x-sample-call-output: |
+//  [
+//      {
+//          "id": "2fbf5e1b-4c6f-4d4c-9a4a-0f2d7a1c2b3e",
+//          "created_time": "2022-09-20T10:00:00.000000Z",
+//          "total_hosts": 3,
+//          "compliant_hosts": 2,
+//          "sgx_disabled_hosts": 0,
+//          "tcb_out_of_date_hosts": 1,
+//          "inactive_hosts": 0
+//      }
+//  ]
+// ---
+
+// swagger:operation GET /reports/{id} ComplianceReport getComplianceReport
+// ---
+// description: |
+//   Retrieves a compliance report along with the list of non-compliant hosts. A host is non-compliant
+//   when SGX is disabled, its TCB is out of date or it has been IN-ACTIVE longer than the configured threshold.
+//   The roles scoped to some hosts cannot read the reports.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the compliance report.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully retrieved the compliance report.
+//     schema:
+//       "$ref": "#/definitions/ComplianceReport"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/reports/2fbf5e1b-4c6f-4d4c-9a4a-0f2d7a1c2b3e
+// x-sample-call-output: |
+//  {
+//      "id": "2fbf5e1b-4c6f-4d4c-9a4a-0f2d7a1c2b3e",
+//      "created_time": "2022-09-20T10:00:00.000000Z",
+//      "total_hosts": 3,
+//      "compliant_hosts": 2,
+//      "sgx_disabled_hosts": 0,
+//      "tcb_out_of_date_hosts": 1,
+//      "inactive_hosts": 0,
+//      "non_compliant_hosts": [
+//          {
+//              "host_ID": "58cee2f3-d694-48ba-b8d2-e541544f5e22",
+//              "host_name": "sgx-host-1",
+//              "uuid": "88888888-8887-0f15-0106-1211b4d3b7fb",
+//              "status": "CONNECTED",
+//              "sgx_disabled": false,
+//              "tcb_out_of_date": true,
+//              "inactive": false,
+//              "last_seen_time": "2022-09-20T09:55:00.000000Z"
+//          }
+//      ]
+//  }
+// ---
@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package docs
+
+import (
+	"intel/isecl/shvs/v5/resource"
+)
+
+// ConfigReloadStatus response payload
+// swagger:response ConfigReloadStatus
+type SwaggConfigReloadStatus struct {
+	// in:body
+	Body resource.ConfigReloadStatus
+}
+
+// swagger:operation POST /admin/config/reload Config reloadConfig
+// ---
+// description: |
+//   Reads the SHVS configuration file again and applies it without a restart, as sending SIGHUP to the
+//   service does. The log level, the scheduler timers, the host expiry times and the per host expiry
+//   overrides take effect right away. The other changed settings, such as the port or the database, are
+//   listed in restart_required and take effect on the next restart. An invalid configuration is rejected
+//   and the current configuration is kept.
+//   A valid bearer token with the ConfigAdministrator role is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// responses:
+//   '200':
+//     description: Successfully reloaded the configuration.
+//     schema:
+//       "$ref": "#/definitions/ConfigReloadStatus"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/admin/config/reload
+// x-sample-call-output: |
+//  {
+//      "status": "reloaded",
+//      "restart_required": [
+//          "Port"
+//      ]
+//  }
+// ---
@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package docs
+
+import (
+	"intel/isecl/shvs/v5/resource"
+)
+
+// HealthStatus response payload
+// swagger:response HealthStatus
+type SwaggHealthStatus struct {
+	// in:body
+	Body resource.HealthStatus
+}
+
+// swagger:operation GET /health Health GetHealth
+// ---
+// description: |
+//   GetHealth reports that the SHVS instance is up and whether it is the leader. Only the leader
+//   runs the periodic auto refresh and compliance report schedulers. The leader holds a Postgres
+//   advisory lock, which another instance takes over when the leader goes away.
+//
+// produces:
+//   - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the health status.
+//     schema:
+//       "$ref": "#/definitions/HealthStatus"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/health
+// x-sample-call-output: |
+//   {
+//       "status": "UP",
+//       "leader": true
+//   }
+// ---
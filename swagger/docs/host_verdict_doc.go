@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package docs
+
+import (
+	"intel/isecl/shvs/v5/types"
+)
+
+// HostVerdict claims carried in the signed host verdict
+// swagger:response HostVerdict
+type SwaggHostVerdict struct {
+	// in:body
+	Body types.HostVerdict
+}
+
+// swagger:operation GET /hosts/{id}/verdict Host getHostVerdict
+// ---
+// description: |
+//   Retrieves a short-lived JWT asserting the SGX capabilities and TCB status of the host. The token is signed
+//   with the key provisioned by the download_cert_signing setup task so that relying parties can verify it
+//   offline against the certificate served at /verdict-signing-certificate. The claims are those of the
+//   HostVerdict definition along with sub (host ID), iss, iat and exp. The token expires after the configured
+//   verdict validity or when the platform data of the host expires, whichever comes first.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/jwt
+// parameters:
+// - name: id
+//   description: Unique ID of the host.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully signed the host verdict.
+//     content:
+//       application/jwt
+//   '404':
+//     description: Host does not exist or has no valid platform data.
+//   '503':
+//     description: Host verdict signing is not configured.
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/hosts/2e7ca3a8-8e1d-4b0c-a3c4-b4e2c8a1f6d9/verdict
+// x-sample-call-output: |
+//  eyJhbGciOiJSUzM4NCIsImtpZCI6IjQ3NGUzN2Y5ZjQ4MzA0YzU0MGM5OGQ4MWZlMzdlNjQ2NmJkYzBmNWUiLCJ0eXAiOiJKV1QifQ.eyJob3N0X0lEIjoi...
+// ---
+
+// swagger:operation GET /verdict-signing-certificate Host getVerdictSigningCertificate
+// ---
+// description: |
+//   Retrieves the PEM encoded certificate used to verify signed host verdicts.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/x-pem-file
+// responses:
+//   '200':
+//     description: Successfully retrieved the verdict signing certificate.
+//     content:
+//       application/x-pem-file
+//   '503':
+//     description: Host verdict signing is not configured.
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/verdict-signing-certificate
+// ---
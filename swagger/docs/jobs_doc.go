@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package docs
+
+import (
+	"intel/isecl/shvs/v5/types"
+)
+
+// Job response payload
+// swagger:response Job
+type SwaggJob struct {
+	// in:body
+	Body types.Job
+}
+
+// Jobs response payload
+// swagger:response Jobs
+type SwaggJobs struct {
+	// in:body
+	Body types.Jobs
+}
+
+// swagger:operation GET /jobs Job queryJobs
+// ---
+// description: |
+//   Retrieves the background jobs, newest first, together with their attempts, timing and the error of
+//   their latest failed attempt. A job is QUEUED, PROCESSING, COMPLETED, ERROR once it ran out of
+//   attempts, or CANCELLED.
+//   Policy reevaluations and compliance reports run through the durable job queue. The periodic host
+//   expiry sweeps run on the scheduler leader and are recorded once finished as auto-refresh jobs, whose
+//   result lists the hosts which became STALE and IN-ACTIVE. Finished jobs are purged after
+//   SHVS_JOB_RETENTION_DAYS days.
+//   A valid bearer token with the JobAdministrator role is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: status
+//   description: Status of the jobs.
+//   in: query
+//   type: string
+//   enum: [QUEUED, PROCESSING, COMPLETED, ERROR, CANCELLED]
+// - name: type
+//   description: Type of the jobs, policy-reevaluation, compliance-report or auto-refresh.
+//   in: query
+//   type: string
+// - name: limit
+//   description: Maximum number of jobs, 100 by default and at most 1000.
+//   in: query
+//   type: integer
+// responses:
+//   '200':
+//     description: Successfully retrieved the jobs.
+//     schema:
+//       "$ref": "#/definitions/Jobs"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/jobs?status=ERROR
+// x-sample-call-output: |
+//  [
+//      {
+//          "id": "5c3f0e2d-7a1b-4d8e-9b0f-1e2d3c4b5a69",
+//          "type": "policy-reevaluation",
+//          "status": "ERROR",
+//          "attempts": 3,
+//          "max_attempts": 3,
+//          "payload": null,
+//          "last_error": "ReevaluateHostPolicies: Error while retrieving policies",
+//          "next_run_time": "2022-03-01T10:01:30.512312Z",
+//          "start_time": "2022-03-01T10:01:30.512312Z",
+//          "end_time": "2022-03-01T10:01:30.734521Z",
+//          "created_time": "2022-03-01T10:00:00.100221Z",
+//          "updated_time": "2022-03-01T10:01:30.734521Z"
+//      }
+//  ]
+// ---
+
+// swagger:operation GET /jobs/{id} Job getJob
+// ---
+// description: |
+//   Retrieves the background job associated with the specified job id.
+//   A valid bearer token with the JobAdministrator role is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the job.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully retrieved the job.
+//     schema:
+//       "$ref": "#/definitions/Job"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/jobs/5c3f0e2d-7a1b-4d8e-9b0f-1e2d3c4b5a69
+// ---
+
+// swagger:operation POST /jobs/{id}/retry Job retryJob
+// ---
+// description: |
+//   Queues the failed or cancelled job associated with the specified job id again with a fresh set of attempts.
+//   Jobs in any other status are rejected with 409.
+//   A valid bearer token with the JobAdministrator role is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the job.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully queued the job again.
+//     schema:
+//       "$ref": "#/definitions/Job"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/jobs/5c3f0e2d-7a1b-4d8e-9b0f-1e2d3c4b5a69/retry
+// ---
+
+// swagger:operation POST /jobs/{id}/cancel Job cancelJob
+// ---
+// description: |
+//   Cancels the queued or processing job associated with the specified job id. A processing job is
+//   stopped at once when it runs on the SHVS instance serving the request, and otherwise by the instance
+//   running it within one job queue poll interval.
+//   Jobs in any other status are rejected with 409.
+//   A valid bearer token with the JobAdministrator role is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the job.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully cancelled the job.
+//     schema:
+//       "$ref": "#/definitions/Job"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/jobs/5c3f0e2d-7a1b-4d8e-9b0f-1e2d3c4b5a69/cancel
+// ---
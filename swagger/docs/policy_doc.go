@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package docs
+
+import (
+	"intel/isecl/shvs/v5/resource"
+	"intel/isecl/shvs/v5/types"
+)
+
+// PolicyInfo request payload
+// swagger:response PolicyInfo
+type SwaggPolicyInfo struct {
+	// in:body
+	Body resource.PolicyInfo
+}
+
+// Policy response payload
+// swagger:response Policy
+type SwaggPolicy struct {
+	// in:body
+	Body types.Policy
+}
+
+// Policies response payload
+// swagger:response Policies
+type SwaggPolicies struct {
+	// in:body
+	Body types.Policies
+}
+
+// swagger:operation POST /policies Policy createPolicy
+// ---
+// description: |
+//   Creates a compliance policy. A host complies with a policy when it satisfies all of its rules.
+//   Rules compare sgx_supported, sgx_enabled, flc_enabled or tcb_upToDate (operators == and !=) or
+//   epc_size (operators ==, !=, >, >=, <, <= with sizes such as 64MB) against a value.
+//   A job evaluating all hosts again is queued once the policy is stored.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// consumes:
+//  - application/json
+// produces:
+//  - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/PolicyInfo"
+// responses:
+//   '201':
+//     description: Successfully created the policy.
+//     schema:
+//       "$ref": "#/definitions/Policy"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/policies
+// x-sample-call-input: |
+//  {
+//      "name": "sgx-baseline",
+//      "description": "SGX enabled AND FLC enabled AND TCB up to date AND EPC >= 64MB",
+//      "rules": [
+//          {"attribute": "sgx_enabled", "operator": "==", "value": "true"},
+//          {"attribute": "flc_enabled", "operator": "==", "value": "true"},
+//          {"attribute": "tcb_upToDate", "operator": "==", "value": "true"},
+//          {"attribute": "epc_size", "operator": ">=", "value": "64MB"}
+//      ]
+//  }
+// x-sample-call-output: |
+//  {
+//      "id": "0ab2a3f1-9d64-4b1b-a6bb-3e1f0e8a2c11",
+//      "name": "sgx-baseline",
+//      "description": "SGX enabled AND FLC enabled AND TCB up to date AND EPC >= 64MB",
+//      "rules": [
+//          {"attribute": "sgx_enabled", "operator": "==", "value": "true"},
+//          {"attribute": "flc_enabled", "operator": "==", "value": "true"},
+//          {"attribute": "tcb_upToDate", "operator": "==", "value": "true"},
+//          {"attribute": "epc_size", "operator": ">=", "value": "64MB"}
+//      ]
+//  }
+// ---
+
+// swagger:operation GET /policies Policy queryPolicies
+// ---
+// description: |
+//   Retrieves all the compliance policies.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the policies.
+//     schema:
+//       "$ref": "#/definitions/Policies"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/policies
+// ---
+
+// swagger:operation GET /policies/{id} Policy getPolicy
+// ---
+// description: |
+//   Retrieves the compliance policy associated with the specified policy id.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the policy.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully retrieved the policy.
+//     schema:
+//       "$ref": "#/definitions/Policy"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/policies/0ab2a3f1-9d64-4b1b-a6bb-3e1f0e8a2c11
+// ---
+
+// swagger:operation PUT /policies/{id} Policy updatePolicy
+// ---
+// description: |
+//   Replaces the name, description and rules of the policy associated with the specified policy id.
+//   A job evaluating all hosts again is queued once the policy is stored.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// consumes:
+//  - application/json
+// produces:
+//  - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the policy.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/PolicyInfo"
+// responses:
+//   '200':
+//     description: Successfully updated the policy.
+//     schema:
+//       "$ref": "#/definitions/Policy"
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/policies/0ab2a3f1-9d64-4b1b-a6bb-3e1f0e8a2c11
+// ---
+
+// swagger:operation DELETE /policies/{id} Policy deletePolicy
+// ---
+// description: |
+//   Deletes the policy associated with the specified policy id. A job evaluating all hosts again is queued afterwards.
+//   A valid bearer token is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// parameters:
+// - name: id
+//   description: Unique ID of the policy.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '204':
+//     description: Successfully deleted the policy.
+//
+// x-sample-call-endpoint: https://sgx-hvs.com:13000/sgx-hvs/v2/policies/0ab2a3f1-9d64-4b1b-a6bb-3e1f0e8a2c11
+// x-sample-call-output: |
+//    204 No content
+// ---
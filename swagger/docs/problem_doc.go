@@ -0,0 +1,28 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package docs
+
+import (
+	"intel/isecl/shvs/v5/resource"
+)
+
+// Problem response payload
+//
+// All error responses carry an RFC 7807 application/problem+json body. The code field is stable and can be
+// switched on by clients: bad_request, invalid_input, invalid_request_body, unauthorized,
+// token_subject_mismatch, forbidden, not_found, method_not_allowed, conflict, host_not_connected,
+// payload_too_large, unsupported_media_type, too_many_requests, service_unavailable and internal_error. The request_id field matches the X-Request-ID
+// header of the request and errors lists the invalid fields of the request, if any.
+//
+// Requests beyond the rate limit of the token subject or of the client IP get too_many_requests with a
+// Retry-After header giving the seconds to wait, and request bodies beyond the size limit get
+// payload_too_large.
+//
+// swagger:response Problem
+type SwaggProblem struct {
+	// in:body
+	Body resource.Problem
+}
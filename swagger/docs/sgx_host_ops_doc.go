@@ -53,16 +53,26 @@ type SwaggNewHostsSgxDataInfo struct {
 	Body NewHostsSgxData
 }
 
+// HostHeartbeatResponse response payload
+// swagger:response HostHeartbeatResponse
+type SwaggHostHeartbeatResponse struct {
+	// in:body
+	Body resource.HostHeartbeatResponse
+}
+
 // swagger:operation GET /platform-data PlatformData getPlatformData
 // ---
 // description: |
 //   Retrieves the platform data of the host based on the provided filter criteria from the SHVS database.
+//   When the Accept header is application/x-ndjson, one JSON object per line is streamed back instead of a JSON array.
+//   Only the platform data of the hosts in the scope of the roles is returned.
 //   A valid bearer token is required to authorize this REST call.
 //
 // security:
 //  - bearerAuth: []
 // produces:
 //  - application/json
+//  - application/x-ndjson
 // parameters:
 // - name: HostName
 //   description: Name of the host.
@@ -72,6 +82,12 @@ type SwaggNewHostsSgxDataInfo struct {
 //   description: Results returned will be restricted to between the current time and number of minutes prior.
 //   in: query
 //   type: string
+// - name: includeStale
+//   description: |
+//     Also return the STALE hosts, which missed their refresh but are still within the grace time
+//     before turning IN-ACTIVE. Only CONNECTED hosts are returned by default.
+//   in: query
+//   type: boolean
 // responses:
 //   '200':
 //     description: Successfully retrieved the platform data.
@@ -100,7 +116,14 @@ type SwaggNewHostsSgxDataInfo struct {
 //
 // description: |
 //   Agent pushes the platform enablement info and TCB status to SHVS at regular Interval
-//   A valid bearer token is required to authorize this REST call.
+//   The optional labels of the host, at most 16 of them, scope the roles carrying a labels context. A
+//   registration without labels keeps the labels of the host, while empty labels remove them. When the
+//   HostDataUpdater role of the token is scoped, the host and its labels must be in its scope, so that an agent
+//   cannot label its host into the scope of another tenant. Agents authenticated by a client certificate or
+//   by an unscoped role may set any labels.
+//   A valid bearer token is required to authorize this REST call. When client certificate authentication is
+//   enabled, the agent may instead present a client certificate issued by CMS which names the hardware UUID
+//   of the host in its common name, a DNS SAN or a urn:uuid URI SAN.
 //
 // security:
 //  - bearerAuth: []
@@ -130,7 +153,8 @@ type SwaggNewHostsSgxDataInfo struct {
 //      "flc_enabled": true,
 //      "epc_offset": "0x40000000",
 //      "epc_size": "3.0 GB",
-//      "tcb_upToDate": true
+//      "tcb_upToDate": true,
+//      "labels": {"tenant": "acme"}
 //  }
 // x-sample-call-output: |
 //  {
@@ -144,12 +168,20 @@ type SwaggNewHostsSgxDataInfo struct {
 // ---
 // description: |
 //   Retrieves the list of hosts based on the provided filter criteria from the SHVS database.
+//   When the Accept header is application/x-ndjson, one JSON object per line is streamed back instead of a JSON array.
+//   When the Accept header is text/csv, the host inventory (host name, hardware UUID, status, SGX/FLC/TCB flags,
+//   EPC size, last update and expiry) is returned as a CSV attachment honoring the same filters.
+//   Only the hosts in the scope of the roles are returned. A role context such as labels=tenant:acme,env:prod
+//   covers the hosts carrying all of these labels, and hostname=*.acme.example the hosts named after the
+//   pattern. Both can be given, separated by a semicolon. Roles without such a context cover all hosts.
 //   A valid bearer token is required to authorize this REST call.
 //
 // security:
 //  - bearerAuth: []
 // produces:
 //  - application/json
+//  - application/x-ndjson
+//  - text/csv
 // parameters:
 // - name: HardwareUUID
 //   description: Hardware UUID of the host.
@@ -168,6 +200,10 @@ type SwaggNewHostsSgxDataInfo struct {
 //   description: Add host status to the host info.
 //   in: query
 //   type: boolean
+// - name: compliant
+//   description: Restrict the hosts to those whose last policy evaluation passed (true) or failed (false).
+//   in: query
+//   type: boolean
 // responses:
 //   '200':
 //     description: Successfully retrieved the hosts.
@@ -182,7 +218,8 @@ type SwaggNewHostsSgxDataInfo struct {
 //    {
 //        "host_ID": "d60c9d18-a272-49b9-bf45-872f28407775",
 //        "host_name": "kbshostname",
-//        "uuid": "88888888-8887-1214-0516-3707a5a5a5a5"
+//        "uuid": "88888888-8887-1214-0516-3707a5a5a5a5",
+//        "labels": {"tenant": "acme"}
 //    }
 //  ]
 // ---
@@ -191,6 +228,7 @@ type SwaggNewHostsSgxDataInfo struct {
 // ---
 // description: |
 //   Deletes a host associated with the specified host id from the SHVS database.
+//   Hosts out of the scope of the roles are left alone, as are unknown hosts.
 //   A valid bearer token is required to authorize this REST call.
 //   Once done, Please make sure to uninstall the SGX Agent running on the corresponding host.
 //
@@ -213,10 +251,49 @@ type SwaggNewHostsSgxDataInfo struct {
 //    204 No content
 // ---
 
+// swagger:operation POST /hosts/{id}/heartbeat Host heartbeatHost
+// ---
+// description: |
+//   Keeps a registered host connected by extending its expiry time, without pushing its platform data again.
+//   The host stays connected for the configured host expiry time, or for the expiry override configured for
+//   its hardware UUID. A STALE host is connected again, while an IN-ACTIVE host is rejected with 409 and
+//   has to register again.
+//   A valid bearer token with the HostDataUpdater role is required to authorize this REST call. The subject
+//   of the token must match the hardware UUID of the host, an unknown host id being rejected with 401 as well.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the host.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully extended the expiry time of the host.
+//     schema:
+//       "$ref": "#/definitions/HostHeartbeatResponse"
+//
+// x-sample-call-endpoint: |
+//    https://sgx-hvs.com:13000/sgx-hvs/v2/hosts/d60c9d18-a272-49b9-bf45-872f28407775/heartbeat
+// x-sample-call-output: |
+//    {
+//        "id": "d60c9d18-a272-49b9-bf45-872f28407775",
+//        "status": "CONNECTED",
+//        "validTo": "2022-03-01T14:00:00.512312Z"
+//    }
+// ---
+
 // swagger:operation GET /hosts/{id} Host getHosts
 // ---
 // description: |
 //   Retrieves the host details associated with a specified host id from the SHVS database.
+//   The compliance field carries the verdict of the last policy evaluation along with the failed rules.
+//   Hosts out of the scope of the roles are not found.
 //   A valid bearer token is required to authorize this REST call.
 //
 // security:
@@ -20,6 +20,7 @@ type SwaggHostStatusResponse struct {
 // ---
 // description: |
 //   Retrieves the host status of the host based on the provided filter criteria from the SHVS database.
+//   The status of a host out of the scope of the roles is not returned.
 //   A valid bearer token is required to authorize this REST call.
 //
 // security:
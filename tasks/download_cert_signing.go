@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tasks
+
+import (
+	"crypto/x509/pkix"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"intel/isecl/lib/common/v5/crypt"
+	"intel/isecl/lib/common/v5/setup"
+	"intel/isecl/shvs/v5/config"
+	"intel/isecl/shvs/v5/constants"
+)
+
+// Download_Cert_Signing generates the key pair used to sign host verdicts and gets its
+// certificate signed from CMS
+type Download_Cert_Signing struct {
+	Flags         []string
+	Config        *config.Configuration
+	CaCertsDir    string
+	ConsoleWriter io.Writer
+}
+
+func (s Download_Cert_Signing) Run(c setup.Context) error {
+	log.Trace("tasks/download_cert_signing:Run() Entering")
+	defer log.Trace("tasks/download_cert_signing:Run() Leaving")
+
+	fmt.Fprintln(s.ConsoleWriter, "Running signing certificate download setup...")
+	fs := flag.NewFlagSet("download_cert_signing", flag.ContinueOnError)
+	force := fs.Bool("force", false, "force recreation, will overwrite any existing signing key and certificate")
+	err := fs.Parse(s.Flags)
+	if err != nil {
+		return errors.Wrap(err, "tasks/download_cert_signing:Run() Could not parse input flags")
+	}
+
+	if !*force && s.Validate(c) == nil {
+		fmt.Fprintln(s.ConsoleWriter, "Signing certificate already downloaded, skipping")
+		return nil
+	}
+
+	if s.Config.CMSBaseURL == "" {
+		return errors.New("tasks/download_cert_signing:Run() CMS_BASE_URL is not configured")
+	}
+	if s.Config.Subject.SigningCertCommonName == "" {
+		return errors.New("tasks/download_cert_signing:Run() Signing certificate common name is not configured")
+	}
+	bearerToken, err := c.GetenvSecret("BEARER_TOKEN", "bearer token")
+	if err != nil || bearerToken == "" {
+		return errors.New("tasks/download_cert_signing:Run() BEARER_TOKEN not found in environment")
+	}
+
+	key, cert, err := setup.GetCertificateFromCMS(constants.SigningCertType, constants.DefaultKeyAlgorithm,
+		constants.DefaultKeyAlgorithmLength, s.Config.CMSBaseURL,
+		pkix.Name{CommonName: s.Config.Subject.SigningCertCommonName}, s.Config.CertSANList, s.CaCertsDir, bearerToken)
+	if err != nil {
+		return errors.Wrap(err, "tasks/download_cert_signing:Run() Could not get signing certificate from CMS")
+	}
+
+	err = crypt.SavePrivateKeyAsPKCS8(key, s.Config.SigningKeyFile)
+	if err != nil {
+		return errors.Wrap(err, "tasks/download_cert_signing:Run() Could not store signing key")
+	}
+	err = ioutil.WriteFile(s.Config.SigningCertFile, cert, 0644)
+	if err != nil {
+		return errors.Wrap(err, "tasks/download_cert_signing:Run() Could not store signing certificate")
+	}
+	return nil
+}
+
+func (s Download_Cert_Signing) Validate(c setup.Context) error {
+	log.Trace("tasks/download_cert_signing:Validate() Entering")
+	defer log.Trace("tasks/download_cert_signing:Validate() Leaving")
+
+	if _, err := os.Stat(s.Config.SigningKeyFile); os.IsNotExist(err) {
+		return errors.New("download_cert_signing: Signing key is not configured")
+	}
+	if _, err := os.Stat(s.Config.SigningCertFile); os.IsNotExist(err) {
+		return errors.New("download_cert_signing: Signing certificate is not configured")
+	}
+	return nil
+}
@@ -14,7 +14,6 @@ import (
 	"intel/isecl/shvs/v5/config"
 	"intel/isecl/shvs/v5/constants"
 	"io"
-	"net/url"
 	"strings"
 	"time"
 )
@@ -43,7 +42,7 @@ func (s Update_Service_Config) Run(c setup.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "tasks/server:Run() Could not parse input flags")
 	}
-	if s.Config.Port > 65535 || s.Config.Port <= 1024 {
+	if err = config.ValidatePort(s.Config.Port); err != nil {
 		return errors.Wrap(err, "tasks/server:Run() Invalid or reserved port")
 	}
 	fmt.Fprintf(s.ConsoleWriter, "Using HTTPS port: %d\n", s.Config.Port)
@@ -92,6 +91,9 @@ func (s Update_Service_Config) Run(c setup.Context) error {
 		}
 	}
 
+	s.Config.ShutdownTimeout = s.durationSetting(c, "SHVS_SERVER_SHUTDOWN_TIMEOUT", "SGX Host Verification Service Shutdown Timeout",
+		s.Config.ShutdownTimeout, constants.DefaultShutdownTimeout)
+
 	maxHeaderBytes, err := c.GetenvInt("SHVS_SERVER_MAX_HEADER_BYTES", "SGX Host Verification Service Max Header Bytes Timeout")
 	if err != nil {
 		s.Config.MaxHeaderBytes = constants.DefaultMaxHeaderBytes
@@ -101,7 +103,7 @@ func (s Update_Service_Config) Run(c setup.Context) error {
 
 	aasAPIURL, err := c.GetenvString("AAS_API_URL", "AAS Base URL")
 	if err == nil && aasAPIURL != "" {
-		if _, err = url.ParseRequestURI(aasAPIURL); err != nil {
+		if err = config.ValidateURL(aasAPIURL); err != nil {
 			return errors.Wrap(err, "SaveConfiguration() AAS_API_URL provided is invalid")
 		} else {
 			s.Config.AuthServiceURL = aasAPIURL
@@ -113,7 +115,7 @@ func (s Update_Service_Config) Run(c setup.Context) error {
 
 	scsBaseURL, err := c.GetenvString("SCS_BASE_URL", "SCS Base URL")
 	if err == nil && scsBaseURL != "" {
-		if _, err = url.ParseRequestURI(scsBaseURL); err != nil {
+		if err = config.ValidateURL(scsBaseURL); err != nil {
 			return errors.Wrap(err, "SaveConfiguration() SCS_BASE_URL provided is invalid")
 		} else {
 			s.Config.ScsBaseURL = scsBaseURL
@@ -139,13 +141,36 @@ func (s Update_Service_Config) Run(c setup.Context) error {
 		return errors.Wrap(err, "SHVS_ADMIN_PASSWORD is not defined in environment or configuration file")
 	}
 
-	schedulerTimeout, err := c.GetenvInt("SHVS_SCHEDULER_TIMER", "SHVS Scheduler Timeout Seconds")
-	if err == nil && schedulerTimeout != 0 {
-		s.Config.SchedulerTimer = schedulerTimeout
-	} else if s.Config.SchedulerTimer == 0 {
-		s.Config.SchedulerTimer = constants.DefaultSHVSSchedulerTimer
+	jobWorkers, err := c.GetenvInt("SHVS_JOB_WORKERS", "SHVS Job Runner Worker Count")
+	if err == nil && jobWorkers > 0 {
+		s.Config.JobRunner.Workers = jobWorkers
+	} else if s.Config.JobRunner.Workers <= 0 {
+		s.Config.JobRunner.Workers = constants.DefaultJobWorkers
+	}
+
+	jobQueueSize, err := c.GetenvInt("SHVS_JOB_QUEUE_SIZE", "SHVS Job Runner Queue Size")
+	if err == nil && jobQueueSize > 0 {
+		s.Config.JobRunner.QueueSize = jobQueueSize
+	} else if s.Config.JobRunner.QueueSize <= 0 {
+		s.Config.JobRunner.QueueSize = constants.DefaultJobQueueSize
+	}
+
+	s.Config.JobRunner.JobTimeout = s.durationSetting(c, "SHVS_JOB_TIMEOUT", "SHVS Job Timeout",
+		s.Config.JobRunner.JobTimeout, constants.DefaultJobTimeout)
+
+	s.Config.JobRunner.PollInterval = s.durationSetting(c, "SHVS_JOB_POLL_INTERVAL", "SHVS Job Queue Poll Interval",
+		s.Config.JobRunner.PollInterval, constants.DefaultJobPollInterval)
+
+	jobRetentionDays, err := c.GetenvInt("SHVS_JOB_RETENTION_DAYS", "SHVS Finished Job Retention in days")
+	if err == nil && jobRetentionDays > 0 {
+		s.Config.JobRunner.RetentionDays = jobRetentionDays
+	} else if s.Config.JobRunner.RetentionDays <= 0 {
+		s.Config.JobRunner.RetentionDays = constants.DefaultJobRetentionDays
 	}
 
+	s.Config.LeaderElectionInterval = s.durationSetting(c, "SHVS_LEADER_ELECTION_INTERVAL", "SHVS Leader Election Interval",
+		s.Config.LeaderElectionInterval, constants.DefaultLeaderElectionInterval)
+
 	autoRefreshTimeout, err := c.GetenvInt("SHVS_AUTO_REFRESH_TIMER", "SHVS autoRefresh Timeout Seconds")
 	if err == nil && autoRefreshTimeout != 0 {
 		s.Config.SHVSRefreshTimer = autoRefreshTimeout
@@ -160,6 +185,41 @@ func (s Update_Service_Config) Run(c setup.Context) error {
 		s.Config.SHVSHostInfoExpiryTime = constants.DefaultSHVSHostInfoExpiryTime
 	}
 
+	hostStaleGraceTime, err := c.GetenvInt("SHVS_HOST_STALE_GRACE_TIME", "SHVS Host Stale Grace Time in minutes")
+	if err == nil && hostStaleGraceTime > 0 {
+		s.Config.SHVSHostStaleGraceTime = hostStaleGraceTime
+	} else if s.Config.SHVSHostStaleGraceTime <= 0 {
+		s.Config.SHVSHostStaleGraceTime = constants.DefaultSHVSHostStaleGraceTime
+	}
+
+	reportTimer, err := c.GetenvInt("SHVS_REPORT_TIMER", "SHVS Compliance Report Timer Seconds")
+	if err == nil && reportTimer > 0 {
+		s.Config.ComplianceReport.Timer = reportTimer
+	} else if s.Config.ComplianceReport.Timer <= 0 {
+		s.Config.ComplianceReport.Timer = constants.DefaultReportTimer
+	}
+
+	reportInactiveHours, err := c.GetenvInt("SHVS_REPORT_INACTIVE_HOURS", "SHVS Compliance Report Inactive Host Threshold in hours")
+	if err == nil && reportInactiveHours > 0 {
+		s.Config.ComplianceReport.InactiveHours = reportInactiveHours
+	} else if s.Config.ComplianceReport.InactiveHours <= 0 {
+		s.Config.ComplianceReport.InactiveHours = constants.DefaultReportInactiveHours
+	}
+
+	reportRetentionDays, err := c.GetenvInt("SHVS_REPORT_RETENTION_DAYS", "SHVS Compliance Report Retention in days")
+	if err == nil && reportRetentionDays > 0 {
+		s.Config.ComplianceReport.RetentionDays = reportRetentionDays
+	} else if s.Config.ComplianceReport.RetentionDays <= 0 {
+		s.Config.ComplianceReport.RetentionDays = constants.DefaultReportRetentionDays
+	}
+
+	verdictValidityMins, err := c.GetenvInt("SHVS_VERDICT_VALIDITY_MINS", "SHVS Host Verdict Token Validity in minutes")
+	if err == nil && verdictValidityMins > 0 {
+		s.Config.VerdictValidityMins = verdictValidityMins
+	} else if s.Config.VerdictValidityMins <= 0 {
+		s.Config.VerdictValidityMins = constants.DefaultVerdictValidityMins
+	}
+
 	logLevel, err := c.GetenvString(constants.SHVSLogLevel, "SHVS Log Level")
 	if err != nil {
 		slog.Infof("config/config:SaveConfiguration() %s not defined, using default log level: Info", constants.SHVSLogLevel)
@@ -175,6 +235,16 @@ func (s Update_Service_Config) Run(c setup.Context) error {
 		}
 	}
 
+	logFormat, err := c.GetenvString(constants.SHVSLogFormat, "SHVS Log Format")
+	if err != nil || logFormat == "" {
+		s.Config.LogFormat = constants.LogFormatText
+	} else if logFormat != constants.LogFormatText && logFormat != constants.LogFormatJSON {
+		slog.Infof("config/config:SaveConfiguration() Invalid log format specified in env, using default log format: %s", constants.LogFormatText)
+		s.Config.LogFormat = constants.LogFormatText
+	} else {
+		s.Config.LogFormat = logFormat
+	}
+
 	logMaxLen, err := c.GetenvInt("SHVS_LOG_MAX_LENGTH", "SGX Host Verification Service Log maximum length")
 	if err != nil || logMaxLen < constants.DefaultLogEntryMaxLength {
 		s.Config.LogMaxLength = constants.DefaultLogEntryMaxLength
@@ -196,6 +266,23 @@ func (s Update_Service_Config) Run(c setup.Context) error {
 	return nil
 }
 
+// durationSetting returns the duration set by envName. The configured duration is kept when the variable is
+// unset or invalid, and defaultValue is used when none is configured.
+func (s Update_Service_Config) durationSetting(c setup.Context, envName, description string, current, defaultValue time.Duration) time.Duration {
+	value, err := c.GetenvString(envName, description)
+	if err == nil && strings.TrimSpace(value) != "" {
+		duration, err := time.ParseDuration(value)
+		if err == nil && duration > 0 {
+			return duration
+		}
+		fmt.Fprintf(s.ConsoleWriter, "Invalid duration provided for %s, keeping the configured value\n", envName)
+	}
+	if current <= 0 {
+		return defaultValue
+	}
+	return current
+}
+
 func (s Update_Service_Config) Validate(c setup.Context) error {
 	log.Trace("tasks/server:Validate() Entering")
 	defer log.Trace("tasks/server:Validate() Leaving")
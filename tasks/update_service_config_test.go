@@ -65,7 +65,8 @@ func TestServerSetupEnv(t *testing.T) {
 		ConsoleWriter: os.Stdout,
 	}
 	err = invalidPortSetupEnv.Run(ctx)
-	assert.Equal(t, err, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "port 137 is reserved or out of range")
 
 	os.Setenv("SHVS_SERVER_READ_TIMEOUT", "testvalue")
 	os.Setenv("SHVS_SERVER_READ_HEADER_TIMEOUT", "testvalue")
@@ -207,6 +208,33 @@ func TestServerSetupInvalidLogLevelArg(t *testing.T) {
 	assert.Equal(t, logrus.InfoLevel, c.LogLevel)
 }
 
+func TestServerSetupKeepsConfiguredDurations(t *testing.T) {
+	os.Setenv("SHVS_ADMIN_USERNAME", RandStringBytes())
+	os.Setenv("SHVS_ADMIN_PASSWORD", RandStringBytes())
+	os.Setenv("SHVS_JOB_POLL_INTERVAL", "10s")
+	defer os.Unsetenv("SHVS_JOB_POLL_INTERVAL")
+	c := config.Configuration{
+		AuthServiceURL:         "https://localhost",
+		ScsBaseURL:             "https://localhost",
+		ShutdownTimeout:        time.Minute,
+		LeaderElectionInterval: 30 * time.Second,
+	}
+	c.JobRunner.JobTimeout = 7 * time.Minute
+	s := Update_Service_Config{
+		Flags:         []string{"-port=1337"},
+		Config:        &c,
+		ConsoleWriter: os.Stdout,
+	}
+	err := s.Run(setup.Context{})
+	if err != nil {
+		assert.Contains(t, err.Error(), config.ErrNoConfigFile.Error())
+	}
+	assert.Equal(t, time.Minute, c.ShutdownTimeout)
+	assert.Equal(t, 30*time.Second, c.LeaderElectionInterval)
+	assert.Equal(t, 7*time.Minute, c.JobRunner.JobTimeout)
+	assert.Equal(t, 10*time.Second, c.JobRunner.PollInterval)
+}
+
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
 func RandStringBytes() string {
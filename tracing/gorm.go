@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tracing
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// gormContextKey holds the context of the request in the settings of a gorm.DB
+const gormContextKey = "tracing:context"
+
+const gormSpanKey = "tracing:span"
+
+// WithContext returns a copy of db whose queries are traced as children of the span in ctx
+func WithContext(db *gorm.DB, ctx context.Context) *gorm.DB {
+	return db.Set(gormContextKey, ctx)
+}
+
+// InstrumentGorm traces the create, query, update and delete calls made through db. The calls are only
+// traced when db carries the context of a request, see WithContext.
+func InstrumentGorm(db *gorm.DB) {
+	operations := []struct {
+		name      string
+		processor *gorm.CallbackProcessor
+		callback  string
+	}{
+		{"create", db.Callback().Create(), "gorm:create"},
+		{"query", db.Callback().Query(), "gorm:query"},
+		{"row_query", db.Callback().RowQuery(), "gorm:row_query"},
+		{"update", db.Callback().Update(), "gorm:update"},
+		{"delete", db.Callback().Delete(), "gorm:delete"},
+	}
+	for _, op := range operations {
+		op.processor.Before(op.callback).Register("tracing:before_"+op.name, startSpan(op.name))
+		op.processor.After(op.callback).Register("tracing:after_"+op.name, endSpan)
+	}
+}
+
+func startSpan(operation string) func(*gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		value, ok := scope.Get(gormContextKey)
+		if !ok {
+			return
+		}
+		ctx, ok := value.(context.Context)
+		if !ok {
+			return
+		}
+		_, span := tracer().Start(ctx, "db "+operation+" "+scope.TableName(),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation", operation),
+				attribute.String("db.sql.table", scope.TableName()),
+			))
+		scope.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endSpan(scope *gorm.Scope) {
+	value, ok := scope.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(attribute.String("db.statement", scope.SQL))
+	if scope.HasError() && !gorm.IsRecordNotFoundError(scope.DB().Error) {
+		span.RecordError(scope.DB().Error)
+		span.SetStatus(codes.Error, scope.DB().Error.Error())
+	}
+	span.End()
+}
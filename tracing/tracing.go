@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+// Package tracing traces the requests served by SHVS, from the HTTP handlers to the database, with
+// OpenTelemetry. The spans are exported through OTLP over HTTP and the W3C trace context is propagated
+// from the incoming requests to the outgoing ones. The JWT signing certificates are fetched from AAS by the
+// token authentication without the context of the request, so their fetch starts a trace of its own. The
+// certificates downloaded from CMS by the setup tasks are fetched before the service, and its tracing, start.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	commLog "intel/isecl/lib/common/v5/log"
+	"intel/isecl/shvs/v5/constants"
+	"intel/isecl/shvs/v5/requestid"
+	"intel/isecl/shvs/v5/version"
+)
+
+var log = commLog.GetDefaultLogger()
+
+// TracerName names the tracer of the spans created by SHVS
+const TracerName = "intel/isecl/shvs/v5"
+
+// Attributes of the spans which are not OpenTelemetry semantic conventions
+const (
+	RequestIDAttribute = attribute.Key("shvs.request_id")
+	ErrorCodeAttribute = attribute.Key("shvs.error_code")
+)
+
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+func tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(TracerName)
+}
+
+// Start exports the spans to the OTLP/HTTP endpoint, given as host:port. The OTEL_EXPORTER_OTLP_* environment
+// variables apply when the endpoint is empty. sampleRatio is the ratio of the traces started by SHVS which are
+// sampled, the traces of the incoming requests being sampled as decided by the caller. The returned function
+// flushes the pending spans and stops the export.
+func Start(ctx context.Context, endpoint string, insecure bool, sampleRatio float64) (func(context.Context) error, error) {
+	log.Trace("tracing/tracing:Start() Entering")
+	defer log.Trace("tracing/tracing:Start() Leaving")
+
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", constants.ServiceName),
+			attribute.String("service.version", version.Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	log.Infof("tracing/tracing:Start() Exporting traces to %s", endpoint)
+	return provider.Shutdown, nil
+}
+
+// statusRecorder keeps the status code of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets the streaming handlers flush the response through the recorder
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Middleware starts the span of a request matched by the router, continuing the trace of the caller. The span
+// is named after the route template so that the requests to one endpoint are grouped.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer().Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("http.target", r.URL.RequestURI()),
+				RequestIDAttribute.String(requestid.FromContext(r.Context())),
+			))
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// Transport propagates the trace context of the outgoing requests and records them as client spans
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx, span := tracer().Start(r.Context(), r.Method+" "+r.URL.Host,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", r.Method),
+			attribute.String("http.url", r.URL.Scheme+"://"+r.URL.Host+r.URL.Path),
+		))
+	defer span.End()
+
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+	res, err := base.RoundTrip(r)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.status_code", res.StatusCode))
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+	}
+	return res, nil
+}
+
+// RecordError marks the span of the request as failed with the error code of its problem response
+func RecordError(ctx context.Context, err error, status int, code string) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetAttributes(ErrorCodeAttribute.String(code))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
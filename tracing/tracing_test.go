@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"intel/isecl/shvs/v5/requestid"
+)
+
+// inMemoryTracing exports the spans to memory for the duration of the test
+func inMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	values := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := inMemoryTracing(t)
+
+	r := mux.NewRouter()
+	r.Use(Middleware)
+	r.Handle("/sgx-hvs/v2/hosts/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, trace.SpanContextFromContext(r.Context()).IsValid())
+		RecordError(r.Context(), errors.New("db down"), http.StatusInternalServerError, "internal_error")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/sgx-hvs/v2/hosts/f1d2c4d0-4c1b-4b8e-9a3b-2f9a3c1e5d21", nil)
+	req = req.WithContext(requestid.NewContext(req.Context(), "req-1"))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /sgx-hvs/v2/hosts/{id}", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, codes.Error, span.Status.Code)
+	values := attributes(span)
+	assert.Equal(t, "/sgx-hvs/v2/hosts/{id}", values["http.route"].AsString())
+	assert.Equal(t, int64(500), values["http.status_code"].AsInt64())
+	assert.Equal(t, "req-1", values[RequestIDAttribute].AsString())
+	assert.Equal(t, "internal_error", values[ErrorCodeAttribute].AsString())
+	assert.Len(t, span.Events, 1)
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	exporter := inMemoryTracing(t)
+
+	r := mux.NewRouter()
+	r.Use(Middleware)
+	r.Handle("/sgx-hvs/v2/version", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/sgx-hvs/v2/version", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+}
+
+func TestTransportPropagatesTraceContext(t *testing.T) {
+	exporter := inMemoryTracing(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, parent := otel.Tracer(TracerName).Start(context.Background(), "parent")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/aas/v1/jwt-certificates", nil)
+	client := &http.Client{Transport: &Transport{}}
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	parent.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	clientSpan := spans[0]
+	assert.Equal(t, trace.SpanKindClient, clientSpan.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), clientSpan.Parent.SpanID())
+	assert.Equal(t, "00-"+clientSpan.SpanContext.TraceID().String()+"-"+clientSpan.SpanContext.SpanID().String()+"-01", traceparent)
+	assert.Equal(t, int64(200), attributes(clientSpan)["http.status_code"].AsInt64())
+}
+
+func TestTransportStartsTraceWithoutRequestContext(t *testing.T) {
+	exporter := inMemoryTracing(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/aas/v1/jwt-certificates", nil)
+	client := &http.Client{Transport: &Transport{}}
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.False(t, spans[0].Parent.IsValid())
+	assert.Equal(t, "00-"+spans[0].SpanContext.TraceID().String()+"-"+spans[0].SpanContext.SpanID().String()+"-01", traceparent)
+}
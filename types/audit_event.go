@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent struct is the database schema of the append-only audit_events table. An event records who
+// took an administrative action, on which target and with which result.
+type AuditEvent struct {
+	// swagger:strfmt uuid
+	ID   uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Time time.Time `json:"time" gorm:"not null;index:idx_audit_event_time"`
+	// Subject is the subject of the token the action was taken with
+	Subject string     `json:"subject"`
+	Roles   AuditRoles `json:"roles,omitempty" gorm:"type:jsonb"`
+	Action  string     `json:"action" gorm:"not null;index:idx_audit_event_action"`
+	// Target is the ID of the host, policy or job the action was taken on
+	Target     string `json:"target,omitempty" gorm:"index:idx_audit_event_target"`
+	Result     string `json:"result" gorm:"not null"`
+	Detail     string `json:"detail,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+}
+
+type AuditEvents []AuditEvent
+
+// AuditLogHead struct is the database schema of the audit_log_heads table. It anchors the hash chain of the
+// audit log file of an instance, which holds at least Sequence entries, entry number Sequence being hashed
+// as Hash, so that a truncated or rewritten file does not verify.
+type AuditLogHead struct {
+	// LogID names the audit log file by the host name of the instance and the path of the file
+	LogID       string    `gorm:"primary_key"`
+	Sequence    int       `gorm:"not null"`
+	Hash        string    `gorm:"not null"`
+	UpdatedTime time.Time `gorm:"not null"`
+}
+
+// AuditRoles lists the roles of the subject, as service:name or service:name:context
+type AuditRoles []string
+
+func (ar AuditRoles) Value() (driver.Value, error) {
+	return json.Marshal(ar)
+}
+
+func (ar *AuditRoles) Scan(value interface{}) error {
+	return scanJSON(value, ar)
+}
+
+// AuditEventFilter selects the audit events returned, newest first. Empty fields match any event.
+type AuditEventFilter struct {
+	Action   string
+	Subject  string
+	Target   string
+	Result   string
+	FromTime time.Time
+	ToTime   time.Time
+	Limit    int
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ComplianceReport struct is the database schema of a ComplianceReport table
+type ComplianceReport struct {
+	// swagger:strfmt uuid
+	ID                uuid.UUID          `json:"id" gorm:"type:uuid;primary_key"`
+	CreatedTime       time.Time          `json:"created_time" gorm:"index:idx_report_created_time"`
+	TotalHosts        int                `json:"total_hosts"`
+	CompliantHosts    int                `json:"compliant_hosts"`
+	SgxDisabledHosts  int                `json:"sgx_disabled_hosts"`
+	TcbOutOfDateHosts int                `json:"tcb_out_of_date_hosts"`
+	InactiveHosts     int                `json:"inactive_hosts"`
+	NonCompliantHosts []NonCompliantHost `json:"non_compliant_hosts,omitempty" gorm:"-"`
+}
+
+type ComplianceReports []ComplianceReport
+
+// NonCompliantHost struct is the database schema of a NonCompliantHost table,
+// one row for every host failing at least one check of a compliance report
+type NonCompliantHost struct {
+	ID       uuid.UUID `json:"-" gorm:"type:uuid;primary_key"`
+	ReportID uuid.UUID `json:"-" gorm:"type:uuid;not null;index:idx_non_compliant_report_id"`
+	// swagger:strfmt uuid
+	HostID   uuid.UUID `json:"host_ID" gorm:"type:uuid;not null"`
+	HostName string    `json:"host_name"`
+	// swagger:strfmt uuid
+	HardwareUUID uuid.UUID `json:"uuid" gorm:"type:uuid"`
+	Status       string    `json:"status,omitempty"`
+	SgxDisabled  bool      `json:"sgx_disabled"`
+	TcbOutOfDate bool      `json:"tcb_out_of_date"`
+	Inactive     bool      `json:"inactive"`
+	LastSeenTime time.Time `json:"last_seen_time"`
+}
@@ -5,8 +5,11 @@
 package types
 
 import (
-	"github.com/google/uuid"
+	"database/sql/driver"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Host struct is the database schema of a Host table
@@ -17,9 +20,22 @@ type Host struct {
 	Description string    `json:"-"`
 	// swagger:strfmt uuid
 	HardwareUUID uuid.UUID `json:"uuid" gorm:"type:uuid"`
-	CreatedTime  time.Time `json:"-"`
-	UpdatedTime  time.Time `json:"-"`
-	Deleted      bool      `json:"-" gorm:"type:bool;not null;default:false"`
+	// Labels are given by the agent registering the host, the roles scoped by labels only cover the hosts carrying them
+	Labels      HostLabels `json:"labels,omitempty" gorm:"type:jsonb"`
+	CreatedTime time.Time  `json:"-"`
+	UpdatedTime time.Time  `json:"-"`
+	Deleted     bool       `json:"-" gorm:"type:bool;not null;default:false"`
+}
+
+// HostLabels maps the label keys of a host to their values, such as tenant: acme
+type HostLabels map[string]string
+
+func (hl HostLabels) Value() (driver.Value, error) {
+	return json.Marshal(hl)
+}
+
+func (hl *HostLabels) Scan(value interface{}) error {
+	return scanJSON(value, hl)
 }
 
 type HostStatusInfo struct {
@@ -29,7 +45,8 @@ type HostStatusInfo struct {
 
 type HostInfo struct {
 	HostStatusInfo
-	HardwareFeatures *HardwareFeatures `json:"hardware_features,omitempty"`
+	HardwareFeatures *HardwareFeatures  `json:"hardware_features,omitempty"`
+	Compliance       *HostPolicyVerdict `json:"compliance,omitempty"`
 }
 
 type HardwareFeatures struct {
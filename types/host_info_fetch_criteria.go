@@ -8,4 +8,6 @@ package types
 type HostInfoFetchCriteria struct {
 	GetPlatformData bool
 	GetStatus       bool
+	// Compliant restricts the hosts to those whose last policy verdict matches, when set
+	Compliant *bool
 }
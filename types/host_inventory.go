@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// HostInventory is a flattened view of a host, its status and its platform data used for reports.
+// Status and platform data fields are nil when the host has no matching record.
+// UpdatedTime is the last update of the status, such as its transition to IN-ACTIVE, and LastSeenTime the
+// last time the host registered its platform data.
+type HostInventory struct {
+	HostID       uuid.UUID
+	HostName     string
+	HardwareUUID uuid.UUID
+	Labels       HostLabels
+	Status       *string
+	SgxSupported *bool
+	SgxEnabled   *bool
+	FlcEnabled   *bool
+	TcbUptodate  *bool
+	EpcSize      *string
+	UpdatedTime  *time.Time
+	ExpiryTime   *time.Time
+	LastSeenTime time.Time
+}
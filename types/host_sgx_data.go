@@ -22,3 +22,9 @@ type HostSgxData struct {
 	CreatedTime  time.Time `json:"-"`
 }
 type HostsSgxData []HostSgxData
+
+// PlatformData is the platform data of a host along with the expiry time of its status
+type PlatformData struct {
+	HostSgxData
+	ExpiryTime time.Time `json:"validTo"`
+}
@@ -0,0 +1,26 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// HostVerdict is the set of claims carried in the signed verdict issued for a host
+type HostVerdict struct {
+	// swagger:strfmt uuid
+	HostID   uuid.UUID `json:"host_ID"`
+	HostName string    `json:"host_name"`
+	// swagger:strfmt uuid
+	HardwareUUID uuid.UUID `json:"uuid"`
+	SgxSupported bool      `json:"sgx_supported"`
+	SgxEnabled   bool      `json:"sgx_enabled"`
+	FlcEnabled   bool      `json:"flc_enabled"`
+	EpcSize      string    `json:"epc_size"`
+	TcbUptodate  bool      `json:"tcb_upToDate"`
+	Compliant    *bool     `json:"compliant,omitempty"`
+	ValidTo      time.Time `json:"validTo"`
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Job struct is the database schema of the jobs table holding the durable job queue. A job is claimed
+// by a worker until NextRunTime, after which it can be claimed again should its worker have died.
+// StartTime and EndTime record the latest attempt, Result the outcome of the jobs which report one.
+type Job struct {
+	// swagger:strfmt uuid
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	Type        string     `json:"type" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;index:idx_job_status_next_run"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	Payload     JobPayload `json:"payload,omitempty" gorm:"type:jsonb"`
+	Result      JobPayload `json:"result,omitempty" gorm:"type:jsonb"`
+	LastError   string     `json:"last_error,omitempty"`
+	Owner       string     `json:"owner,omitempty"`
+	NextRunTime time.Time  `json:"next_run_time" gorm:"index:idx_job_status_next_run"`
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	CreatedTime time.Time  `json:"created_time"`
+	UpdatedTime time.Time  `json:"updated_time"`
+}
+
+type Jobs []Job
+
+// JobPayload is the JSON encoded input or result of a job
+type JobPayload []byte
+
+func (p JobPayload) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	return []byte(p), nil
+}
+
+func (p *JobPayload) Scan(value interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		*p = nil
+	case []byte:
+		*p = append(JobPayload(nil), data...)
+	case string:
+		*p = JobPayload(data)
+	default:
+		return errors.Errorf("JobPayload: unsupported type %T", value)
+	}
+	return nil
+}
+
+func (p JobPayload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+func (p *JobPayload) UnmarshalJSON(data []byte) error {
+	*p = append(JobPayload(nil), data...)
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2022 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// Policy struct is the database schema of a Policy table. A host complies with a policy
+// when it satisfies every one of its rules
+type Policy struct {
+	// swagger:strfmt uuid
+	ID          uuid.UUID   `json:"id" gorm:"type:uuid;primary_key"`
+	Name        string      `json:"name" gorm:"not null;unique"`
+	Description string      `json:"description,omitempty"`
+	Rules       PolicyRules `json:"rules" gorm:"type:jsonb;not null"`
+	CreatedTime time.Time   `json:"-"`
+	UpdatedTime time.Time   `json:"-"`
+}
+
+type Policies []Policy
+
+// PolicyRule compares one of the SGX attributes reported by a host against a value,
+// e.g. {"attribute": "epc_size", "operator": ">=", "value": "64MB"}
+type PolicyRule struct {
+	Attribute string `json:"attribute"`
+	Operator  string `json:"operator"`
+	Value     string `json:"value"`
+}
+
+type PolicyRules []PolicyRule
+
+func (pr PolicyRules) Value() (driver.Value, error) {
+	return json.Marshal(pr)
+}
+
+func (pr *PolicyRules) Scan(value interface{}) error {
+	return scanJSON(value, pr)
+}
+
+// HostPolicyVerdict struct is the database schema of a HostPolicyVerdict table holding
+// the outcome of the last policy evaluation of a host
+type HostPolicyVerdict struct {
+	ID            uuid.UUID         `json:"-" gorm:"type:uuid;primary_key"`
+	HostID        uuid.UUID         `json:"-" gorm:"type:uuid;not null;unique"`
+	Compliant     bool              `json:"compliant"`
+	FailedRules   FailedPolicyRules `json:"failed_rules,omitempty" gorm:"type:jsonb"`
+	EvaluatedTime time.Time         `json:"evaluated_time"`
+}
+
+// FailedPolicyRule is a rule of the named policy which the host did not satisfy, along
+// with the value reported by the host
+type FailedPolicyRule struct {
+	Policy string `json:"policy"`
+	PolicyRule
+	Actual string `json:"actual"`
+}
+
+type FailedPolicyRules []FailedPolicyRule
+
+func (fr FailedPolicyRules) Value() (driver.Value, error) {
+	return json.Marshal(fr)
+}
+
+func (fr *FailedPolicyRules) Scan(value interface{}) error {
+	return scanJSON(value, fr)
+}
+
+func scanJSON(value interface{}, v interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	default:
+		return errors.Errorf("scanJSON: unsupported type %T", value)
+	}
+}